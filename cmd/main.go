@@ -2,23 +2,41 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"expvar"
+	"flag"
 	"log"
+	"net/http"
 	"os"
-	"regexp"
+	"os/signal"
+	"syscall"
+	"time"
 
 	firebase "firebase.google.com/go"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
+	"github.com/shunsukenagashima/chat-api/pkg/config"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	domainRepository "github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	domainUsecase "github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
 	"github.com/shunsukenagashima/chat-api/pkg/infra/auth"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/broadcaster"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/memory"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/postgres"
 	"github.com/shunsukenagashima/chat-api/pkg/infra/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/storage"
 	"github.com/shunsukenagashima/chat-api/pkg/interface/controller"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/middleware"
 	"github.com/shunsukenagashima/chat-api/pkg/interface/route"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/validation"
 	"github.com/shunsukenagashima/chat-api/pkg/usecase"
 	"google.golang.org/api/option"
 )
@@ -28,141 +46,349 @@ type AppSecret struct {
 }
 
 func main() {
-	if err := run(context.Background()); err != nil {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML file overriding the default settings; environment variables override it in turn")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load the config: %v", err)
+	}
+
+	if err := run(context.Background(), cfg); err != nil {
 		log.Fatalf("Server failed to run with %v", err)
 		os.Exit(1)
 	}
 }
 
-func run(ctx context.Context) error {
-	router := gin.Default()
+func run(ctx context.Context, cfg *config.Config) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	controllers, err := initializeControllers(ctx)
+	controllers, middlewares, err := initializeControllers(ctx, cfg)
 	if err != nil {
 		return err
 	}
 
+	server := &http.Server{
+		Addr:    cfg.Server.Addr,
+		Handler: newRouter(controllers, middlewares, cfg.Server.CORSOrigins),
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	adminServer := newAdminServer(cfg.Server.AdminAddr)
+	if adminServer != nil {
+		log.Printf("serving metrics at %s/debug/vars", cfg.Server.AdminAddr)
+		go func() {
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Admin server failed with %v", err)
+			}
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// WebSockets are hijacked connections that http.Server.Shutdown does not track, so they are closed first.
+	if err := controllers.WSController.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to close WebSocket connections: %v", err)
+	}
+
+	if adminServer != nil {
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to shut down the admin server: %v", err)
+		}
+	}
+
+	return server.Shutdown(shutdownCtx)
+}
+
+// newAdminServer serves runtime metrics, including the events dropped for slow WebSocket clients per room, on an
+// address of its own that is never exposed like the API. It returns nil when addr is empty.
+func newAdminServer(addr string) *http.Server {
+	if addr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
+
+func newRouter(controllers *controller.Controllers, middlewares *middleware.Middlewares, corsOrigins []string) *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.AccessLogger(), gin.Recovery())
+
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowAllOrigins = false
-	corsConfig.AllowOrigins = []string{
-		"http://localhost:3000",
-		"http://localhost:3001",
-		"https://chat-now.net",
-	}
+	corsConfig.AllowOrigins = corsOrigins
 
 	router.Use(cors.New(corsConfig))
 
-	route.RegisterRoutes(router, controllers)
+	route.RegisterRoutes(router, controllers, middlewares)
 
-	return router.Run(":8080")
+	return router
 }
 
-func initializeControllers(ctx context.Context) (*controller.Controllers, error) {
-	hm := model.NewRoomHubManager()
+func initializeControllers(ctx context.Context, cfg *config.Config) (*controller.Controllers, *middleware.Middlewares, error) {
+	b := initializeBroadcaster(cfg.Redis.Addr)
+	hm := model.NewRoomHubManager(b, cfg.Hub.IdleTimeout)
+	gh := model.InitGlobalHub(b)
 
-	db, err := initializeDynamodbClient()
+	repos, err := initializeRepositories(ctx, cfg)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	svc, err := initializeSecretManagerClient()
+	fa, err := initializeAuthenticator(ctx, cfg)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	input := &secretsmanager.GetSecretValueInput{
-		SecretId: aws.String("firebase-creds"),
-	}
+	au := usecase.NewAuthorizationUsecase(repos.room, repos.roomUser)
+	ru := usecase.NewRoomUsecase(repos.room, repos.user, au)
+	atu := usecase.NewAttachmentUsecase(repos.attachment, repos.attachmentStorage, au, cfg.AttachmentConfig(), clock.RealClocker{})
+	ruu := usecase.NewRoomUserUsecase(repos.roomUser, repos.user, repos.room, repos.message, au, gh, hm)
+	uu := usecase.NewUserUsecase(repos.user)
+	mu := usecase.NewMessageUsecase(repos.message, repos.roomUser, repos.reaction, repos.messageSearch, atu, au, hm, clock.RealClocker{})
+	reu := usecase.NewReactionUsecase(repos.reaction, repos.message, au, hm, clock.RealClocker{})
+	pu := usecase.NewPresenceUsecase(repos.roomUser, repos.user, au, hm, clock.RealClocker{})
 
-	result, err := svc.GetSecretValue(input)
+	v, err := validation.NewValidator()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	opt := option.WithCredentialsJSON([]byte(*result.SecretString))
-	app, err := firebase.NewApp(ctx, nil, opt)
+	controllers := &controller.Controllers{
+		HelloController:      controller.NewHelloController(),
+		WSController:         controller.NewWSController(hm, gh, au, mu, pu, cfg.ClientConfig()),
+		RoomController:       controller.NewRoomController(ru, v),
+		RoomUserController:   controller.NewRoomUserController(ruu, v),
+		UserController:       controller.NewUserController(uu, v),
+		MessageController:    controller.NewMessageController(mu, v),
+		PresenceController:   controller.NewPresenceController(pu),
+		ReactionController:   controller.NewReactionController(reu, v),
+		AttachmentController: controller.NewAttachmentController(atu, v),
+	}
+
+	go cleanupOrphanedAttachments(ctx, atu, cfg.Attachment.CleanupInterval)
+
+	middlewares := &middleware.Middlewares{
+		AuthMiddleware:  middleware.NewAuthMiddleware(fa),
+		ErrorMiddleware: middleware.NewErrorMiddleware(),
+	}
+
+	return controllers, middlewares, nil
+}
+
+type repositories struct {
+	room              domainRepository.RoomRepository
+	roomUser          domainRepository.RoomUserRepository
+	user              domainRepository.UserRepository
+	message           domainRepository.MessageRepository
+	reaction          domainRepository.ReactionRepository
+	messageSearch     domainRepository.MessageSearchRepository
+	attachment        domainRepository.AttachmentRepository
+	attachmentStorage domainRepository.AttachmentStorage
+}
+
+// initializeRepositories stores data in DynamoDB, the default backend, or in PostgreSQL. Both search messages with
+// Elasticsearch and keep attachments in S3. The memory backend keeps everything in the process, so the API runs
+// without any of them.
+func initializeRepositories(ctx context.Context, cfg *config.Config) (*repositories, error) {
+	if cfg.Repository.Backend == config.BackendMemory {
+		log.Println("storing data in memory")
+		store := memory.NewStore()
+		return &repositories{
+			room:              memory.NewRoomRepository(store),
+			roomUser:          memory.NewRoomUserRepository(store),
+			user:              memory.NewUserRepository(store),
+			message:           memory.NewMessageRepository(store),
+			reaction:          memory.NewReactionRepository(store),
+			messageSearch:     memory.NewMessageSearchRepository(store),
+			attachment:        memory.NewAttachmentRepository(store),
+			attachmentStorage: memory.NewAttachmentStorage(store),
+		}, nil
+	}
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: cfg.Elasticsearch.URLs,
+	})
 	if err != nil {
 		return nil, err
 	}
-	client, err := app.Auth(ctx)
+	// messages stay searchable once the index is there, so the API serves without it rather than not at all
+	if err := repository.CreateMessageIndex(ctx, es); err != nil {
+		log.Printf("Failed to create the message index: %v", err)
+	}
+
+	s3Client, err := initializeS3Client(cfg.Attachment.S3Endpoint, cfg.AWS.Region)
 	if err != nil {
 		return nil, err
 	}
 
-	fa := auth.NewFirebaseAuth(client)
+	if cfg.Repository.Backend == config.BackendPostgres {
+		db, err := initializePostgres(ctx, cfg.Postgres.URL)
+		if err != nil {
+			return nil, err
+		}
 
-	rr := repository.NewRoomRepository(db)
-	rur := repository.NewRoomUserRepository(db)
-	ur := repository.NewUserRepository(db)
-	mr := repository.NewMessageRepository(db)
+		return &repositories{
+			room:              postgres.NewRoomRepository(db),
+			roomUser:          postgres.NewRoomUserRepository(db),
+			user:              postgres.NewUserRepository(db),
+			message:           postgres.NewMessageRepository(db),
+			reaction:          postgres.NewReactionRepository(db),
+			messageSearch:     repository.NewMessageSearchRepository(es),
+			attachment:        postgres.NewAttachmentRepository(db),
+			attachmentStorage: storage.NewS3Storage(s3Client, cfg.Attachment.Bucket),
+		}, nil
+	}
 
-	ru := usecase.NewRoomUsecase(rr, ur)
-	ruu := usecase.NewRoomUserUsecase(rur, ur, rr)
-	uu := usecase.NewUserUsecase(ur, fa)
-	mu := usecase.NewMessageUsecase(mr)
+	db, err := schema.NewClient(cfg.DynamoDB.Endpoint, cfg.DynamoDB.Region)
+	if err != nil {
+		return nil, err
+	}
+	tables := schema.NewTableNames(cfg.DynamoDB.TablePrefix)
 
-	v := validator.New()
+	return &repositories{
+		room:              repository.NewRoomRepository(db, tables),
+		roomUser:          repository.NewRoomUserRepository(db, tables),
+		user:              repository.NewUserRepository(db, tables),
+		message:           repository.NewMessageRepository(db, tables),
+		reaction:          repository.NewReactionRepository(db, tables),
+		messageSearch:     repository.NewMessageSearchRepository(es),
+		attachment:        repository.NewAttachmentRepository(db, tables),
+		attachmentStorage: storage.NewS3Storage(s3Client, cfg.Attachment.Bucket),
+	}, nil
+}
 
-	if err := v.RegisterValidation("alnumdash", isAlnumOrDash); err != nil {
+// initializePostgres connects to the database at url and applies the migrations that are missing.
+func initializePostgres(ctx context.Context, url string) (*sql.DB, error) {
+	db, err := postgres.Open(ctx, url)
+	if err != nil {
 		return nil, err
 	}
 
-	controllers := &controller.Controllers{
-		HelloController:    controller.NewHelloController(),
-		WSController:       controller.NewWSController(hm),
-		RoomController:     controller.NewRoomController(ru, v),
-		RoomUserController: controller.NewRoomUserController(ruu, v),
-		UserController:     controller.NewUserController(uu, v),
-		MessageController:  controller.NewMessageController(mu, v),
+	if err := postgres.Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
 	}
 
-	return controllers, nil
+	return db, nil
 }
 
-func initializeDynamodbClient() (*dynamodb.DynamoDB, error) {
-	if os.Getenv("APP_ENV") == "local" {
-		sess, err := session.NewSession(&aws.Config{
-			Region:   aws.String("ap-northeast-1"),
-			Endpoint: aws.String("http://dynamodb-local:8000"),
-		})
-		if err != nil {
-			return nil, err
-		}
-		return dynamodb.New(sess), nil
+// initializeAuthenticator verifies ID tokens with the Firebase credentials stored in Secrets Manager. The stub
+// provider accepts any token as the UID of its sender and is meant for local runs and tests.
+func initializeAuthenticator(ctx context.Context, cfg *config.Config) (auth.FirebaseAuthenticator, error) {
+	if cfg.Auth.Provider == config.AuthProviderStub {
+		log.Println("WARNING: ID tokens are not verified; every token is trusted as the UID of its sender")
+		return auth.NewStubAuth(), nil
 	}
 
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String("ap-northeast-1"),
-	})
+	svc, err := initializeSecretManagerClient(cfg.Auth.SecretsManagerEndpoint, cfg.AWS.Region)
 	if err != nil {
 		return nil, err
 	}
 
-	return dynamodb.New(sess), nil
+	input := &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(cfg.Auth.FirebaseSecretID),
+	}
+
+	result, err := svc.GetSecretValue(input)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := option.WithCredentialsJSON([]byte(*result.SecretString))
+	app, err := firebase.NewApp(ctx, nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	client, err := app.Auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewFirebaseAuth(client), nil
 }
 
-func initializeSecretManagerClient() (*secretsmanager.SecretsManager, error) {
-	if os.Getenv("APP_ENV") == "local" {
-		log.Println("local mode")
-		sess, err := session.NewSession(&aws.Config{
-			Region:   aws.String("ap-northeast-1"),
-			Endpoint: aws.String("http://localstack:4566"),
-		})
-		if err != nil {
-			return nil, err
+// initializeBroadcaster shares hub events between instances through the Redis at addr, unless it is empty.
+func initializeBroadcaster(addr string) model.Broadcaster {
+	if addr == "" {
+		return model.NewLocalBroadcaster()
+	}
+
+	log.Printf("broadcasting through redis at %s", addr)
+	return broadcaster.NewRedisBroadcaster(redis.NewClient(&redis.Options{
+		Addr: addr,
+	}))
+}
+
+// cleanupOrphanedAttachments deletes the uploads that never made it into a message every interval until ctx is done.
+func cleanupOrphanedAttachments(ctx context.Context, attachmentUsecase domainUsecase.AttachmentUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := attachmentUsecase.CleanupOrphaned(ctx)
+			if err != nil {
+				log.Printf("Failed to clean up orphaned attachments: %v", err)
+			}
+			if deleted > 0 {
+				log.Printf("Deleted %d orphaned attachments", deleted)
+			}
 		}
-		return secretsmanager.New(sess), nil
 	}
+}
 
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String("ap-northeast-1"),
-	})
+// initializeSecretManagerClient talks to Secrets Manager at endpoint, such as LocalStack, or AWS when it is empty.
+func initializeSecretManagerClient(endpoint, region string) (*secretsmanager.SecretsManager, error) {
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+	}
+
+	sess, err := session.NewSession(config)
 	if err != nil {
 		return nil, err
 	}
 	return secretsmanager.New(sess), nil
 }
 
-func isAlnumOrDash(fl validator.FieldLevel) bool {
-	return regexp.MustCompile(`^[a-zA-Z0-9_-]+$`).MatchString(fl.Field().String())
+// initializeS3Client talks to S3 at endpoint, such as LocalStack, or AWS when it is empty. Presigned URLs are
+// built from the endpoint, so it has to be reachable by clients as well as by the server.
+func initializeS3Client(endpoint, region string) (*s3.S3, error) {
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess), nil
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer starts the API the way main does, with the in-memory repositories and the stub authenticator.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.Repository.Backend = config.BackendMemory
+	cfg.Auth.Provider = config.AuthProviderStub
+	require.NoError(t, cfg.Validate())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	controllers, middlewares, err := initializeControllers(ctx, cfg)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(newRouter(controllers, middlewares, cfg.Server.CORSOrigins))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// do sends the request as the user, whose ID is the token the stub authenticator accepts, and decodes the "result"
+// of the response into result when it is not nil.
+func do(t *testing.T, server *httptest.Server, userId, method, path string, body interface{}, result interface{}) (int, string) {
+	t.Helper()
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+
+	req, err := http.NewRequest(method, server.URL+path, &reqBody)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if userId != "" {
+		req.Header.Set("Authorization", "Bearer "+userId)
+	}
+
+	res, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var resBody struct {
+		Result  json.RawMessage `json:"result"`
+		NextKey string          `json:"nextKey"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resBody))
+	if result != nil {
+		require.NoError(t, json.Unmarshal(resBody.Result, result), string(resBody.Result))
+	}
+
+	return res.StatusCode, resBody.NextKey
+}
+
+func TestAPI_InMemory(t *testing.T) {
+	server := newTestServer(t)
+
+	status, _ := do(t, server, "", http.MethodGet, "/api/hello", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	for _, userId := range []string{"alice", "bob"} {
+		status, _ := do(t, server, userId, http.MethodPost, "/api/users", map[string]string{
+			"name":     userId,
+			"email":    userId + "@example.com",
+			"imageUrl": "https://example.com/" + userId + ".png",
+		}, nil)
+		require.Equal(t, http.StatusOK, status)
+	}
+
+	var room struct {
+		RoomID string `json:"roomId"`
+	}
+	status, _ = do(t, server, "alice", http.MethodPost, "/api/rooms", map[string]string{
+		"name":     "general",
+		"roomType": "private",
+	}, &room)
+	require.Equal(t, http.StatusCreated, status)
+	require.NotEmpty(t, room.RoomID)
+	messagesPath := "/api/rooms/" + room.RoomID + "/messages"
+
+	// private rooms are not revealed to outsiders
+	status, _ = do(t, server, "bob", http.MethodGet, messagesPath, nil, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = do(t, server, "alice", http.MethodPost, "/api/rooms/"+room.RoomID+"/users", map[string][]string{
+		"userIds": {"bob"},
+	}, nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	for _, content := range []string{"hello bob", "how are you?", "Hello again"} {
+		status, _ := do(t, server, "alice", http.MethodPost, messagesPath, map[string]string{
+			"content": content,
+		}, nil)
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	type message struct {
+		Content string `json:"content"`
+		UserID  string `json:"userId"`
+	}
+
+	var firstPage []message
+	status, nextKey := do(t, server, "bob", http.MethodGet, messagesPath+"?limit=2", nil, &firstPage)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []message{{"Hello again", "alice"}, {"how are you?", "alice"}}, firstPage)
+	require.NotEmpty(t, nextKey)
+
+	var secondPage []message
+	status, nextKey = do(t, server, "bob", http.MethodGet, messagesPath+"?limit=2&lastEvaluatedKey="+nextKey, nil, &secondPage)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []message{{"hello bob", "alice"}}, secondPage)
+	assert.Empty(t, nextKey)
+
+	var hits []struct {
+		Message    message  `json:"message"`
+		Highlights []string `json:"highlights"`
+	}
+	status, _ = do(t, server, "bob", http.MethodGet, "/api/search/messages?q=hello", nil, &hits)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, hits, 2)
+	assert.Equal(t, []string{"<em>Hello</em> again"}, hits[0].Highlights)
+	assert.Equal(t, []string{"<em>hello</em> bob"}, hits[1].Highlights)
+}
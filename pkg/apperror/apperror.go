@@ -1,5 +1,35 @@
 package apperror
 
+import (
+	"errors"
+	"time"
+)
+
+// Code is the machine-readable kind of an error, which clients can branch on instead of parsing messages.
+type Code string
+
+const (
+	CodeNotFound        Code = "not_found"
+	CodeConflict        Code = "conflict"
+	CodeForbidden       Code = "forbidden"
+	CodeUnauthenticated Code = "unauthenticated"
+	CodeBadRequest      Code = "bad_request"
+	CodeValidation      Code = "validation_failed"
+	CodeRateLimited     Code = "rate_limited"
+	CodeUnavailable     Code = "unavailable"
+	CodeInternal        Code = "internal"
+)
+
+// CodeOf returns the code of the first error in the chain of err that has one, and CodeInternal for errors
+// that are not part of the taxonomy.
+func CodeOf(err error) Code {
+	var coded interface{ Code() Code }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	return CodeInternal
+}
+
 type NotFoundErr struct {
 	Resource string
 	Detail   string
@@ -9,6 +39,10 @@ func (e *NotFoundErr) Error() string {
 	return e.Resource + " " + e.Detail + ": not found"
 }
 
+func (e *NotFoundErr) Code() Code {
+	return CodeNotFound
+}
+
 func NewNotFoundErr(resource, detail string) *NotFoundErr {
 	return &NotFoundErr{
 		Resource: resource,
@@ -16,6 +50,7 @@ func NewNotFoundErr(resource, detail string) *NotFoundErr {
 	}
 }
 
+// AlreadyExistsErr is the conflict of creating, or renaming to, something that exists already.
 type AlreadyExistsErr struct {
 	Resource string
 	Detail   string
@@ -25,9 +60,174 @@ func (e *AlreadyExistsErr) Error() string {
 	return e.Resource + " " + e.Detail + ": already exists"
 }
 
+func (e *AlreadyExistsErr) Code() Code {
+	return CodeConflict
+}
+
 func NewAlreadyExistsErr(resource, detail string) *AlreadyExistsErr {
 	return &AlreadyExistsErr{
 		Resource: resource,
 		Detail:   detail,
 	}
 }
+
+// ConflictErr is a request that cannot be applied to the current state of the resource.
+type ConflictErr struct {
+	Resource string
+	Detail   string
+}
+
+func (e *ConflictErr) Error() string {
+	return e.Resource + " " + e.Detail + ": conflict"
+}
+
+func (e *ConflictErr) Code() Code {
+	return CodeConflict
+}
+
+func NewConflictErr(resource, detail string) *ConflictErr {
+	return &ConflictErr{
+		Resource: resource,
+		Detail:   detail,
+	}
+}
+
+type ForbiddenErr struct {
+	Resource string
+	Detail   string
+}
+
+func (e *ForbiddenErr) Error() string {
+	return e.Resource + " " + e.Detail + ": forbidden"
+}
+
+func (e *ForbiddenErr) Code() Code {
+	return CodeForbidden
+}
+
+func NewForbiddenErr(resource, detail string) *ForbiddenErr {
+	return &ForbiddenErr{
+		Resource: resource,
+		Detail:   detail,
+	}
+}
+
+// UnauthenticatedErr is a request without valid credentials.
+type UnauthenticatedErr struct {
+	Resource string
+	Detail   string
+}
+
+func (e *UnauthenticatedErr) Error() string {
+	return e.Resource + " " + e.Detail + ": unauthenticated"
+}
+
+func (e *UnauthenticatedErr) Code() Code {
+	return CodeUnauthenticated
+}
+
+func NewUnauthenticatedErr(resource, detail string) *UnauthenticatedErr {
+	return &UnauthenticatedErr{
+		Resource: resource,
+		Detail:   detail,
+	}
+}
+
+// BadRequestErr is a request that breaks a rule of the application, such as a limit or an allowed content type.
+type BadRequestErr struct {
+	Resource string
+	Detail   string
+}
+
+func (e *BadRequestErr) Error() string {
+	return e.Resource + " " + e.Detail + ": bad request"
+}
+
+func (e *BadRequestErr) Code() Code {
+	return CodeBadRequest
+}
+
+func NewBadRequestErr(resource, detail string) *BadRequestErr {
+	return &BadRequestErr{
+		Resource: resource,
+		Detail:   detail,
+	}
+}
+
+// ValidationErr is a request whose body or parameters are malformed or fail validation. Fields lists the
+// fields that failed, when the failure is down to fields.
+type ValidationErr struct {
+	Resource string
+	Detail   string
+	Fields   []FieldError
+}
+
+// FieldError is a field of a request that failed a rule of validation. Field is the name the field has in
+// JSON, and Message describes the failure in the language of the request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationErr) Error() string {
+	return e.Resource + " " + e.Detail + ": validation failed"
+}
+
+func (e *ValidationErr) Code() Code {
+	return CodeValidation
+}
+
+func NewValidationErr(resource, detail string, fields ...FieldError) *ValidationErr {
+	return &ValidationErr{
+		Resource: resource,
+		Detail:   detail,
+		Fields:   fields,
+	}
+}
+
+// RateLimitedErr is a request refused for being over a limit of requests. RetryAfter is how long the client
+// should wait before trying again, or zero when it is not known.
+type RateLimitedErr struct {
+	Resource   string
+	Detail     string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedErr) Error() string {
+	return e.Resource + " " + e.Detail + ": rate limited"
+}
+
+func (e *RateLimitedErr) Code() Code {
+	return CodeRateLimited
+}
+
+func NewRateLimitedErr(resource, detail string, retryAfter time.Duration) *RateLimitedErr {
+	return &RateLimitedErr{
+		Resource:   resource,
+		Detail:     detail,
+		RetryAfter: retryAfter,
+	}
+}
+
+// UnavailableErr is a request the server cannot serve for now, such as while it is shutting down.
+type UnavailableErr struct {
+	Resource string
+	Detail   string
+}
+
+func (e *UnavailableErr) Error() string {
+	return e.Resource + " " + e.Detail + ": unavailable"
+}
+
+func (e *UnavailableErr) Code() Code {
+	return CodeUnavailable
+}
+
+func NewUnavailableErr(resource, detail string) *UnavailableErr {
+	return &UnavailableErr{
+		Resource: resource,
+		Detail:   detail,
+	}
+}
@@ -0,0 +1,19 @@
+package authctx
+
+import "context"
+
+type userIDKey struct{}
+
+// WithUserID returns a copy of ctx that carries the ID of the authenticated user.
+func WithUserID(ctx context.Context, userId string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userId)
+}
+
+// UserID returns the ID of the authenticated user stored in ctx, if any.
+func UserID(ctx context.Context) (string, bool) {
+	userId, ok := ctx.Value(userIDKey{}).(string)
+	if !ok || userId == "" {
+		return "", false
+	}
+	return userId, true
+}
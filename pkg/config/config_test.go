@@ -0,0 +1,314 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfigFile(t, `
+env: staging
+server:
+  addr: ":9090"
+  corsOrigins: ["https://staging.chat-now.net"]
+dynamodb:
+  tablePrefix: file-
+websocket:
+  pingInterval: 20s
+  pongWait: 30s
+`)
+	t.Setenv("DYNAMODB_TABLE_PREFIX", "env-")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "staging", cfg.Env)
+	assert.Equal(t, ":9090", cfg.Server.Addr)
+	assert.Equal(t, []string{"https://staging.chat-now.net"}, cfg.Server.CORSOrigins)
+	assert.Equal(t, 20*time.Second, cfg.WebSocket.PingInterval)
+	assert.Equal(t, 30*time.Second, cfg.WebSocket.PongWait)
+	// the environment wins over the file
+	assert.Equal(t, "env-", cfg.DynamoDB.TablePrefix)
+	// the region of DynamoDB follows the region of AWS
+	assert.Equal(t, "us-east-1", cfg.DynamoDB.Region)
+	// what neither sets keeps its default
+	assert.Equal(t, Default().Attachment, cfg.Attachment)
+}
+
+func TestLoad_Example(t *testing.T) {
+	defaults, err := Load("")
+	require.NoError(t, err)
+
+	// the example documents the defaults, so loading it changes nothing
+	example, err := Load(filepath.Join("..", "..", "config.example.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, defaults, example)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "unknown key",
+			content: "server:\n  port: 8080\n",
+		},
+		{
+			name:    "invalid duration",
+			content: "hub:\n  idleTimeout: soon\n",
+		},
+		{
+			name:    "invalid setting",
+			content: "repository:\n  backend: mongodb\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfigFile(t, tt.content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoadEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		check       func(t *testing.T, cfg *Config)
+		expectedErr bool
+	}{
+		{
+			name: "strings",
+			env:  map[string]string{"REPOSITORY_BACKEND": "postgres", "DATABASE_URL": "postgres://db", "FIREBASE_SECRET_ID": "creds"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, BackendPostgres, cfg.Repository.Backend)
+				assert.Equal(t, "postgres://db", cfg.Postgres.URL)
+				assert.Equal(t, "creds", cfg.Auth.FirebaseSecretID)
+			},
+		},
+		{
+			name: "lists are separated by commas",
+			env:  map[string]string{"ATTACHMENT_CONTENT_TYPES": "image/png, image/gif", "ELASTICSEARCH_URL": "http://es1:9200,http://es2:9200"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []string{"image/png", "image/gif"}, cfg.Attachment.ContentTypes)
+				assert.Equal(t, []string{"http://es1:9200", "http://es2:9200"}, cfg.Elasticsearch.URLs)
+			},
+		},
+		{
+			name: "numbers and durations",
+			env:  map[string]string{"CLIENT_SEND_QUEUE_SIZE": "16", "ATTACHMENT_MAX_SIZE": "1024", "SHUTDOWN_TIMEOUT": "5s"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 16, cfg.WebSocket.SendQueueSize)
+				assert.Equal(t, int64(1024), cfg.Attachment.MaxSize)
+				assert.Equal(t, 5*time.Second, cfg.Server.ShutdownTimeout)
+			},
+		},
+		{
+			name: "empty variables are ignored",
+			env:  map[string]string{"SERVER_ADDR": ""},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":8080", cfg.Server.Addr)
+			},
+		},
+		{
+			name:        "invalid number",
+			env:         map[string]string{"CLIENT_DROP_THRESHOLD": "many"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid duration",
+			env:         map[string]string{"WS_PONG_WAIT": "1 minute"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			err := cfg.loadEnv(func(name string) string { return tt.env[name] })
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestApplyEnvDefaults(t *testing.T) {
+	local := Default()
+	local.Env = EnvLocal
+	local.DynamoDB.Endpoint = "http://localhost:8000"
+	local.applyEnvDefaults()
+
+	assert.Equal(t, "http://localhost:8000", local.DynamoDB.Endpoint, "an endpoint that is set is kept")
+	assert.Equal(t, []string{"http://elasticsearch:9200"}, local.Elasticsearch.URLs)
+	assert.Equal(t, "http://localstack:4566", local.Attachment.S3Endpoint)
+	assert.NotEmpty(t, local.Postgres.URL)
+
+	production := Default()
+	production.applyEnvDefaults()
+
+	assert.Empty(t, production.DynamoDB.Endpoint)
+	assert.Empty(t, production.Attachment.S3Endpoint)
+	assert.Equal(t, production.AWS.Region, production.DynamoDB.Region)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:   "defaults",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:        "unknown backend",
+			modify:      func(cfg *Config) { cfg.Repository.Backend = "mongodb" },
+			expectedErr: `unknown repository.backend "mongodb"`,
+		},
+		{
+			name:        "admin address shared with the api",
+			modify:      func(cfg *Config) { cfg.Server.AdminAddr = cfg.Server.Addr },
+			expectedErr: "server.adminAddr must differ from server.addr",
+		},
+		{
+			name:        "postgres without a url",
+			modify:      func(cfg *Config) { cfg.Repository.Backend = BackendPostgres },
+			expectedErr: "postgres.url is required",
+		},
+		{
+			name:        "table prefix with invalid characters",
+			modify:      func(cfg *Config) { cfg.DynamoDB.TablePrefix = "staging/" },
+			expectedErr: "dynamodb.tablePrefix",
+		},
+		{
+			name:        "unknown auth provider",
+			modify:      func(cfg *Config) { cfg.Auth.Provider = "google" },
+			expectedErr: `unknown auth.provider "google"`,
+		},
+		{
+			name:        "stub auth with persistent data",
+			modify:      func(cfg *Config) { cfg.Auth.Provider = AuthProviderStub },
+			expectedErr: `auth.provider "stub" is only allowed with env "local" or the memory backend`,
+		},
+		{
+			name:   "stub auth in the local environment",
+			modify: func(cfg *Config) { cfg.Env = EnvLocal; cfg.Auth.Provider = AuthProviderStub },
+		},
+		{
+			name:   "stub auth with the memory backend",
+			modify: func(cfg *Config) { cfg.Repository.Backend = BackendMemory; cfg.Auth.Provider = AuthProviderStub },
+		},
+		{
+			name:        "invalid backpressure policy",
+			modify:      func(cfg *Config) { cfg.WebSocket.BackpressurePolicy = "block" },
+			expectedErr: "websocket.backpressurePolicy",
+		},
+		{
+			name: "ping interval not shorter than pong wait",
+			modify: func(cfg *Config) {
+				cfg.WebSocket.PingInterval = time.Minute
+				cfg.WebSocket.PongWait = time.Minute
+			},
+			expectedErr: "websocket.pingInterval (1m0s) must be shorter than websocket.pongWait (1m0s)",
+		},
+		{
+			name:        "zero ping interval",
+			modify:      func(cfg *Config) { cfg.WebSocket.PingInterval = 0 },
+			expectedErr: "websocket.pingInterval must be positive",
+		},
+		{
+			name:        "negative ping interval",
+			modify:      func(cfg *Config) { cfg.WebSocket.PingInterval = -time.Second },
+			expectedErr: "websocket.pingInterval must be positive",
+		},
+		{
+			name:        "zero write wait",
+			modify:      func(cfg *Config) { cfg.WebSocket.WriteWait = 0 },
+			expectedErr: "websocket.writeWait must be positive",
+		},
+		{
+			name:   "idle hubs kept forever",
+			modify: func(cfg *Config) { cfg.Hub.IdleTimeout = 0 },
+		},
+		{
+			name:        "negative idle timeout",
+			modify:      func(cfg *Config) { cfg.Hub.IdleTimeout = -time.Minute },
+			expectedErr: "hub.idleTimeout must not be negative",
+		},
+		{
+			name:        "orphan ttl not longer than upload expiry",
+			modify:      func(cfg *Config) { cfg.Attachment.OrphanTTL = cfg.Attachment.UploadExpiry },
+			expectedErr: "attachment.orphanTTL",
+		},
+		{
+			name:   "the memory backend needs no bucket",
+			modify: func(cfg *Config) { cfg.Repository.Backend = BackendMemory; cfg.Attachment.Bucket = "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.applyEnvDefaults()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.applyEnvDefaults()
+	cfg.Server.Addr = ""
+	cfg.Hub.IdleTimeout = -time.Minute
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.addr is required")
+	assert.Contains(t, err.Error(), "hub.idleTimeout must not be negative")
+}
+
+func TestClientConfig(t *testing.T) {
+	cfg := Default()
+	cfg.WebSocket.SendQueueSize = 8
+	cfg.WebSocket.BackpressurePolicy = string(model.Coalesce)
+
+	clientConfig := cfg.ClientConfig()
+	assert.Equal(t, 8, clientConfig.SendQueue.Size)
+	assert.Equal(t, model.Coalesce, clientConfig.SendQueue.Policy)
+	// settings the config does not expose keep their defaults
+	assert.Equal(t, model.DefaultClientConfig().TypingTimeout, clientConfig.TypingTimeout)
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadEnv overrides the settings with the environment variables that are set and not empty.
+func (c *Config) loadEnv(getenv func(string) string) error {
+	values := map[string]*string{
+		"APP_ENV":                    &c.Env,
+		"SERVER_ADDR":                &c.Server.Addr,
+		"SERVER_ADMIN_ADDR":          &c.Server.AdminAddr,
+		"AWS_REGION":                 &c.AWS.Region,
+		"REPOSITORY_BACKEND":         &c.Repository.Backend,
+		"DYNAMODB_ENDPOINT":          &c.DynamoDB.Endpoint,
+		"DYNAMODB_REGION":            &c.DynamoDB.Region,
+		"DYNAMODB_TABLE_PREFIX":      &c.DynamoDB.TablePrefix,
+		"DATABASE_URL":               &c.Postgres.URL,
+		"AUTH_PROVIDER":              &c.Auth.Provider,
+		"FIREBASE_SECRET_ID":         &c.Auth.FirebaseSecretID,
+		"SECRETS_MANAGER_ENDPOINT":   &c.Auth.SecretsManagerEndpoint,
+		"REDIS_ADDR":                 &c.Redis.Addr,
+		"CLIENT_BACKPRESSURE_POLICY": &c.WebSocket.BackpressurePolicy,
+		"ATTACHMENT_BUCKET":          &c.Attachment.Bucket,
+		"S3_ENDPOINT":                &c.Attachment.S3Endpoint,
+	}
+	for name, value := range values {
+		if v := getenv(name); v != "" {
+			*value = v
+		}
+	}
+
+	// lists are separated by commas
+	lists := map[string]*[]string{
+		"CORS_ORIGINS":             &c.Server.CORSOrigins,
+		"ELASTICSEARCH_URL":        &c.Elasticsearch.URLs,
+		"ATTACHMENT_CONTENT_TYPES": &c.Attachment.ContentTypes,
+	}
+	for name, value := range lists {
+		if v := getenv(name); v != "" {
+			*value = nil
+			for _, item := range strings.Split(v, ",") {
+				*value = append(*value, strings.TrimSpace(item))
+			}
+		}
+	}
+
+	ints := map[string]*int{
+		"CLIENT_SEND_QUEUE_SIZE": &c.WebSocket.SendQueueSize,
+		"CLIENT_DROP_THRESHOLD":  &c.WebSocket.DropThreshold,
+	}
+	for name, value := range ints {
+		if v := getenv(name); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*value = parsed
+		}
+	}
+
+	int64s := map[string]*int64{
+		"WS_MAX_MESSAGE_SIZE": &c.WebSocket.MaxMessageSize,
+		"ATTACHMENT_MAX_SIZE": &c.Attachment.MaxSize,
+	}
+	for name, value := range int64s {
+		if v := getenv(name); v != "" {
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*value = parsed
+		}
+	}
+
+	// durations are written like "30s" or "10m"
+	durations := map[string]*time.Duration{
+		"SHUTDOWN_TIMEOUT":            &c.Server.ShutdownTimeout,
+		"ROOM_HUB_IDLE_TIMEOUT":       &c.Hub.IdleTimeout,
+		"WS_PING_INTERVAL":            &c.WebSocket.PingInterval,
+		"WS_PONG_WAIT":                &c.WebSocket.PongWait,
+		"WS_WRITE_WAIT":               &c.WebSocket.WriteWait,
+		"ATTACHMENT_CLEANUP_INTERVAL": &c.Attachment.CleanupInterval,
+		"ATTACHMENT_UPLOAD_EXPIRY":    &c.Attachment.UploadExpiry,
+		"ATTACHMENT_DOWNLOAD_EXPIRY":  &c.Attachment.DownloadExpiry,
+		"ATTACHMENT_ORPHAN_TTL":       &c.Attachment.OrphanTTL,
+	}
+	for name, value := range durations {
+		if v := getenv(name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*value = parsed
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+)
+
+type AttachmentStatus string
+
+const (
+	// Pending attachments have an upload URL but are not part of a message yet.
+	Pending  AttachmentStatus = "pending"
+	Attached AttachmentStatus = "attached"
+)
+
+type Attachment struct {
+	AttachmentID string           `json:"attachmentId"`
+	RoomID       string           `json:"roomId"`
+	UserID       string           `json:"userId"`
+	MessageID    string           `json:"messageId,omitempty"`
+	Name         string           `json:"name"`
+	Size         int64            `json:"size"`
+	ContentType  string           `json:"contentType"`
+	ObjectKey    string           `json:"objectKey"`
+	Status       AttachmentStatus `json:"status"`
+	CreatedAt    time.Time        `json:"createdAt"`
+}
+
+// AttachmentUpload is handed to a client to upload the file of an attachment straight to the storage.
+// The upload must send the Content-Type and Content-Length the attachment was declared with.
+type AttachmentUpload struct {
+	Attachment *Attachment `json:"attachment"`
+	UploadURL  string      `json:"uploadUrl"`
+	ExpiresAt  time.Time   `json:"expiresAt"`
+}
+
+type AttachmentDownload struct {
+	DownloadURL string    `json:"downloadUrl"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// ObjectInfo describes an uploaded file as reported by the storage.
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+type AttachmentConfig struct {
+	// MaxSize is the largest file in bytes that can be attached.
+	MaxSize int64
+	// ContentTypes are the MIME types that can be attached.
+	ContentTypes []string
+	// UploadExpiry and DownloadExpiry bound how long presigned URLs stay valid.
+	UploadExpiry   time.Duration
+	DownloadExpiry time.Duration
+	// OrphanTTL is how long a pending attachment waits for a message before its upload is deleted.
+	OrphanTTL time.Duration
+}
+
+func DefaultAttachmentConfig() AttachmentConfig {
+	return AttachmentConfig{
+		MaxSize: 10 * 1024 * 1024,
+		ContentTypes: []string{
+			"image/png",
+			"image/jpeg",
+			"image/gif",
+			"image/webp",
+			"application/pdf",
+			"text/plain",
+		},
+		UploadExpiry:   15 * time.Minute,
+		DownloadExpiry: 15 * time.Minute,
+		OrphanTTL:      24 * time.Hour,
+	}
+}
+
+// AllowsContentType reports whether files of the MIME type can be attached.
+func (ac AttachmentConfig) AllowsContentType(contentType string) bool {
+	for _, allowed := range ac.ContentTypes {
+		if allowed == contentType {
+			return true
+		}
+	}
+	return false
+}
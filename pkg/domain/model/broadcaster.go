@@ -0,0 +1,66 @@
+package model
+
+import (
+	"context"
+	"sync"
+)
+
+// Broadcaster fans events out to every hub subscribed to a topic, which may live on other server instances.
+// Handlers of one subscription are called in the order the events were published.
+//
+//go:generate mockery --name=Broadcaster --output=mocks
+type Broadcaster interface {
+	Publish(ctx context.Context, topic string, event Event) error
+	Subscribe(ctx context.Context, topic string, handler func(Event)) (unsubscribe func(), err error)
+}
+
+const globalTopic = "global"
+
+func roomTopic(roomId string) string {
+	return "room:" + roomId
+}
+
+// LocalBroadcaster delivers events to subscribers in the same process.
+type LocalBroadcaster struct {
+	handlers map[string]map[int]func(Event)
+	nextID   int
+	mu       sync.RWMutex
+}
+
+func NewLocalBroadcaster() Broadcaster {
+	return &LocalBroadcaster{
+		handlers: make(map[string]map[int]func(Event)),
+	}
+}
+
+func (lb *LocalBroadcaster) Publish(ctx context.Context, topic string, event Event) error {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	for _, handler := range lb.handlers[topic] {
+		handler(event)
+	}
+	return nil
+}
+
+func (lb *LocalBroadcaster) Subscribe(ctx context.Context, topic string, handler func(Event)) (func(), error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if _, ok := lb.handlers[topic]; !ok {
+		lb.handlers[topic] = make(map[int]func(Event))
+	}
+	id := lb.nextID
+	lb.nextID++
+	lb.handlers[topic][id] = handler
+
+	return func() {
+		lb.mu.Lock()
+		defer lb.mu.Unlock()
+
+		delete(lb.handlers[topic], id)
+		if len(lb.handlers[topic]) == 0 {
+			delete(lb.handlers, topic)
+		}
+	}, nil
+}
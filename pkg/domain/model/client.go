@@ -1,31 +1,146 @@
 package model
 
 import (
+	"context"
 	"encoding/json"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"golang.org/x/time/rate"
 )
 
+// MessageHandler stores messages and read markers received over the socket and publishes them to the room.
+type MessageHandler interface {
+	CreateMessage(ctx context.Context, message *Message) error
+	MarkAsRead(ctx context.Context, roomId, messageId string) error
+}
+
+// ShutdownCloseReason is sent with the going away close frame when hubs are stopped, telling clients to reconnect.
+const ShutdownCloseReason = "server is shutting down, please reconnect"
+
+// RemovedCloseReason is sent with the policy violation close frame to the clients of a user removed from the room.
+const RemovedCloseReason = "removed from the room"
+
+// replyBufferSize is the number of acks and errors queued for a client before its reader waits for the writer.
+const replyBufferSize = 16
+
+type ClientConfig struct {
+	SendQueue SendQueueConfig
+	// PingInterval is how often the server pings the client. It must be shorter than PongWait.
+	PingInterval time.Duration
+	// PongWait is how long the server waits for any frame, pongs included, before dropping the connection.
+	PongWait time.Duration
+	// WriteWait bounds every write to the connection.
+	WriteWait time.Duration
+	// MaxMessageSize is the largest frame in bytes accepted from the client.
+	MaxMessageSize int64
+	// TypingTimeout is how long a typing indicator lasts without a new TypingStarted from the client.
+	TypingTimeout time.Duration
+	// TypingInterval and TypingBurst limit how often a client's typing indicator is relayed to the room.
+	TypingInterval time.Duration
+	TypingBurst    int
+}
+
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		SendQueue:      DefaultSendQueueConfig(),
+		PingInterval:   54 * time.Second,
+		PongWait:       60 * time.Second,
+		WriteWait:      10 * time.Second,
+		MaxMessageSize: 64 * 1024,
+		TypingTimeout:  5 * time.Second,
+		TypingInterval: time.Second,
+		TypingBurst:    3,
+	}
+}
+
 type Client struct {
-	Conn *websocket.Conn
-	Send chan Event
-	Hub  Hub
+	Conn   *websocket.Conn
+	Hub    Hub
+	RoomID string
+	UserID string
+
+	config         ClientConfig
+	queue          *sendQueue
+	closeOnce      sync.Once
+	closeCode      int
+	closeText      string
+	messageHandler MessageHandler
+	presence       PresenceTracker
+	history        []Event
+	replayed       map[string]bool
+	replies        chan *RawEvent
+	done           chan struct{}
+	doneOnce       sync.Once
+	typingState    typingState
+	typingLimiter  *rate.Limiter
+	typingMu       sync.Mutex
 }
 
-func NewClient(ws *websocket.Conn, hub Hub) *Client {
+func NewClient(ws *websocket.Conn, hub Hub, roomId, userId string, messageHandler MessageHandler, presence PresenceTracker, config ClientConfig) *Client {
 	return &Client{
-		Conn: ws,
-		Send: make(chan Event),
-		Hub:  hub,
+		Conn:           ws,
+		Hub:            hub,
+		RoomID:         roomId,
+		UserID:         userId,
+		config:         config,
+		queue:          newSendQueue(config.SendQueue, roomId),
+		messageHandler: messageHandler,
+		presence:       presence,
+		replies:        make(chan *RawEvent, replyBufferSize),
+		done:           make(chan struct{}),
+		typingLimiter:  rate.NewLimiter(rate.Every(config.TypingInterval), config.TypingBurst),
 	}
 }
 
+// Enqueue queues an event to be written to the client. It returns false when the client is closed or
+// fell too far behind, in which case the hub should drop it.
+func (c *Client) Enqueue(event Event) bool {
+	return c.queue.push(event)
+}
+
+// Close stops the client from accepting events; the writer sends a close frame with the code and reason
+// once the queue is flushed. Only the first call has an effect.
+func (c *Client) Close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeText = reason
+		c.queue.close()
+	})
+}
+
+// SetHistory makes the writer send the messages, oldest first, followed by a HistoryReplayed event before
+// any live event. Live copies of the replayed messages are skipped. It must be called before Write.
+func (c *Client) SetHistory(messages []*Message, truncated bool) {
+	c.replayed = make(map[string]bool, len(messages))
+	for _, message := range messages {
+		c.history = append(c.history, message)
+		c.replayed[message.MessageID] = true
+	}
+	c.history = append(c.history, &HistoryReplayedDetails{
+		RoomID:    c.RoomID,
+		Count:     len(messages),
+		Truncated: truncated,
+	})
+}
+
+// Read handles the events the client sends until the connection closes. Events only the server sends, such as
+// RoomUserChange, which the room user usecase publishes on changes of membership and roles, are ignored.
 func (c *Client) Read() {
 	defer func() {
 		c.disconnect()
 	}()
 
+	c.Conn.SetReadLimit(c.config.MaxMessageSize)
+	c.extendReadDeadline()
+	c.Conn.SetPongHandler(func(string) error {
+		c.extendReadDeadline()
+		return nil
+	})
+
 	for {
 		var rawEvent RawEvent
 		err := c.Conn.ReadJSON(&rawEvent)
@@ -35,6 +150,7 @@ func (c *Client) Read() {
 			}
 			break
 		}
+		c.extendReadDeadline()
 
 		switch rawEvent.Type {
 		case MessageSent:
@@ -42,74 +158,217 @@ func (c *Client) Read() {
 			err := json.Unmarshal(rawEvent.Data, &message)
 			if err != nil {
 				log.Printf("Failed to unmarshal message: %v", err)
+				c.reply(MessageError, rawEvent.CorrelationID, &ErrorDetails{Error: "invalid message"})
 				break
 			}
-			c.Hub.BroadcastEvent(&message)
-		case RoomUserChange:
-			var eventData RoomUserDetails
-			err := json.Unmarshal(rawEvent.Data, &eventData)
-			if err != nil {
-				log.Printf("Failed to unmarshal event data: %v", err)
+			c.handleMessageSent(&message, rawEvent.CorrelationID)
+		case MessageRead:
+			var details MessageReadDetails
+			if err := json.Unmarshal(rawEvent.Data, &details); err != nil {
+				log.Printf("Failed to unmarshal read marker: %v", err)
+				c.reply(MessageError, rawEvent.CorrelationID, &ErrorDetails{Error: "invalid read marker"})
 				break
 			}
-			c.Hub.BroadcastEvent(&eventData)
+			c.handleMessageRead(details.MessageID, rawEvent.CorrelationID)
+		case TypingStarted, TypingStopped:
+			if c.RoomID == "" {
+				log.Printf("Typing event on a connection without a room: %s", rawEvent.Type)
+				break
+			}
+			if rawEvent.Type == TypingStarted {
+				c.startTyping()
+			} else {
+				c.stopTyping()
+			}
+		case PresenceChanged:
+			var presence Presence
+			if err := json.Unmarshal(rawEvent.Data, &presence); err != nil {
+				log.Printf("Failed to unmarshal presence: %v", err)
+				break
+			}
+			c.setPresence(presence.Status)
 		default:
 			log.Printf("Invalid event type: %s", rawEvent.Type)
 		}
 	}
 }
 
+// handleMessageSent saves a message sent over the socket; the message usecase broadcasts it to the room once it is stored.
+// Only the content, the parent and the attachment IDs are taken from the client; the ID, room, sender and timestamp
+// are set by the server.
+func (c *Client) handleMessageSent(received *Message, correlationId string) {
+	if c.messageHandler == nil || c.RoomID == "" {
+		c.reply(MessageError, correlationId, &ErrorDetails{Error: "messages cannot be sent on this connection"})
+		return
+	}
+	if received.Content == "" && len(received.Attachments) == 0 {
+		c.reply(MessageError, correlationId, &ErrorDetails{Error: "content is required"})
+		return
+	}
+
+	message := &Message{
+		RoomID:          c.RoomID,
+		UserID:          c.UserID,
+		Content:         received.Content,
+		ParentMessageID: received.ParentMessageID,
+	}
+	for _, attachment := range received.Attachments {
+		if attachment != nil {
+			message.Attachments = append(message.Attachments, &Attachment{AttachmentID: attachment.AttachmentID})
+		}
+	}
+
+	ctx := authctx.WithUserID(context.Background(), c.UserID)
+	if err := c.messageHandler.CreateMessage(ctx, message); err != nil {
+		log.Printf("Failed to create message: %v", err)
+		c.reply(MessageError, correlationId, &ErrorDetails{Error: err.Error()})
+		return
+	}
+
+	c.reply(MessageAck, correlationId, message)
+}
+
+// handleMessageRead advances the read marker of the client's user. The room, this client included,
+// is told through a MessageRead event, so only failures are replied to.
+func (c *Client) handleMessageRead(messageId, correlationId string) {
+	if c.messageHandler == nil || c.RoomID == "" {
+		c.reply(MessageError, correlationId, &ErrorDetails{Error: "messages cannot be read on this connection"})
+		return
+	}
+	if messageId == "" {
+		c.reply(MessageError, correlationId, &ErrorDetails{Error: "messageId is required"})
+		return
+	}
+
+	ctx := authctx.WithUserID(context.Background(), c.UserID)
+	if err := c.messageHandler.MarkAsRead(ctx, c.RoomID, messageId); err != nil {
+		log.Printf("Failed to mark message as read: %v", err)
+		c.reply(MessageError, correlationId, &ErrorDetails{Error: err.Error()})
+	}
+}
+
+func (c *Client) setPresence(s PresenceStatus) {
+	status, err := ParsePresenceStatus(string(s))
+	if err != nil {
+		log.Printf("Failed to set presence of user %s: %v", c.UserID, err)
+		return
+	}
+	if c.presence != nil {
+		c.presence.SetStatus(c, status)
+	}
+}
+
+// reply queues a frame for this client only.
+func (c *Client) reply(eventType EventType, correlationId string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to marshal reply: %v", err)
+		return
+	}
+
+	select {
+	case c.replies <- &RawEvent{Type: eventType, Data: raw, CorrelationID: correlationId}:
+	case <-c.done:
+	}
+}
+
 func (c *Client) Write() {
+	ticker := time.NewTicker(c.config.PingInterval)
 	defer func() {
+		ticker.Stop()
 		c.disconnect()
 	}()
 
+	for _, event := range c.history {
+		if err := c.writeEvent(event); err != nil {
+			log.Printf("Failed to write history: %v", err)
+			return
+		}
+	}
+	c.history = nil
+
 	for {
-		eventData, ok := <-c.Send
-		if !ok {
-			if err := c.Conn.WriteMessage(websocket.CloseMessage, []byte{}); err != nil {
-				log.Printf("Failed to write close message: %v", err)
+		select {
+		case reply := <-c.replies:
+			if err := c.writeJSON(reply); err != nil {
+				log.Printf("Failed to write reply: %v", err)
+				return
+			}
+		case <-c.queue.notify:
+			events, closed := c.queue.drain()
+			if err := c.writeEvents(events); err != nil {
+				log.Printf("Failed to write event: %v", err)
+				return
+			}
+
+			if closed {
+				message := websocket.FormatCloseMessage(c.closeCode, c.closeText)
+				if err := c.Conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(c.config.WriteWait)); err != nil {
+					log.Printf("Failed to write close message: %v", err)
+				}
+				return
+			}
+		case <-ticker.C:
+			if err := c.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.config.WriteWait)); err != nil {
+				log.Printf("Failed to write ping: %v", err)
+				return
 			}
-			return
 		}
+	}
+}
 
-		var eventType EventType
-		var err error
-		switch dataType := eventData.(type) {
-		case *Message:
-			eventType = MessageSent
-		case *RoomUserDetails:
-			eventType = RoomUserChange
-		default:
-			log.Printf("Invalid event type: %v", dataType)
+// writeEvents writes live events, skipping messages that were already replayed.
+func (c *Client) writeEvents(events []Event) error {
+	for _, event := range events {
+		if message, ok := event.(*Message); ok && c.replayed[message.MessageID] {
+			delete(c.replayed, message.MessageID)
 			continue
 		}
 
-		data, err := json.Marshal(eventData)
-		if err != nil {
-			log.Printf("Failed to marshal event: %v", err)
-			return
+		if err := c.writeEvent(event); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		rawEvent := RawEvent{
-			Type: eventType,
-			Data: data,
-		}
+func (c *Client) writeEvent(event Event) error {
+	rawEvent, err := EncodeEvent(event)
+	if err != nil {
+		log.Printf("Failed to encode event: %v", err)
+		return nil
+	}
+	return c.writeJSON(rawEvent)
+}
 
-		err = c.Conn.WriteJSON(rawEvent)
-		if err != nil {
-			log.Printf("Failed to write event: %v", err)
-			return
-		}
+func (c *Client) writeJSON(v interface{}) error {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(c.config.WriteWait)); err != nil {
+		return err
+	}
+	return c.Conn.WriteJSON(v)
+}
+
+func (c *Client) extendReadDeadline() {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.config.PongWait)); err != nil {
+		log.Printf("Failed to set read deadline: %v", err)
 	}
 }
 
 func (c *Client) disconnect() {
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
+
+	c.stopTyping()
+
 	c.Hub.UnregisterClient(c)
-	log.Printf("Unregistered client: %v", c)
+	if c.presence != nil {
+		c.presence.Disconnect(c)
+	}
+	log.Printf("Unregistered client of user %s in room %q", c.UserID, c.RoomID)
 
 	err := c.Conn.Close()
 	if err != nil {
-		log.Printf("Failed to close connection for client: %v, error: %v", c, err)
+		log.Printf("Failed to close connection of user %s in room %q: %v", c.UserID, c.RoomID, err)
 	}
 }
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func testClientConfig() ClientConfig {
+	config := DefaultClientConfig()
+	config.PingInterval = 20 * time.Millisecond
+	config.PongWait = 100 * time.Millisecond
+	config.WriteWait = 100 * time.Millisecond
+	return config
+}
+
+// dialTestClient connects to a server that registers every connection on the hub as a Client of user "1",
+// or of the user given in the userId query parameter.
+func dialTestClient(t *testing.T, hub Hub, config ClientConfig, query ...string) *websocket.Conn {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		userId := r.URL.Query().Get("userId")
+		if userId == "" {
+			userId = "1"
+		}
+		client := NewClient(conn, hub, "1", userId, nil, nil, config)
+		hub.RegisterClient(client)
+		go client.Write()
+		go client.Read()
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?"+strings.Join(query, "&"), nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return conn
+}
+
+func newTestRoomHub(t *testing.T) *RoomHub {
+	hub := NewRoomHub("1", NewLocalBroadcaster(), 0, nil)
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+	return hub
+}
+
+func TestClient_Heartbeat(t *testing.T) {
+	testCases := []struct {
+		name           string
+		answerPings    bool
+		expectedClosed bool
+	}{
+		{
+			name:           "Kept Alive By Pongs",
+			answerPings:    true,
+			expectedClosed: false,
+		},
+		{
+			name:           "Dropped Without Pongs",
+			answerPings:    false,
+			expectedClosed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := testClientConfig()
+			conn := dialTestClient(t, newTestRoomHub(t), config)
+
+			pings := make(chan struct{}, 100)
+			conn.SetPingHandler(func(data string) error {
+				pings <- struct{}{}
+				if !tc.answerPings {
+					return nil
+				}
+				return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+			})
+
+			readErr := make(chan error, 1)
+			go func() {
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						readErr <- err
+						return
+					}
+				}
+			}()
+
+			select {
+			case err := <-readErr:
+				assert.True(t, tc.expectedClosed, "connection closed: %v", err)
+			case <-time.After(5 * config.PongWait):
+				assert.False(t, tc.expectedClosed, "connection was kept open")
+			}
+			assert.NotEmpty(t, pings)
+		})
+	}
+}
+
+func TestClient_MaxMessageSize(t *testing.T) {
+	config := testClientConfig()
+	config.PongWait = time.Second
+	config.MaxMessageSize = 64
+	conn := dialTestClient(t, newTestRoomHub(t), config)
+
+	err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"MessageSent","data":{"content":"`+strings.Repeat("a", 128)+`"}}`))
+	assert.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), "unexpected error: %v", err)
+}
+
+func TestClient_IgnoresServerEvents(t *testing.T) {
+	config := testClientConfig()
+	config.PongWait = 5 * time.Second
+	hub := newTestRoomHub(t)
+	sender := dialTestClient(t, hub, config, "userId=1")
+	member := dialTestClient(t, hub, config, "userId=2")
+	// Wait until the server has registered both clients.
+	time.Sleep(50 * time.Millisecond)
+
+	err := sender.WriteJSON(&RawEvent{Type: RoomUserChange, Data: []byte(`{"roomId":"1","userId":"2","role":"owner"}`)})
+	assert.NoError(t, err)
+	sendTyping(t, sender, TypingStarted)
+
+	assert.Equal(t, []Event{&TypingStartedDetails{RoomID: "1", UserID: "1"}}, readEvents(t, member, 200*time.Millisecond))
+}
+
+func TestClient_ClosedByHub(t *testing.T) {
+	config := testClientConfig()
+	// no ping is in flight when the hub stops, as answering it after the server hung up fails the read
+	config.PingInterval = 900 * time.Millisecond
+	config.PongWait = time.Second
+	hub := newTestRoomHub(t)
+	conn := dialTestClient(t, hub, config)
+
+	// Wait until the server has registered the client.
+	time.Sleep(50 * time.Millisecond)
+	hub.Stop()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+	"time"
 )
 
 type Event interface{}
@@ -9,16 +11,217 @@ type Event interface{}
 type EventType string
 
 type RawEvent struct {
-	Type EventType       `json:"type"`
-	Data json.RawMessage `json:"data"`
+	Type          EventType       `json:"type"`
+	Data          json.RawMessage `json:"data"`
+	CorrelationID string          `json:"correlationId,omitempty"`
 }
 
 const (
 	MessageSent    EventType = "MessageSent"
 	RoomUserChange EventType = "RoomUserChange"
+	MessageEdited  EventType = "MessageEdited"
+	MessageDeleted EventType = "MessageDeleted"
+	// ThreadReplied is broadcast instead of MessageSent for replies, with the updated summary of their thread.
+	ThreadReplied EventType = "ThreadReplied"
+	// MessageRead is broadcast when a member's read marker advances. Clients send it with a messageId to mark
+	// the messages of the room up to that one as read.
+	MessageRead EventType = "MessageRead"
+	// ReactionAdded and ReactionRemoved carry the new number of reactions with the emoji on the message.
+	ReactionAdded   EventType = "ReactionAdded"
+	ReactionRemoved EventType = "ReactionRemoved"
+	// TypingStarted and TypingStopped are sent by clients and relayed to the other members of the room without being stored.
+	TypingStarted EventType = "TypingStarted"
+	TypingStopped EventType = "TypingStopped"
+	// PresenceChanged is pushed to the rooms of a user whose presence changed. Clients send it with only
+	// a status to mark their connection away or back online.
+	PresenceChanged EventType = "PresenceChanged"
+	// HistoryReplayed follows the messages replayed to a reconnecting client; later events are live.
+	HistoryReplayed EventType = "HistoryReplayed"
+	// MemberRemoved is broadcast when a user leaves or is removed from a room. The clients of that user in the
+	// room are closed instead of receiving it.
+	MemberRemoved EventType = "MemberRemoved"
+	// MessageAck and MessageError answer a MessageSent frame only to its sender,
+	// echoing the correlationId the sender attached to it.
+	MessageAck   EventType = "MessageAck"
+	MessageError EventType = "MessageError"
 )
 
 type RoomUserDetails struct {
+	RoomID string   `json:"roomId"`
+	UserID string   `json:"userId"`
+	Role   RoomRole `json:"role,omitempty"`
+}
+
+type MemberRemovedDetails struct {
+	RoomID string `json:"roomId"`
+	UserID string `json:"userId"`
+}
+
+type MessageEditedDetails struct {
+	RoomID    string `json:"roomId"`
+	MessageID string `json:"messageId"`
+	UserID    string `json:"userId"`
+	Content   string `json:"content"`
+}
+
+type MessageDeletedDetails struct {
+	RoomID    string `json:"roomId"`
+	MessageID string `json:"messageId"`
+}
+
+type ThreadRepliedDetails struct {
+	RoomID          string    `json:"roomId"`
+	ParentMessageID string    `json:"parentMessageId"`
+	Reply           *Message  `json:"reply"`
+	ReplyCount      int       `json:"replyCount"`
+	LastReplyAt     time.Time `json:"lastReplyAt"`
+}
+
+type MessageReadDetails struct {
+	RoomID    string    `json:"roomId"`
+	UserID    string    `json:"userId"`
+	MessageID string    `json:"messageId"`
+	ReadAt    time.Time `json:"readAt"`
+}
+
+type ReactionAddedDetails struct {
+	RoomID    string `json:"roomId"`
+	MessageID string `json:"messageId"`
+	UserID    string `json:"userId"`
+	Emoji     string `json:"emoji"`
+	Count     int    `json:"count"`
+}
+
+type ReactionRemovedDetails struct {
+	RoomID    string `json:"roomId"`
+	MessageID string `json:"messageId"`
+	UserID    string `json:"userId"`
+	Emoji     string `json:"emoji"`
+	Count     int    `json:"count"`
+}
+
+type TypingStartedDetails struct {
+	RoomID string `json:"roomId"`
+	UserID string `json:"userId"`
+}
+
+type TypingStoppedDetails struct {
 	RoomID string `json:"roomId"`
 	UserID string `json:"userId"`
 }
+
+type HistoryReplayedDetails struct {
+	RoomID string `json:"roomId"`
+	Count  int    `json:"count"`
+	// Truncated is set when more messages were missed than replayed; older ones must be paged through the REST API.
+	Truncated bool `json:"truncated"`
+}
+
+type ErrorDetails struct {
+	Error string `json:"error"`
+}
+
+// EncodeEvent wraps an event in the envelope sent to clients and to other server instances.
+func EncodeEvent(event Event) (*RawEvent, error) {
+	var eventType EventType
+	switch dataType := event.(type) {
+	case *Message:
+		eventType = MessageSent
+	case *RoomUserDetails:
+		eventType = RoomUserChange
+	case *MessageEditedDetails:
+		eventType = MessageEdited
+	case *MessageDeletedDetails:
+		eventType = MessageDeleted
+	case *ThreadRepliedDetails:
+		eventType = ThreadReplied
+	case *MessageReadDetails:
+		eventType = MessageRead
+	case *ReactionAddedDetails:
+		eventType = ReactionAdded
+	case *ReactionRemovedDetails:
+		eventType = ReactionRemoved
+	case *TypingStartedDetails:
+		eventType = TypingStarted
+	case *TypingStoppedDetails:
+		eventType = TypingStopped
+	case *Presence:
+		eventType = PresenceChanged
+	case *HistoryReplayedDetails:
+		eventType = HistoryReplayed
+	case *MemberRemovedDetails:
+		eventType = MemberRemoved
+	default:
+		return nil, fmt.Errorf("invalid event type: %T", dataType)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawEvent{
+		Type: eventType,
+		Data: data,
+	}, nil
+}
+
+// DecodeEvent is the inverse of EncodeEvent.
+func DecodeEvent(rawEvent *RawEvent) (Event, error) {
+	var event Event
+	switch rawEvent.Type {
+	case MessageSent:
+		event = &Message{}
+	case RoomUserChange:
+		event = &RoomUserDetails{}
+	case MessageEdited:
+		event = &MessageEditedDetails{}
+	case MessageDeleted:
+		event = &MessageDeletedDetails{}
+	case ThreadReplied:
+		event = &ThreadRepliedDetails{}
+	case MessageRead:
+		event = &MessageReadDetails{}
+	case ReactionAdded:
+		event = &ReactionAddedDetails{}
+	case ReactionRemoved:
+		event = &ReactionRemovedDetails{}
+	case TypingStarted:
+		event = &TypingStartedDetails{}
+	case TypingStopped:
+		event = &TypingStoppedDetails{}
+	case PresenceChanged:
+		event = &Presence{}
+	case HistoryReplayed:
+		event = &HistoryReplayedDetails{}
+	case MemberRemoved:
+		event = &MemberRemovedDetails{}
+	default:
+		return nil, fmt.Errorf("invalid event type: %s", rawEvent.Type)
+	}
+
+	if err := json.Unmarshal(rawEvent.Data, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// excludedUserID returns the user an event is not delivered to: typing indicators are not echoed to the typist.
+func excludedUserID(event Event) string {
+	switch e := event.(type) {
+	case *TypingStartedDetails:
+		return e.UserID
+	case *TypingStoppedDetails:
+		return e.UserID
+	default:
+		return ""
+	}
+}
+
+// removedUserID returns the user whose clients an event closes: a removed member stops receiving the room.
+func removedUserID(event Event) string {
+	if e, ok := event.(*MemberRemovedDetails); ok {
+		return e.UserID
+	}
+	return ""
+}
@@ -1,31 +1,67 @@
 package model
 
-import "sync"
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
 
 var once sync.Once
 var globalHubInstance *GlobalHub
 
 type GlobalHub struct {
-	clients   map[*Client]bool
-	broadcast chan Event
-	clientMu  sync.Mutex
+	clients     map[*Client]bool
+	broadcast   chan Event
+	clientMu    sync.Mutex
+	broadcaster Broadcaster
+	unsubscribe func()
+	done        chan struct{}
+	stopOnce    sync.Once
 }
 
-func NewGlobalHub() Hub {
-	return &GlobalHub{
-		clients:   make(map[*Client]bool),
-		broadcast: make(chan Event),
+func NewGlobalHub(broadcaster Broadcaster) Hub {
+	gh := &GlobalHub{
+		clients:     make(map[*Client]bool),
+		broadcast:   make(chan Event),
+		broadcaster: broadcaster,
+		unsubscribe: func() {},
+		done:        make(chan struct{}),
 	}
+
+	unsubscribe, err := broadcaster.Subscribe(context.Background(), globalTopic, func(event Event) {
+		select {
+		case gh.broadcast <- event:
+		case <-gh.done:
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to subscribe to %s: %v", globalTopic, err)
+	} else {
+		gh.unsubscribe = unsubscribe
+	}
+
+	return gh
 }
 
-func GetGlobalHubInstance() *GlobalHub {
+// InitGlobalHub creates the global hub on top of the broadcaster, falling back to an in-process one when it is nil.
+// It has no effect once the hub exists.
+func InitGlobalHub(broadcaster Broadcaster) *GlobalHub {
 	once.Do(func() {
-		globalHubInstance = NewGlobalHub().(*GlobalHub)
+		if broadcaster == nil {
+			broadcaster = NewLocalBroadcaster()
+		}
+		globalHubInstance = NewGlobalHub(broadcaster).(*GlobalHub)
 		go globalHubInstance.Run()
 	})
 	return globalHubInstance
 }
 
+func GetGlobalHubInstance() *GlobalHub {
+	return InitGlobalHub(nil)
+}
+
 func (gh *GlobalHub) RegisterClient(client *Client) {
 	gh.clientMu.Lock()
 	defer gh.clientMu.Unlock()
@@ -37,28 +73,60 @@ func (gh *GlobalHub) UnregisterClient(client *Client) {
 	defer gh.clientMu.Unlock()
 	if _, ok := gh.clients[client]; ok {
 		delete(gh.clients, client)
-		close(client.Send)
+		client.Close(websocket.CloseNormalClosure, "")
 	}
 }
 
 func (gh *GlobalHub) BroadcastEvent(event Event) {
-	gh.broadcast <- event.(*RoomUserDetails)
+	if err := gh.broadcaster.Publish(context.Background(), globalTopic, event); err != nil {
+		log.Printf("Failed to publish event to %s: %v", globalTopic, err)
+	}
 }
 
 func (gh *GlobalHub) Run() {
 	for {
-		event := <-gh.broadcast
+		select {
+		case event := <-gh.broadcast:
+			gh.deliver(event)
+		case <-gh.done:
+			return
+		}
+	}
+}
+
+func (gh *GlobalHub) Stop() {
+	gh.stopOnce.Do(func() {
+		close(gh.done)
+		gh.unsubscribe()
+
+		gh.clientMu.Lock()
+		defer gh.clientMu.Unlock()
+
 		for client := range gh.clients {
-			eventData, ok := event.(*RoomUserDetails)
-			if ok {
-				select {
-				case client.Send <- eventData:
-				default:
-					close(client.Send)
-					delete(gh.clients, client)
-				}
-				continue
-			}
+			delete(gh.clients, client)
+			client.Close(websocket.CloseGoingAway, ShutdownCloseReason)
+		}
+	})
+}
+
+// deliver queues a change of a member's role only for the clients of that member, since the global hub
+// does not know who else is in the room. The other members learn about it through the room.
+func (gh *GlobalHub) deliver(event Event) {
+	eventData, ok := event.(*RoomUserDetails)
+	if !ok {
+		return
+	}
+
+	gh.clientMu.Lock()
+	defer gh.clientMu.Unlock()
+
+	for client := range gh.clients {
+		if client.UserID != eventData.UserID {
+			continue
+		}
+		if !client.Enqueue(eventData) {
+			client.Close(websocket.ClosePolicyViolation, "client is too slow")
+			delete(gh.clients, client)
 		}
 	}
 }
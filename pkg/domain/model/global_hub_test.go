@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobalHub_DeliversRoleChangesToTheMemberOnly(t *testing.T) {
+	gh := NewGlobalHub(NewLocalBroadcaster()).(*GlobalHub)
+	go gh.Run()
+	defer gh.Stop()
+
+	member := NewClient(nil, gh, "", "1", nil, nil, DefaultClientConfig())
+	gh.RegisterClient(member)
+	nonMember := NewClient(nil, gh, "", "2", nil, nil, DefaultClientConfig())
+	gh.RegisterClient(nonMember)
+
+	change := &RoomUserDetails{RoomID: "private", UserID: "1", Role: Admin}
+	gh.BroadcastEvent(change)
+
+	events, _ := receive(t, member)
+	assert.Equal(t, []Event{change}, events)
+
+	// the delivery is over once the lock is free
+	gh.clientMu.Lock()
+	gh.clientMu.Unlock()
+	assert.Len(t, nonMember.queue.notify, 0)
+}
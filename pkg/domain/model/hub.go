@@ -1,8 +1,20 @@
 package model
 
+//go:generate mockery --name=Hub --output=mocks
 type Hub interface {
 	RegisterClient(*Client)
 	UnregisterClient(*Client)
 	BroadcastEvent(Event)
+	// Run delivers broadcast events to the registered clients until Stop is called.
 	Run()
+	// Stop ends Run and closes every client still registered with Client.Close, so that each writer flushes its
+	// queue and sends a going-away close frame.
+	Stop()
+}
+
+// RoomEventPublisher delivers events to the clients connected to a room.
+//
+//go:generate mockery --name=RoomEventPublisher --output=mocks
+type RoomEventPublisher interface {
+	PublishToRoom(roomId string, event Event)
 }
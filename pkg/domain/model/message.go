@@ -8,4 +8,13 @@ type Message struct {
 	UserID    string    `json:"userId"`
 	RoomID    string    `json:"roomId"`
 	CreatedAt time.Time `json:"createdAt"`
+	// ParentMessageID is set on replies to the message starting their thread.
+	ParentMessageID string `json:"parentMessageId,omitempty"`
+	// ReplyCount and LastReplyAt summarize the thread started by the message.
+	ReplyCount  int        `json:"replyCount,omitempty"`
+	LastReplyAt *time.Time `json:"lastReplyAt,omitempty"`
+	// Attachments are uploaded before the message is created; clients only send their IDs.
+	Attachments []*Attachment `json:"attachments,omitempty"`
+	// Reactions are stored apart from the message, so that reacting never rewrites the message item.
+	Reactions []*ReactionCount `json:"reactions,omitempty" dynamodbav:"-"`
 }
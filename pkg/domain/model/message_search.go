@@ -0,0 +1,8 @@
+package model
+
+// MessageSearchHit is a message matching a search. Highlights are fragments of its content with the matching
+// terms wrapped in <em> tags; the rest of the fragments is HTML escaped.
+type MessageSearchHit struct {
+	Message    *Message `json:"message"`
+	Highlights []string `json:"highlights"`
+}
@@ -0,0 +1,70 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Broadcaster is an autogenerated mock type for the Broadcaster type
+type Broadcaster struct {
+	mock.Mock
+}
+
+// Publish provides a mock function with given fields: ctx, topic, event
+func (_m *Broadcaster) Publish(ctx context.Context, topic string, event model.Event) error {
+	ret := _m.Called(ctx, topic, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.Event) error); ok {
+		r0 = rf(ctx, topic, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subscribe provides a mock function with given fields: ctx, topic, handler
+func (_m *Broadcaster) Subscribe(ctx context.Context, topic string, handler func(model.Event)) (func(), error) {
+	ret := _m.Called(ctx, topic, handler)
+
+	var r0 func()
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, func(model.Event)) (func(), error)); ok {
+		return rf(ctx, topic, handler)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, func(model.Event)) func()); ok {
+		r0 = rf(ctx, topic, handler)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func())
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, func(model.Event)) error); ok {
+		r1 = rf(ctx, topic, handler)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewBroadcaster interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBroadcaster creates a new instance of Broadcaster. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBroadcaster(t mockConstructorTestingTNewBroadcaster) *Broadcaster {
+	mock := &Broadcaster{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,53 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Hub is an autogenerated mock type for the Hub type
+type Hub struct {
+	mock.Mock
+}
+
+// BroadcastEvent provides a mock function with given fields: _a0
+func (_m *Hub) BroadcastEvent(_a0 model.Event) {
+	_m.Called(_a0)
+}
+
+// RegisterClient provides a mock function with given fields: _a0
+func (_m *Hub) RegisterClient(_a0 *model.Client) {
+	_m.Called(_a0)
+}
+
+// Run provides a mock function with given fields:
+func (_m *Hub) Run() {
+	_m.Called()
+}
+
+// Stop provides a mock function with given fields:
+func (_m *Hub) Stop() {
+	_m.Called()
+}
+
+// UnregisterClient provides a mock function with given fields: _a0
+func (_m *Hub) UnregisterClient(_a0 *model.Client) {
+	_m.Called(_a0)
+}
+
+type mockConstructorTestingTNewHub interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewHub creates a new instance of Hub. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewHub(t mockConstructorTestingTNewHub) *Hub {
+	mock := &Hub{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,43 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PresenceTracker is an autogenerated mock type for the PresenceTracker type
+type PresenceTracker struct {
+	mock.Mock
+}
+
+// Connect provides a mock function with given fields: client
+func (_m *PresenceTracker) Connect(client *model.Client) {
+	_m.Called(client)
+}
+
+// Disconnect provides a mock function with given fields: client
+func (_m *PresenceTracker) Disconnect(client *model.Client) {
+	_m.Called(client)
+}
+
+// SetStatus provides a mock function with given fields: client, status
+func (_m *PresenceTracker) SetStatus(client *model.Client, status model.PresenceStatus) {
+	_m.Called(client, status)
+}
+
+type mockConstructorTestingTNewPresenceTracker interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPresenceTracker creates a new instance of PresenceTracker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPresenceTracker(t mockConstructorTestingTNewPresenceTracker) *PresenceTracker {
+	mock := &PresenceTracker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
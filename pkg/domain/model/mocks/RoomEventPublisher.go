@@ -0,0 +1,33 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RoomEventPublisher is an autogenerated mock type for the RoomEventPublisher type
+type RoomEventPublisher struct {
+	mock.Mock
+}
+
+// PublishToRoom provides a mock function with given fields: roomId, event
+func (_m *RoomEventPublisher) PublishToRoom(roomId string, event model.Event) {
+	_m.Called(roomId, event)
+}
+
+type mockConstructorTestingTNewRoomEventPublisher interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewRoomEventPublisher creates a new instance of RoomEventPublisher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewRoomEventPublisher(t mockConstructorTestingTNewRoomEventPublisher) *RoomEventPublisher {
+	mock := &RoomEventPublisher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
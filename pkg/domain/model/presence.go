@@ -0,0 +1,45 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type PresenceStatus string
+
+const (
+	Online  PresenceStatus = "online"
+	Away    PresenceStatus = "away"
+	Offline PresenceStatus = "offline"
+)
+
+// Presence is the status of a user aggregated over all of their connections:
+// online while any connection is active, away while every connection is away and offline without connections.
+type Presence struct {
+	UserID string         `json:"userId"`
+	Status PresenceStatus `json:"status"`
+	// LastSeenAt is when the user last connected, disconnected or changed their status.
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// PresenceTracker is told about the connections of each user. A client is tracked from Connect until
+// Disconnect, which may be called more than once.
+//
+//go:generate mockery --name=PresenceTracker --output=mocks
+type PresenceTracker interface {
+	Connect(client *Client)
+	SetStatus(client *Client, status PresenceStatus)
+	Disconnect(client *Client)
+}
+
+// ParsePresenceStatus parses a status a client may set on its connection. Offline is implied by disconnecting.
+func ParsePresenceStatus(s string) (PresenceStatus, error) {
+	switch s {
+	case string(Online):
+		return Online, nil
+	case string(Away):
+		return Away, nil
+	default:
+		return "", fmt.Errorf("invalid presence status: %s", s)
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Reaction is an emoji a user reacted to a message with. A user reacts with each emoji at most once.
+type Reaction struct {
+	MessageID string    `json:"messageId"`
+	UserID    string    `json:"userId"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ReactionCount is the number of users who reacted to a message with an emoji.
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
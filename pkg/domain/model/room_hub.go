@@ -1,47 +1,184 @@
 package model
 
-import "sync"
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
 
 type RoomHub struct {
-	clients   map[*Client]bool
-	broadcast chan *Message
-	clientMu  sync.Mutex
+	roomId      string
+	clients     map[*Client]bool
+	broadcast   chan Event
+	clientMu    sync.Mutex
+	topic       string
+	broadcaster Broadcaster
+	unsubscribe func()
+	done        chan struct{}
+	stopOnce    sync.Once
+	stopped     bool
+
+	// idleTimeout is how long the hub may stay without clients before onIdle is called. Zero disables it.
+	idleTimeout time.Duration
+	onIdle      func(*RoomHub)
+	idleSince   time.Time
+	idleTimer   *time.Timer
 }
 
-func NewRoomHub() Hub {
-	return &RoomHub{
-		clients:   make(map[*Client]bool),
-		broadcast: make(chan *Message),
+func NewRoomHub(roomId string, broadcaster Broadcaster, idleTimeout time.Duration, onIdle func(*RoomHub)) *RoomHub {
+	rh := &RoomHub{
+		roomId:      roomId,
+		clients:     make(map[*Client]bool),
+		broadcast:   make(chan Event),
+		topic:       roomTopic(roomId),
+		broadcaster: broadcaster,
+		unsubscribe: func() {},
+		done:        make(chan struct{}),
+		idleTimeout: idleTimeout,
+		onIdle:      onIdle,
+	}
+
+	// Events reach the clients only through the subscription, so every instance sees the same order.
+	unsubscribe, err := broadcaster.Subscribe(context.Background(), rh.topic, func(event Event) {
+		select {
+		case rh.broadcast <- event:
+		case <-rh.done:
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to subscribe to %s: %v", rh.topic, err)
+	} else {
+		rh.unsubscribe = unsubscribe
 	}
+
+	rh.clientMu.Lock()
+	rh.markIdle()
+	rh.clientMu.Unlock()
+
+	return rh
 }
 
 func (rh *RoomHub) RegisterClient(client *Client) {
 	rh.clientMu.Lock()
 	defer rh.clientMu.Unlock()
+
+	if rh.stopped {
+		client.Close(websocket.CloseTryAgainLater, "room is closed")
+		return
+	}
+
 	rh.clients[client] = true
+	if rh.idleTimer != nil {
+		rh.idleTimer.Stop()
+		rh.idleTimer = nil
+	}
 }
 
 func (rh *RoomHub) UnregisterClient(client *Client) {
-	if _, ok := rh.clients[client]; ok {
-		delete(rh.clients, client)
-		close(client.Send)
-	}
+	rh.clientMu.Lock()
+	defer rh.clientMu.Unlock()
+
+	rh.removeClient(client, websocket.CloseNormalClosure, "")
 }
 
 func (rh *RoomHub) BroadcastEvent(event Event) {
-	rh.broadcast <- event.(*Message)
+	if err := rh.broadcaster.Publish(context.Background(), rh.topic, event); err != nil {
+		log.Printf("Failed to publish event to %s: %v", rh.topic, err)
+	}
 }
 
 func (rh *RoomHub) Run() {
 	for {
-		message := <-rh.broadcast
-		for client := range rh.clients {
-			select {
-			case client.Send <- message:
-			default:
-				close(client.Send)
-				delete(rh.clients, client)
+		select {
+		case event := <-rh.broadcast:
+			excluded := excludedUserID(event)
+			removed := removedUserID(event)
+			rh.clientMu.Lock()
+			for client := range rh.clients {
+				if removed != "" && client.UserID == removed {
+					rh.removeClient(client, websocket.ClosePolicyViolation, RemovedCloseReason)
+					continue
+				}
+				if excluded != "" && client.UserID == excluded {
+					continue
+				}
+				if !client.Enqueue(event) {
+					rh.removeClient(client, websocket.ClosePolicyViolation, "client is too slow")
+				}
 			}
+			rh.clientMu.Unlock()
+		case <-rh.done:
+			return
+		}
+	}
+}
+
+func (rh *RoomHub) Stop() {
+	rh.stopOnce.Do(func() {
+		close(rh.done)
+		rh.unsubscribe()
+
+		rh.clientMu.Lock()
+		defer rh.clientMu.Unlock()
+
+		rh.stopped = true
+		for client := range rh.clients {
+			delete(rh.clients, client)
+			client.Close(websocket.CloseGoingAway, ShutdownCloseReason)
+		}
+		if rh.idleTimer != nil {
+			rh.idleTimer.Stop()
+			rh.idleTimer = nil
 		}
+		forgetDroppedEvents(rh.roomId)
+	})
+}
+
+// Touch restarts the idle period of an empty hub, so that it is not evicted right before a client registers.
+func (rh *RoomHub) Touch() {
+	rh.clientMu.Lock()
+	defer rh.clientMu.Unlock()
+
+	if len(rh.clients) == 0 {
+		rh.markIdle()
+	}
+}
+
+// IsIdle reports whether the hub has had no clients for at least its idle timeout.
+func (rh *RoomHub) IsIdle() bool {
+	rh.clientMu.Lock()
+	defer rh.clientMu.Unlock()
+
+	return rh.idleTimeout > 0 && len(rh.clients) == 0 && time.Since(rh.idleSince) >= rh.idleTimeout
+}
+
+// removeClient must be called with clientMu held.
+func (rh *RoomHub) removeClient(client *Client, code int, reason string) {
+	if _, ok := rh.clients[client]; !ok {
+		return
+	}
+
+	delete(rh.clients, client)
+	client.Close(code, reason)
+	if len(rh.clients) == 0 {
+		rh.markIdle()
+	}
+}
+
+// markIdle must be called with clientMu held.
+func (rh *RoomHub) markIdle() {
+	if rh.idleTimeout <= 0 || rh.stopped {
+		return
+	}
+
+	rh.idleSince = time.Now()
+	if rh.idleTimer != nil {
+		rh.idleTimer.Stop()
 	}
+	rh.idleTimer = time.AfterFunc(rh.idleTimeout, func() {
+		rh.onIdle(rh)
+	})
 }
@@ -1,23 +1,80 @@
 package model
 
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
 type RoomHubManager struct {
-	roomHubs map[string]Hub
+	roomHubs    map[string]*RoomHub
+	broadcaster Broadcaster
+	idleTimeout time.Duration
+	mu          sync.Mutex
 }
 
-func NewRoomHubManager() *RoomHubManager {
+// NewRoomHubManager creates a manager whose room hubs are stopped and removed once they have had
+// no clients for idleTimeout. An idleTimeout of zero keeps them forever.
+func NewRoomHubManager(broadcaster Broadcaster, idleTimeout time.Duration) *RoomHubManager {
 	return &RoomHubManager{
-		roomHubs: make(map[string]Hub),
+		roomHubs:    make(map[string]*RoomHub),
+		broadcaster: broadcaster,
+		idleTimeout: idleTimeout,
 	}
 }
 
 func (hm *RoomHubManager) GetRoomHub(roomId string) (Hub, bool) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
 	hub, exists := hm.roomHubs[roomId]
 	return hub, exists
 }
 
-func (hm *RoomHubManager) CreateRoomHub(roomId string) Hub {
-	hub := NewRoomHub()
+// GetOrCreateRoomHub returns the running hub of the room, starting one if there is none.
+func (hm *RoomHubManager) GetOrCreateRoomHub(roomId string) Hub {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	if hub, exists := hm.roomHubs[roomId]; exists {
+		hub.Touch()
+		return hub
+	}
+
+	hub := NewRoomHub(roomId, hm.broadcaster, hm.idleTimeout, hm.evict)
 	hm.roomHubs[roomId] = hub
 	go hub.Run()
 	return hub
 }
+
+// PublishToRoom sends the event to the room's hubs on every instance, whether or not this instance has one.
+func (hm *RoomHubManager) PublishToRoom(roomId string, event Event) {
+	if err := hm.broadcaster.Publish(context.Background(), roomTopic(roomId), event); err != nil {
+		log.Printf("Failed to publish event to room %s: %v", roomId, err)
+	}
+}
+
+// Stop stops every room hub.
+func (hm *RoomHubManager) Stop() {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	for roomId, hub := range hm.roomHubs {
+		delete(hm.roomHubs, roomId)
+		hub.Stop()
+	}
+}
+
+func (hm *RoomHubManager) evict(hub *RoomHub) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	if hm.roomHubs[hub.roomId] != hub || !hub.IsIdle() {
+		return
+	}
+
+	delete(hm.roomHubs, hub.roomId)
+	hub.Stop()
+	log.Printf("Evicted idle hub of room %s", hub.roomId)
+}
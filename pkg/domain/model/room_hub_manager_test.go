@@ -0,0 +1,206 @@
+package model
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(hub Hub) *Client {
+	return NewClient(nil, hub, "1", "", nil, nil, DefaultClientConfig())
+}
+
+// receive waits for the next batch of events queued for the client and reports whether it was closed.
+func receive(t *testing.T, client *Client) ([]Event, bool) {
+	select {
+	case <-client.queue.notify:
+		return client.queue.drain()
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil, false
+	}
+}
+
+func TestGetOrCreateRoomHub_Concurrent(t *testing.T) {
+	hm := NewRoomHubManager(NewLocalBroadcaster(), 0)
+	defer hm.Stop()
+
+	hubs := make([]Hub, 50)
+	var wg sync.WaitGroup
+	for i := range hubs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hubs[i] = hm.GetOrCreateRoomHub("1")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, hub := range hubs {
+		assert.Same(t, hubs[0], hub)
+	}
+}
+
+func TestRoomHub_ConcurrentConnectsAndDisconnects(t *testing.T) {
+	hm := NewRoomHubManager(NewLocalBroadcaster(), 0)
+	defer hm.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			roomId := []string{"1", "2"}[i%2]
+			hub := hm.GetOrCreateRoomHub(roomId)
+			client := newTestClient(hub)
+			hub.RegisterClient(client)
+
+			go func() {
+				for {
+					<-client.queue.notify
+					if _, closed := client.queue.drain(); closed {
+						return
+					}
+				}
+			}()
+
+			for j := 0; j < 10; j++ {
+				hm.PublishToRoom(roomId, &Message{RoomID: roomId, Content: "Hello"})
+			}
+			hub.UnregisterClient(client)
+			hub.UnregisterClient(client)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRoomHub_Broadcast(t *testing.T) {
+	hm := NewRoomHubManager(NewLocalBroadcaster(), 0)
+	defer hm.Stop()
+
+	hub := hm.GetOrCreateRoomHub("1")
+	client := newTestClient(hub)
+	hub.RegisterClient(client)
+	otherRoomClient := newTestClient(hm.GetOrCreateRoomHub("2"))
+	otherRoomClient.Hub.RegisterClient(otherRoomClient)
+
+	message := &Message{RoomID: "1", Content: "Hello"}
+	hm.PublishToRoom("1", message)
+
+	events, _ := receive(t, client)
+	assert.Equal(t, []Event{message}, events)
+	assert.Len(t, otherRoomClient.queue.notify, 0)
+}
+
+func TestRoomHub_MemberRemoved(t *testing.T) {
+	hm := NewRoomHubManager(NewLocalBroadcaster(), 0)
+	defer hm.Stop()
+
+	hub := hm.GetOrCreateRoomHub("1")
+	member := NewClient(nil, hub, "1", "1", nil, nil, DefaultClientConfig())
+	hub.RegisterClient(member)
+	removed := NewClient(nil, hub, "1", "2", nil, nil, DefaultClientConfig())
+	hub.RegisterClient(removed)
+
+	removal := &MemberRemovedDetails{RoomID: "1", UserID: "2"}
+	hm.PublishToRoom("1", removal)
+
+	events, _ := receive(t, member)
+	assert.Equal(t, []Event{removal}, events)
+	events, closed := receive(t, removed)
+	assert.Empty(t, events)
+	assert.True(t, closed)
+	assert.Equal(t, RemovedCloseReason, removed.closeText)
+
+	// messages published after the removal no longer reach the removed user
+	hm.PublishToRoom("1", &Message{RoomID: "1", Content: "Hello"})
+	receive(t, member)
+	assert.Len(t, removed.queue.notify, 0)
+}
+
+func TestRoomHub_Stop(t *testing.T) {
+	hm := NewRoomHubManager(NewLocalBroadcaster(), 0)
+
+	hub := hm.GetOrCreateRoomHub("1")
+	client := newTestClient(hub)
+	hub.RegisterClient(client)
+
+	hm.Stop()
+
+	_, closed := receive(t, client)
+	assert.True(t, closed)
+	_, exists := hm.GetRoomHub("1")
+	assert.False(t, exists)
+
+	// Publishing to a stopped hub must not block.
+	hm.PublishToRoom("1", &Message{RoomID: "1"})
+	hub.UnregisterClient(client)
+	hub.Stop()
+
+	lateClient := newTestClient(hub)
+	hub.RegisterClient(lateClient)
+	_, closed = receive(t, lateClient)
+	assert.True(t, closed)
+}
+
+func TestRoomHub_StopForgetsDroppedEvents(t *testing.T) {
+	hm := NewRoomHubManager(NewLocalBroadcaster(), 0)
+	hub := hm.GetOrCreateRoomHub("forget-dropped")
+
+	queue := newSendQueue(SendQueueConfig{Size: 1, Policy: DropOldest}, "forget-dropped")
+	queue.push(&Message{RoomID: "forget-dropped"})
+	queue.push(&Message{RoomID: "forget-dropped"})
+	assert.Equal(t, int64(1), DroppedEvents("forget-dropped"))
+
+	hub.Stop()
+
+	assert.Nil(t, droppedEvents.Get("forget-dropped"))
+	hm.Stop()
+}
+
+func TestRoomHubManager_IdleEviction(t *testing.T) {
+	idleTimeout := 50 * time.Millisecond
+
+	testCases := []struct {
+		name          string
+		keepClient    bool
+		expectedEvict bool
+	}{
+		{
+			name:          "Evicts Empty Hub",
+			keepClient:    false,
+			expectedEvict: true,
+		},
+		{
+			name:          "Keeps Hub With Clients",
+			keepClient:    true,
+			expectedEvict: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hm := NewRoomHubManager(NewLocalBroadcaster(), idleTimeout)
+			defer hm.Stop()
+
+			hub := hm.GetOrCreateRoomHub("1")
+			client := newTestClient(hub)
+			hub.RegisterClient(client)
+			if !tc.keepClient {
+				hub.UnregisterClient(client)
+			}
+
+			time.Sleep(4 * idleTimeout)
+
+			current, exists := hm.GetRoomHub("1")
+			assert.Equal(t, !tc.expectedEvict, exists)
+			if tc.expectedEvict {
+				assert.NotSame(t, hub, hm.GetOrCreateRoomHub("1"))
+			} else {
+				assert.Same(t, hub, current)
+			}
+		})
+	}
+}
@@ -1,6 +1,68 @@
 package model
 
+import (
+	"fmt"
+	"time"
+)
+
+type RoomRole string
+
+const (
+	Owner  RoomRole = "owner"
+	Admin  RoomRole = "admin"
+	Member RoomRole = "member"
+)
+
+var roomRoleRanks = map[RoomRole]int{
+	Member: 1,
+	Admin:  2,
+	Owner:  3,
+}
+
+// Outranks reports whether r is strictly higher than other.
+func (r RoomRole) Outranks(other RoomRole) bool {
+	return roomRoleRanks[r] > roomRoleRanks[other]
+}
+
 type RoomUser struct {
-	RoomID string `json:"roomId"`
-	UserID string `json:"userId"`
+	RoomID string   `json:"roomId"`
+	UserID string   `json:"userId"`
+	Role   RoomRole `json:"role"`
+	// LastReadMessageID is the latest message the user has read in the room and LastReadAt its creation time.
+	// Both are empty until the user reads a message.
+	LastReadMessageID string    `json:"lastReadMessageId,omitempty"`
+	LastReadAt        time.Time `json:"lastReadAt"`
+}
+
+// UserRoom is a room as seen by one of its members.
+type UserRoom struct {
+	Room
+	// UnreadCount is the number of messages by other members after the member's read marker, up to MaxUnreadCount.
+	UnreadCount       int    `json:"unreadCount"`
+	LastReadMessageID string `json:"lastReadMessageId,omitempty"`
+}
+
+// MaxUnreadCount caps the unread messages counted per room; clients show larger counts as "100+".
+const MaxUnreadCount = 100
+
+// EffectiveRole returns the role of the user in the room.
+// Rows written before roles were introduced have no role and count as members.
+func (ru *RoomUser) EffectiveRole() RoomRole {
+	if ru.Role == "" {
+		return Member
+	}
+	return ru.Role
+}
+
+func ParseRoomRole(s string) (RoomRole, error) {
+	switch s {
+	case string(Owner):
+		return Owner, nil
+	case string(Admin):
+		return Admin, nil
+	case string(Member):
+		return Member, nil
+	default:
+		return "", fmt.Errorf("invalid RoomRole: %s", s)
+	}
 }
@@ -0,0 +1,174 @@
+package model
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// BackpressurePolicy decides what happens when an event arrives for a client whose send queue is full.
+type BackpressurePolicy string
+
+const (
+	// DropOldest discards the oldest queued event to make room for the new one.
+	DropOldest BackpressurePolicy = "drop-oldest"
+	// DisconnectAfterThreshold discards new events and disconnects the client once too many were discarded in a row.
+	DisconnectAfterThreshold BackpressurePolicy = "disconnect"
+	// Coalesce replaces a queued event superseded by the new one, such as an older edit of the same message,
+	// and otherwise falls back to DropOldest.
+	Coalesce BackpressurePolicy = "coalesce"
+)
+
+func ParseBackpressurePolicy(s string) (BackpressurePolicy, error) {
+	switch policy := BackpressurePolicy(s); policy {
+	case DropOldest, DisconnectAfterThreshold, Coalesce:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid backpressure policy: %s", s)
+	}
+}
+
+type SendQueueConfig struct {
+	Size   int
+	Policy BackpressurePolicy
+	// DropThreshold is the number of consecutive dropped events that disconnects a client under DisconnectAfterThreshold.
+	DropThreshold int
+}
+
+func DefaultSendQueueConfig() SendQueueConfig {
+	return SendQueueConfig{
+		Size:          64,
+		Policy:        DisconnectAfterThreshold,
+		DropThreshold: 16,
+	}
+}
+
+// droppedEvents counts the events discarded by send queues, keyed by room ID ("global" for the global hub).
+// It is published through expvar. The count of a room is forgotten when its hub stops, so the map only holds
+// rooms with a hub running rather than every room that ever had clients.
+var droppedEvents = expvar.NewMap("dropped_events")
+
+func DroppedEvents(roomId string) int64 {
+	if roomId == "" {
+		roomId = globalTopic
+	}
+	if count, ok := droppedEvents.Get(roomId).(*expvar.Int); ok {
+		return count.Value()
+	}
+	return 0
+}
+
+func forgetDroppedEvents(roomId string) {
+	droppedEvents.Delete(roomId)
+}
+
+// sendQueue is a bounded queue of events waiting to be written to a client.
+type sendQueue struct {
+	config           SendQueueConfig
+	roomId           string
+	events           []Event
+	consecutiveDrops int
+	closed           bool
+	// notify holds a token whenever there are events to drain or the queue was closed.
+	notify chan struct{}
+	mu     sync.Mutex
+}
+
+func newSendQueue(config SendQueueConfig, roomId string) *sendQueue {
+	if roomId == "" {
+		roomId = globalTopic
+	}
+	if config.Size < 1 {
+		config.Size = 1
+	}
+	return &sendQueue{
+		config: config,
+		roomId: roomId,
+		events: make([]Event, 0, config.Size),
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// push queues the event and reports whether the client can be kept.
+func (q *sendQueue) push(event Event) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false
+	}
+
+	if len(q.events) < q.config.Size {
+		q.events = append(q.events, event)
+		q.consecutiveDrops = 0
+		q.signal()
+		return true
+	}
+
+	droppedEvents.Add(q.roomId, 1)
+
+	switch q.config.Policy {
+	case DisconnectAfterThreshold:
+		q.consecutiveDrops++
+		return q.consecutiveDrops <= q.config.DropThreshold
+	case Coalesce:
+		if key, ok := coalesceKey(event); ok {
+			for i, queued := range q.events {
+				if queuedKey, ok := coalesceKey(queued); ok && queuedKey == key {
+					q.events[i] = event
+					return true
+				}
+			}
+		}
+		fallthrough
+	default:
+		q.events = append(q.events[1:], event)
+		return true
+	}
+}
+
+// drain returns the queued events and whether the queue has been closed.
+func (q *sendQueue) drain() ([]Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := q.events
+	q.events = make([]Event, 0, q.config.Size)
+	return events, q.closed
+}
+
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.signal()
+}
+
+// signal must be called with mu held.
+func (q *sendQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// coalesceKey identifies events of which only the latest one matters to a client.
+func coalesceKey(event Event) (string, bool) {
+	switch e := event.(type) {
+	case *MessageEditedDetails:
+		return "edit:" + e.RoomID + ":" + e.MessageID, true
+	case *RoomUserDetails:
+		return "room-user:" + e.RoomID + ":" + e.UserID, true
+	case *MessageReadDetails:
+		return "read:" + e.RoomID + ":" + e.UserID, true
+	case *TypingStartedDetails:
+		return "typing:" + e.RoomID + ":" + e.UserID, true
+	case *TypingStoppedDetails:
+		return "typing:" + e.RoomID + ":" + e.UserID, true
+	case *Presence:
+		return "presence:" + e.UserID, true
+	default:
+		return "", false
+	}
+}
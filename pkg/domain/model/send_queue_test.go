@@ -0,0 +1,108 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendQueue_Push(t *testing.T) {
+	edit := func(messageId, content string) *MessageEditedDetails {
+		return &MessageEditedDetails{RoomID: "1", MessageID: messageId, Content: content}
+	}
+	message := func(content string) *Message {
+		return &Message{RoomID: "1", Content: content}
+	}
+
+	testCases := []struct {
+		name           string
+		roomId         string
+		config         SendQueueConfig
+		events         []Event
+		expectedKept   []bool
+		expectedEvents []Event
+		expectedDrops  int64
+	}{
+		{
+			name:           "Within Capacity",
+			roomId:         "within-capacity",
+			config:         SendQueueConfig{Size: 2, Policy: DisconnectAfterThreshold, DropThreshold: 0},
+			events:         []Event{message("a"), message("b")},
+			expectedKept:   []bool{true, true},
+			expectedEvents: []Event{message("a"), message("b")},
+			expectedDrops:  0,
+		},
+		{
+			name:           "Drop Oldest",
+			roomId:         "drop-oldest",
+			config:         SendQueueConfig{Size: 2, Policy: DropOldest},
+			events:         []Event{message("a"), message("b"), message("c"), message("d")},
+			expectedKept:   []bool{true, true, true, true},
+			expectedEvents: []Event{message("c"), message("d")},
+			expectedDrops:  2,
+		},
+		{
+			name:           "Disconnect After Threshold",
+			roomId:         "disconnect",
+			config:         SendQueueConfig{Size: 2, Policy: DisconnectAfterThreshold, DropThreshold: 1},
+			events:         []Event{message("a"), message("b"), message("c"), message("d")},
+			expectedKept:   []bool{true, true, true, false},
+			expectedEvents: []Event{message("a"), message("b")},
+			expectedDrops:  2,
+		},
+		{
+			name:           "Coalesce",
+			roomId:         "coalesce",
+			config:         SendQueueConfig{Size: 2, Policy: Coalesce},
+			events:         []Event{edit("1", "a"), message("b"), edit("1", "c"), message("d")},
+			expectedKept:   []bool{true, true, true, true},
+			expectedEvents: []Event{message("b"), message("d")},
+			expectedDrops:  2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			queue := newSendQueue(tc.config, tc.roomId)
+			dropsBefore := DroppedEvents(tc.roomId)
+
+			for i, event := range tc.events {
+				assert.Equal(t, tc.expectedKept[i], queue.push(event))
+			}
+
+			events, closed := queue.drain()
+			assert.Equal(t, tc.expectedEvents, events)
+			assert.False(t, closed)
+			assert.Equal(t, tc.expectedDrops, DroppedEvents(tc.roomId)-dropsBefore)
+		})
+	}
+}
+
+func TestSendQueue_CoalesceReplacesQueuedEvent(t *testing.T) {
+	queue := newSendQueue(SendQueueConfig{Size: 2, Policy: Coalesce}, "coalesce-replace")
+
+	queue.push(&MessageEditedDetails{RoomID: "1", MessageID: "1", Content: "a"})
+	queue.push(&Message{RoomID: "1", Content: "b"})
+	queue.push(&MessageEditedDetails{RoomID: "1", MessageID: "1", Content: "c"})
+
+	events, _ := queue.drain()
+	assert.Equal(t, []Event{
+		&MessageEditedDetails{RoomID: "1", MessageID: "1", Content: "c"},
+		&Message{RoomID: "1", Content: "b"},
+	}, events)
+}
+
+func TestClient_Close(t *testing.T) {
+	client := NewClient(nil, nil, "1", "", nil, nil, DefaultClientConfig())
+
+	assert.True(t, client.Enqueue(&Message{RoomID: "1"}))
+	client.Close(websocket.ClosePolicyViolation, "client is too slow")
+	client.Close(websocket.CloseGoingAway, ShutdownCloseReason)
+
+	assert.False(t, client.Enqueue(&Message{RoomID: "1"}))
+	events, closed := client.queue.drain()
+	assert.Len(t, events, 1)
+	assert.True(t, closed)
+	assert.Equal(t, websocket.ClosePolicyViolation, client.closeCode)
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+)
+
+// typingState tracks whether a client is typing. A start that is never followed by a stop expires after
+// ClientConfig.TypingTimeout, and starts beyond the client's typing rate limit are ignored.
+type typingState struct {
+	typing bool
+	timer  *time.Timer
+	// generation invalidates expiry timers that fired while the state was being changed.
+	generation int
+}
+
+func (c *Client) startTyping() {
+	c.typingMu.Lock()
+	defer c.typingMu.Unlock()
+
+	if !c.typingState.typing {
+		if !c.typingLimiter.Allow() {
+			return
+		}
+		c.typingState.typing = true
+		c.Hub.BroadcastEvent(&TypingStartedDetails{RoomID: c.RoomID, UserID: c.UserID})
+	}
+
+	// Repeated starts while typing only push the expiry back.
+	if c.typingState.timer != nil {
+		c.typingState.timer.Stop()
+	}
+	c.typingState.generation++
+	generation := c.typingState.generation
+	c.typingState.timer = time.AfterFunc(c.config.TypingTimeout, func() {
+		c.expireTyping(generation)
+	})
+}
+
+func (c *Client) stopTyping() {
+	c.typingMu.Lock()
+	defer c.typingMu.Unlock()
+
+	c.stopTypingLocked()
+}
+
+func (c *Client) expireTyping(generation int) {
+	c.typingMu.Lock()
+	defer c.typingMu.Unlock()
+
+	if generation != c.typingState.generation {
+		return
+	}
+	c.stopTypingLocked()
+}
+
+// stopTypingLocked must be called with typingMu held.
+func (c *Client) stopTypingLocked() {
+	if !c.typingState.typing {
+		return
+	}
+
+	c.typingState.typing = false
+	c.typingState.generation++
+	if c.typingState.timer != nil {
+		c.typingState.timer.Stop()
+		c.typingState.timer = nil
+	}
+	c.Hub.BroadcastEvent(&TypingStoppedDetails{RoomID: c.RoomID, UserID: c.UserID})
+}
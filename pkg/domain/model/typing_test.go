@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendTyping(t *testing.T, conn *websocket.Conn, eventType EventType) {
+	err := conn.WriteJSON(&RawEvent{Type: eventType, Data: []byte(`{"userId":"spoofed"}`)})
+	assert.NoError(t, err)
+}
+
+// readEvents reads events until none arrives within wait.
+func readEvents(t *testing.T, conn *websocket.Conn, wait time.Duration) []Event {
+	var events []Event
+	for {
+		conn.SetReadDeadline(time.Now().Add(wait))
+		var rawEvent RawEvent
+		if err := conn.ReadJSON(&rawEvent); err != nil {
+			return events
+		}
+		event, err := DecodeEvent(&rawEvent)
+		assert.NoError(t, err)
+		events = append(events, event)
+	}
+}
+
+func TestClient_Typing(t *testing.T) {
+	started := &TypingStartedDetails{RoomID: "1", UserID: "1"}
+	stopped := &TypingStoppedDetails{RoomID: "1", UserID: "1"}
+
+	testCases := []struct {
+		name           string
+		typingTimeout  time.Duration
+		typingBurst    int
+		sent           []EventType
+		expectedEvents []Event
+	}{
+		{
+			name:           "Start And Stop",
+			typingTimeout:  time.Second,
+			typingBurst:    3,
+			sent:           []EventType{TypingStarted, TypingStarted, TypingStopped},
+			expectedEvents: []Event{started, stopped},
+		},
+		{
+			name:           "Expires Without Stop",
+			typingTimeout:  50 * time.Millisecond,
+			typingBurst:    3,
+			sent:           []EventType{TypingStarted},
+			expectedEvents: []Event{started, stopped},
+		},
+		{
+			name:           "Rate Limited",
+			typingTimeout:  time.Second,
+			typingBurst:    1,
+			sent:           []EventType{TypingStarted, TypingStopped, TypingStarted, TypingStopped},
+			expectedEvents: []Event{started, stopped},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := testClientConfig()
+			config.PongWait = 5 * time.Second
+			config.TypingTimeout = tc.typingTimeout
+			config.TypingInterval = time.Hour
+			config.TypingBurst = tc.typingBurst
+
+			hub := newTestRoomHub(t)
+			typist := dialTestClient(t, hub, config, "userId=1")
+			member := dialTestClient(t, hub, config, "userId=2")
+			// Wait until the server has registered both clients.
+			time.Sleep(50 * time.Millisecond)
+
+			for _, eventType := range tc.sent {
+				sendTyping(t, typist, eventType)
+			}
+
+			assert.Equal(t, tc.expectedEvents, readEvents(t, member, 200*time.Millisecond))
+			assert.Empty(t, readEvents(t, typist, 50*time.Millisecond))
+		})
+	}
+}
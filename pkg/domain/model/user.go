@@ -8,4 +8,6 @@ type User struct {
 	Email     string    `json:"email"`
 	ImageURL  string    `json:"imageUrl"`
 	CreatedAt time.Time `json:"createdAt"`
+	// LastSeenAt is when the user was last seen disconnecting from the last of their connections, if ever.
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
 }
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+)
+
+//go:generate mockery --name=AttachmentRepository --output=mocks
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *model.Attachment) error
+	GetByID(ctx context.Context, attachmentId string) (*model.Attachment, error)
+	// MarkAttached links a pending attachment to the message. It fails with a NotFoundErr when the attachment
+	// is not pending anymore.
+	MarkAttached(ctx context.Context, attachmentId, messageId string) error
+	// GetPendingBefore returns up to limit attachments still pending that were created before the time.
+	GetPendingBefore(ctx context.Context, before time.Time, limit int) ([]*model.Attachment, error)
+	Delete(ctx context.Context, attachmentId string) error
+}
+
+// AttachmentStorage keeps the files of attachments. Clients upload and download them through presigned URLs.
+//
+//go:generate mockery --name=AttachmentStorage --output=mocks
+type AttachmentStorage interface {
+	PresignUpload(ctx context.Context, objectKey, contentType string, size int64, expiry time.Duration) (string, error)
+	PresignDownload(ctx context.Context, objectKey, fileName string, expiry time.Duration) (string, error)
+	// Stat fails with a NotFoundErr when nothing was uploaded under the key.
+	Stat(ctx context.Context, objectKey string) (*model.ObjectInfo, error)
+	Delete(ctx context.Context, objectKey string) error
+}
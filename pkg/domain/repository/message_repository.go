@@ -2,15 +2,33 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 )
 
 //go:generate mockery --name=MessageRepository --output=mocks
 type MessageRepository interface {
+	// GetMessagesByRoomID pages through the timeline of the room, newest first. Replies are left out.
 	GetMessagesByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error)
+	// GetReplies pages through the replies to a message, oldest first.
+	GetReplies(ctx context.Context, roomId, parentMessageId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error)
+	// GetMessagesSince returns up to limit of the newest top-level messages created after since, oldest first.
+	// Replies are left out, since clients receive them as thread replies rather than messages. No two messages
+	// of a room share their creation time, so the creation time of a message is a cursor that skips only it.
+	GetMessagesSince(ctx context.Context, roomId string, since time.Time, limit int) ([]*model.Message, error)
+	// CountMessagesSince counts, for every room of since, the messages created after the time of the room by users
+	// other than userId, stopping at limit.
+	CountMessagesSince(ctx context.Context, userId string, since map[string]time.Time, limit int) (map[string]int, error)
 	GetByID(ctx context.Context, roomId, messageId string) (*model.Message, error)
+	// Create fails with an AlreadyExistsErr when the room has a message created at the same time, as the room
+	// and the creation time identify a message. PostgreSQL keeps times to the microsecond, so there the same
+	// time is the same microsecond.
 	Create(ctx context.Context, message *model.Message) error
 	Update(ctx context.Context, roomId, messageId, newContent string) error
 	Delete(ctx context.Context, roomId, messageId string) error
+	// AddReply counts a reply created at repliedAt in the thread summary of the parent and returns the updated parent.
+	AddReply(ctx context.Context, parent *model.Message, repliedAt time.Time) (*model.Message, error)
+	// RemoveReply takes a deleted reply out of the reply count of the parent.
+	RemoveReply(ctx context.Context, parent *model.Message) error
 }
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+)
+
+// MessageSearchRepository keeps a full-text index of messages. The Messages table stays the source of truth;
+// the index can be rebuilt from it at any time.
+//
+//go:generate mockery --name=MessageSearchRepository --output=mocks
+type MessageSearchRepository interface {
+	// Index adds the message to the index or replaces the indexed version of it.
+	Index(ctx context.Context, message *model.Message) error
+	BulkIndex(ctx context.Context, messages []*model.Message) error
+	// Delete removes the message from the index. Deleting a message that is not indexed is not an error.
+	Delete(ctx context.Context, messageId string) error
+	// Search returns the messages of the rooms matching the query, best matches first, along with the total number of matches.
+	Search(ctx context.Context, query string, roomIds []string, offset, limit int) ([]*model.MessageSearchHit, int, error)
+}
@@ -0,0 +1,126 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AttachmentRepository is an autogenerated mock type for the AttachmentRepository type
+type AttachmentRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, attachment
+func (_m *AttachmentRepository) Create(ctx context.Context, attachment *model.Attachment) error {
+	ret := _m.Called(ctx, attachment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Attachment) error); ok {
+		r0 = rf(ctx, attachment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, attachmentId
+func (_m *AttachmentRepository) Delete(ctx context.Context, attachmentId string) error {
+	ret := _m.Called(ctx, attachmentId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, attachmentId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: ctx, attachmentId
+func (_m *AttachmentRepository) GetByID(ctx context.Context, attachmentId string) (*model.Attachment, error) {
+	ret := _m.Called(ctx, attachmentId)
+
+	var r0 *model.Attachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Attachment, error)); ok {
+		return rf(ctx, attachmentId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Attachment); ok {
+		r0 = rf(ctx, attachmentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Attachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, attachmentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingBefore provides a mock function with given fields: ctx, before, limit
+func (_m *AttachmentRepository) GetPendingBefore(ctx context.Context, before time.Time, limit int) ([]*model.Attachment, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	var r0 []*model.Attachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]*model.Attachment, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []*model.Attachment); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Attachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkAttached provides a mock function with given fields: ctx, attachmentId, messageId
+func (_m *AttachmentRepository) MarkAttached(ctx context.Context, attachmentId string, messageId string) error {
+	ret := _m.Called(ctx, attachmentId, messageId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, attachmentId, messageId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAttachmentRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAttachmentRepository creates a new instance of AttachmentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAttachmentRepository(t mockConstructorTestingTNewAttachmentRepository) *AttachmentRepository {
+	mock := &AttachmentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
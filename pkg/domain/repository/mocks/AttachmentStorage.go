@@ -0,0 +1,120 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AttachmentStorage is an autogenerated mock type for the AttachmentStorage type
+type AttachmentStorage struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, objectKey
+func (_m *AttachmentStorage) Delete(ctx context.Context, objectKey string) error {
+	ret := _m.Called(ctx, objectKey)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, objectKey)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PresignDownload provides a mock function with given fields: ctx, objectKey, fileName, expiry
+func (_m *AttachmentStorage) PresignDownload(ctx context.Context, objectKey string, fileName string, expiry time.Duration) (string, error) {
+	ret := _m.Called(ctx, objectKey, fileName, expiry)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) (string, error)); ok {
+		return rf(ctx, objectKey, fileName, expiry)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) string); ok {
+		r0 = rf(ctx, objectKey, fileName, expiry)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Duration) error); ok {
+		r1 = rf(ctx, objectKey, fileName, expiry)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PresignUpload provides a mock function with given fields: ctx, objectKey, contentType, size, expiry
+func (_m *AttachmentStorage) PresignUpload(ctx context.Context, objectKey string, contentType string, size int64, expiry time.Duration) (string, error) {
+	ret := _m.Called(ctx, objectKey, contentType, size, expiry)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, time.Duration) (string, error)); ok {
+		return rf(ctx, objectKey, contentType, size, expiry)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, time.Duration) string); ok {
+		r0 = rf(ctx, objectKey, contentType, size, expiry)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64, time.Duration) error); ok {
+		r1 = rf(ctx, objectKey, contentType, size, expiry)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Stat provides a mock function with given fields: ctx, objectKey
+func (_m *AttachmentStorage) Stat(ctx context.Context, objectKey string) (*model.ObjectInfo, error) {
+	ret := _m.Called(ctx, objectKey)
+
+	var r0 *model.ObjectInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.ObjectInfo, error)); ok {
+		return rf(ctx, objectKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.ObjectInfo); ok {
+		r0 = rf(ctx, objectKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ObjectInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, objectKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewAttachmentStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAttachmentStorage creates a new instance of AttachmentStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAttachmentStorage(t mockConstructorTestingTNewAttachmentStorage) *AttachmentStorage {
+	mock := &AttachmentStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -7,6 +7,8 @@ import (
 
 	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MessageRepository is an autogenerated mock type for the MessageRepository type
@@ -14,6 +16,58 @@ type MessageRepository struct {
 	mock.Mock
 }
 
+// AddReply provides a mock function with given fields: ctx, parent, repliedAt
+func (_m *MessageRepository) AddReply(ctx context.Context, parent *model.Message, repliedAt time.Time) (*model.Message, error) {
+	ret := _m.Called(ctx, parent, repliedAt)
+
+	var r0 *model.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Message, time.Time) (*model.Message, error)); ok {
+		return rf(ctx, parent, repliedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Message, time.Time) *model.Message); ok {
+		r0 = rf(ctx, parent, repliedAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Message, time.Time) error); ok {
+		r1 = rf(ctx, parent, repliedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountMessagesSince provides a mock function with given fields: ctx, userId, since, limit
+func (_m *MessageRepository) CountMessagesSince(ctx context.Context, userId string, since map[string]time.Time, limit int) (map[string]int, error) {
+	ret := _m.Called(ctx, userId, since, limit)
+
+	var r0 map[string]int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]time.Time, int) (map[string]int, error)); ok {
+		return rf(ctx, userId, since, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]time.Time, int) map[string]int); ok {
+		r0 = rf(ctx, userId, since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]time.Time, int) error); ok {
+		r1 = rf(ctx, userId, since, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, message
 func (_m *MessageRepository) Create(ctx context.Context, message *model.Message) error {
 	ret := _m.Called(ctx, message)
@@ -101,6 +155,79 @@ func (_m *MessageRepository) GetMessagesByRoomID(ctx context.Context, roomId str
 	return r0, r1, r2
 }
 
+// GetMessagesSince provides a mock function with given fields: ctx, roomId, since, limit
+func (_m *MessageRepository) GetMessagesSince(ctx context.Context, roomId string, since time.Time, limit int) ([]*model.Message, error) {
+	ret := _m.Called(ctx, roomId, since, limit)
+
+	var r0 []*model.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) ([]*model.Message, error)); ok {
+		return rf(ctx, roomId, since, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) []*model.Message); ok {
+		r0 = rf(ctx, roomId, since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, int) error); ok {
+		r1 = rf(ctx, roomId, since, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReplies provides a mock function with given fields: ctx, roomId, parentMessageId, lastEvaluatedKey, limit
+func (_m *MessageRepository) GetReplies(ctx context.Context, roomId string, parentMessageId string, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+	ret := _m.Called(ctx, roomId, parentMessageId, lastEvaluatedKey, limit)
+
+	var r0 []*model.Message
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int) ([]*model.Message, string, error)); ok {
+		return rf(ctx, roomId, parentMessageId, lastEvaluatedKey, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int) []*model.Message); ok {
+		r0 = rf(ctx, roomId, parentMessageId, lastEvaluatedKey, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, int) string); ok {
+		r1 = rf(ctx, roomId, parentMessageId, lastEvaluatedKey, limit)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, int) error); ok {
+		r2 = rf(ctx, roomId, parentMessageId, lastEvaluatedKey, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RemoveReply provides a mock function with given fields: ctx, parent
+func (_m *MessageRepository) RemoveReply(ctx context.Context, parent *model.Message) error {
+	ret := _m.Called(ctx, parent)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Message) error); ok {
+		r0 = rf(ctx, parent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, roomId, messageId, newContent
 func (_m *MessageRepository) Update(ctx context.Context, roomId string, messageId string, newContent string) error {
 	ret := _m.Called(ctx, roomId, messageId, newContent)
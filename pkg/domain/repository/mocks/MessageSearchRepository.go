@@ -0,0 +1,105 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MessageSearchRepository is an autogenerated mock type for the MessageSearchRepository type
+type MessageSearchRepository struct {
+	mock.Mock
+}
+
+// BulkIndex provides a mock function with given fields: ctx, messages
+func (_m *MessageSearchRepository) BulkIndex(ctx context.Context, messages []*model.Message) error {
+	ret := _m.Called(ctx, messages)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.Message) error); ok {
+		r0 = rf(ctx, messages)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, messageId
+func (_m *MessageSearchRepository) Delete(ctx context.Context, messageId string) error {
+	ret := _m.Called(ctx, messageId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, messageId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Index provides a mock function with given fields: ctx, message
+func (_m *MessageSearchRepository) Index(ctx context.Context, message *model.Message) error {
+	ret := _m.Called(ctx, message)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: ctx, query, roomIds, offset, limit
+func (_m *MessageSearchRepository) Search(ctx context.Context, query string, roomIds []string, offset int, limit int) ([]*model.MessageSearchHit, int, error) {
+	ret := _m.Called(ctx, query, roomIds, offset, limit)
+
+	var r0 []*model.MessageSearchHit
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int, int) ([]*model.MessageSearchHit, int, error)); ok {
+		return rf(ctx, query, roomIds, offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int, int) []*model.MessageSearchHit); ok {
+		r0 = rf(ctx, query, roomIds, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.MessageSearchHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, int, int) int); ok {
+		r1 = rf(ctx, query, roomIds, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, []string, int, int) error); ok {
+		r2 = rf(ctx, query, roomIds, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type mockConstructorTestingTNewMessageSearchRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewMessageSearchRepository creates a new instance of MessageSearchRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewMessageSearchRepository(t mockConstructorTestingTNewMessageSearchRepository) *MessageSearchRepository {
+	mock := &MessageSearchRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
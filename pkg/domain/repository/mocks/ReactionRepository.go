@@ -0,0 +1,132 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReactionRepository is an autogenerated mock type for the ReactionRepository type
+type ReactionRepository struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, reaction
+func (_m *ReactionRepository) Add(ctx context.Context, reaction *model.Reaction) (int, bool, error) {
+	ret := _m.Called(ctx, reaction)
+
+	var r0 int
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Reaction) (int, bool, error)); ok {
+		return rf(ctx, reaction)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Reaction) int); ok {
+		r0 = rf(ctx, reaction)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Reaction) bool); ok {
+		r1 = rf(ctx, reaction)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *model.Reaction) error); ok {
+		r2 = rf(ctx, reaction)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// DeleteByMessageID provides a mock function with given fields: ctx, messageId
+func (_m *ReactionRepository) DeleteByMessageID(ctx context.Context, messageId string) error {
+	ret := _m.Called(ctx, messageId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, messageId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCounts provides a mock function with given fields: ctx, messageIds
+func (_m *ReactionRepository) GetCounts(ctx context.Context, messageIds []string) (map[string][]*model.ReactionCount, error) {
+	ret := _m.Called(ctx, messageIds)
+
+	var r0 map[string][]*model.ReactionCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) (map[string][]*model.ReactionCount, error)); ok {
+		return rf(ctx, messageIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) map[string][]*model.ReactionCount); ok {
+		r0 = rf(ctx, messageIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]*model.ReactionCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, messageIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Remove provides a mock function with given fields: ctx, messageId, userId, emoji
+func (_m *ReactionRepository) Remove(ctx context.Context, messageId string, userId string, emoji string) (int, bool, error) {
+	ret := _m.Called(ctx, messageId, userId, emoji)
+
+	var r0 int
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (int, bool, error)); ok {
+		return rf(ctx, messageId, userId, emoji)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) int); ok {
+		r0 = rf(ctx, messageId, userId, emoji)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) bool); ok {
+		r1 = rf(ctx, messageId, userId, emoji)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string) error); ok {
+		r2 = rf(ctx, messageId, userId, emoji)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type mockConstructorTestingTNewReactionRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewReactionRepository creates a new instance of ReactionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewReactionRepository(t mockConstructorTestingTNewReactionRepository) *ReactionRepository {
+	mock := &ReactionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
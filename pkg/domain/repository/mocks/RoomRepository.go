@@ -14,6 +14,32 @@ type RoomRepository struct {
 	mock.Mock
 }
 
+// BatchGetByIDs provides a mock function with given fields: ctx, roomIds
+func (_m *RoomRepository) BatchGetByIDs(ctx context.Context, roomIds []string) ([]*model.Room, error) {
+	ret := _m.Called(ctx, roomIds)
+
+	var r0 []*model.Room
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*model.Room, error)); ok {
+		return rf(ctx, roomIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*model.Room); ok {
+		r0 = rf(ctx, roomIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Room)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, roomIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateAndAddUser provides a mock function with given fields: ctx, room, ownerId
 func (_m *RoomRepository) CreateAndAddUser(ctx context.Context, room *model.Room, ownerId string) error {
 	ret := _m.Called(ctx, room, ownerId)
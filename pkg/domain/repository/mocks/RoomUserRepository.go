@@ -7,6 +7,8 @@ import (
 
 	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // RoomUserRepository is an autogenerated mock type for the RoomUserRepository type
@@ -54,6 +56,32 @@ func (_m *RoomUserRepository) GetAllRoomsByUserID(ctx context.Context, userId st
 	return r0, r1
 }
 
+// GetByRoomIDAndUserID provides a mock function with given fields: ctx, roomId, userId
+func (_m *RoomUserRepository) GetByRoomIDAndUserID(ctx context.Context, roomId string, userId string) (*model.RoomUser, error) {
+	ret := _m.Called(ctx, roomId, userId)
+
+	var r0 *model.RoomUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.RoomUser, error)); ok {
+		return rf(ctx, roomId, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.RoomUser); ok {
+		r0 = rf(ctx, roomId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RoomUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, roomId, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUsersByRoomID provides a mock function with given fields: ctx, roomId, lastEvaluatedKey, limit
 func (_m *RoomUserRepository) GetUsersByRoomID(ctx context.Context, roomId string, lastEvaluatedKey string, limit int) ([]*model.RoomUser, string, error) {
 	ret := _m.Called(ctx, roomId, lastEvaluatedKey, limit)
@@ -101,6 +129,58 @@ func (_m *RoomUserRepository) RemoveUserFromRoom(ctx context.Context, roomId str
 	return r0
 }
 
+// TransferOwnership provides a mock function with given fields: ctx, roomId, currentOwnerId, newOwnerId
+func (_m *RoomUserRepository) TransferOwnership(ctx context.Context, roomId string, currentOwnerId string, newOwnerId string) error {
+	ret := _m.Called(ctx, roomId, currentOwnerId, newOwnerId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, roomId, currentOwnerId, newOwnerId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLastRead provides a mock function with given fields: ctx, roomId, userId, messageId, readAt
+func (_m *RoomUserRepository) UpdateLastRead(ctx context.Context, roomId string, userId string, messageId string, readAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, roomId, userId, messageId, readAt)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time) (bool, error)); ok {
+		return rf(ctx, roomId, userId, messageId, readAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time) bool); ok {
+		r0 = rf(ctx, roomId, userId, messageId, readAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, time.Time) error); ok {
+		r1 = rf(ctx, roomId, userId, messageId, readAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateRole provides a mock function with given fields: ctx, roomId, userId, role
+func (_m *RoomUserRepository) UpdateRole(ctx context.Context, roomId string, userId string, role model.RoomRole) error {
+	ret := _m.Called(ctx, roomId, userId, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.RoomRole) error); ok {
+		r0 = rf(ctx, roomId, userId, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewRoomUserRepository interface {
 	mock.TestingT
 	Cleanup(func())
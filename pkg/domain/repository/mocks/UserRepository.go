@@ -7,6 +7,8 @@ import (
 
 	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -113,6 +115,20 @@ func (_m *UserRepository) GetMultiple(ctx context.Context, lastEvaluatedKey stri
 	return r0, r1, r2
 }
 
+// UpdateLastSeen provides a mock function with given fields: ctx, userId, lastSeenAt
+func (_m *UserRepository) UpdateLastSeen(ctx context.Context, userId string, lastSeenAt time.Time) error {
+	ret := _m.Called(ctx, userId, lastSeenAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, userId, lastSeenAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewUserRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+)
+
+//go:generate mockery --name=ReactionRepository --output=mocks
+type ReactionRepository interface {
+	// Add stores the reaction and returns the new number of reactions with its emoji on the message.
+	// The bool is false when the user had already reacted with the emoji, in which case nothing changes.
+	Add(ctx context.Context, reaction *model.Reaction) (int, bool, error)
+	// Remove deletes the reaction of the user with the emoji and returns the remaining number of reactions
+	// with it. The bool is false when the user had not reacted with the emoji.
+	Remove(ctx context.Context, messageId, userId, emoji string) (int, bool, error)
+	// GetCounts returns the reaction counts of each message, keyed by message ID. Messages without reactions are left out.
+	GetCounts(ctx context.Context, messageIds []string) (map[string][]*model.ReactionCount, error)
+	// DeleteByMessageID deletes every reaction to the message, along with its counts.
+	DeleteByMessageID(ctx context.Context, messageId string) error
+}
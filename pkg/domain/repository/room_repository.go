@@ -9,6 +9,8 @@ import (
 //go:generate mockery --name=RoomRepository --output=mocks
 type RoomRepository interface {
 	GetByID(ctx context.Context, roomId string) (*model.Room, error)
+	// BatchGetByIDs returns the rooms with the IDs in no particular order, leaving out the rooms that do not exist.
+	BatchGetByIDs(ctx context.Context, roomIds []string) ([]*model.Room, error)
 	GetByName(ctx context.Context, name string) (*model.Room, error)
 	GetAllPublic(ctx context.Context) ([]*model.Room, error)
 	CreateAndAddUser(ctx context.Context, room *model.Room, ownerId string) error
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 )
@@ -9,7 +10,13 @@ import (
 //go:generate mockery --name=RoomUserRepository --output=mocks
 type RoomUserRepository interface {
 	GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.RoomUser, error)
+	GetByRoomIDAndUserID(ctx context.Context, roomId, userId string) (*model.RoomUser, error)
 	GetUsersByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.RoomUser, string, error)
 	RemoveUserFromRoom(ctx context.Context, roomId, userId string) error
 	AddUsersToRoom(ctx context.Context, roomId string, userIDs []string) error
+	UpdateRole(ctx context.Context, roomId, userId string, role model.RoomRole) error
+	// UpdateLastRead moves the read marker of the user forward to the message. It returns false without
+	// changing anything when the marker is already at or past readAt.
+	UpdateLastRead(ctx context.Context, roomId, userId, messageId string, readAt time.Time) (bool, error)
+	TransferOwnership(ctx context.Context, roomId, currentOwnerId, newOwnerId string) error
 }
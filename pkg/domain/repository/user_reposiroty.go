@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 )
@@ -12,4 +13,6 @@ type UserRepository interface {
 	GetMultiple(ctx context.Context, lastEvaluatedKey string, limit int) ([]*model.User, string, error)
 	GetByID(ctx context.Context, userId string) (*model.User, error)
 	BatchGetUsers(ctx context.Context, userIds []string) ([]*model.User, error)
+	// UpdateLastSeen records when the user was last seen. It fails with a NotFoundErr when the user does not exist.
+	UpdateLastSeen(ctx context.Context, userId string, lastSeenAt time.Time) error
 }
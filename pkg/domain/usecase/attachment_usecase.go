@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+)
+
+//go:generate mockery --name=AttachmentUsecase --output=mocks
+type AttachmentUsecase interface {
+	// CreateUpload declares an attachment of the caller in the room and returns the URL its file is uploaded to.
+	CreateUpload(ctx context.Context, roomId, name, contentType string, size int64) (*model.AttachmentUpload, error)
+	// GetDownload returns a URL the file of an attachment in the room is downloaded from. Uploads that are not
+	// attached to a message yet can only be downloaded by their uploader.
+	GetDownload(ctx context.Context, roomId, attachmentId string) (*model.AttachmentDownload, error)
+	// PrepareAttachments replaces the attachments of the message, which only carry their IDs, with the uploaded files
+	// as they are once attached. Nothing is linked yet. The message must have its ID, room and sender set.
+	PrepareAttachments(ctx context.Context, message *model.Message) error
+	// AttachToMessage links the attachments prepared by PrepareAttachments to the message, once it is stored.
+	AttachToMessage(ctx context.Context, message *model.Message) error
+	// DeleteForMessage deletes the attachments linked to the message along with their files.
+	DeleteForMessage(ctx context.Context, message *model.Message) error
+	// CleanupOrphaned deletes the uploads that were never attached to a message and returns how many were deleted.
+	CleanupOrphaned(ctx context.Context) (int, error)
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+)
+
+//go:generate mockery --name=AuthorizationUsecase --output=mocks
+type AuthorizationUsecase interface {
+	AuthorizeRoomMember(ctx context.Context, roomId, userId string) (*model.RoomUser, error)
+	AuthorizeRoomRole(ctx context.Context, roomId, userId string, roles ...model.RoomRole) (*model.RoomUser, error)
+}
@@ -8,8 +8,22 @@ import (
 
 //go:generate mockery --name=MessageUsecase --output=mocks
 type MessageUsecase interface {
+	// GetMessagesByRoomID pages through the timeline of the room, newest first. Replies are read through GetReplies.
 	GetMessagesByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error)
+	GetReplies(ctx context.Context, roomId, messageId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error)
+	// GetMessagesSince returns up to limit of the latest messages after the cursor, which is either an RFC 3339
+	// timestamp or a message ID, oldest first. The bool reports whether older messages after the cursor were left out.
+	// Messages of a room are created at distinct times, so the cursor of a message skips that message only.
+	GetMessagesSince(ctx context.Context, roomId, since string, limit int) ([]*model.Message, bool, error)
+	// CreateMessage stores the message, as a reply to the thread of ParentMessageID when it is set.
+	// The attachments of the message only need their IDs; they are filled in from the uploads.
 	CreateMessage(ctx context.Context, message *model.Message) error
+	// UpdateMessage fails with a ForbiddenErr unless the caller wrote the message.
 	UpdateMessage(ctx context.Context, roomId, messageId, newContent string) error
+	// DeleteMessage fails with a ForbiddenErr unless the caller wrote the message or is the owner or an admin of the room.
 	DeleteMessage(ctx context.Context, roomId, messageId string) error
+	MarkAsRead(ctx context.Context, roomId, messageId string) error
+	// SearchMessages finds messages matching the query in the room, or in every room of the caller when roomId is empty.
+	// It returns a page of the hits along with the total number of them.
+	SearchMessages(ctx context.Context, query, roomId string, offset, limit int) ([]*model.MessageSearchHit, int, error)
 }
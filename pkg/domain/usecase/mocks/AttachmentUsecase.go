@@ -0,0 +1,148 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AttachmentUsecase is an autogenerated mock type for the AttachmentUsecase type
+type AttachmentUsecase struct {
+	mock.Mock
+}
+
+// AttachToMessage provides a mock function with given fields: ctx, message
+func (_m *AttachmentUsecase) AttachToMessage(ctx context.Context, message *model.Message) error {
+	ret := _m.Called(ctx, message)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CleanupOrphaned provides a mock function with given fields: ctx
+func (_m *AttachmentUsecase) CleanupOrphaned(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUpload provides a mock function with given fields: ctx, roomId, name, contentType, size
+func (_m *AttachmentUsecase) CreateUpload(ctx context.Context, roomId string, name string, contentType string, size int64) (*model.AttachmentUpload, error) {
+	ret := _m.Called(ctx, roomId, name, contentType, size)
+
+	var r0 *model.AttachmentUpload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int64) (*model.AttachmentUpload, error)); ok {
+		return rf(ctx, roomId, name, contentType, size)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int64) *model.AttachmentUpload); ok {
+		r0 = rf(ctx, roomId, name, contentType, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AttachmentUpload)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, int64) error); ok {
+		r1 = rf(ctx, roomId, name, contentType, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteForMessage provides a mock function with given fields: ctx, message
+func (_m *AttachmentUsecase) DeleteForMessage(ctx context.Context, message *model.Message) error {
+	ret := _m.Called(ctx, message)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDownload provides a mock function with given fields: ctx, roomId, attachmentId
+func (_m *AttachmentUsecase) GetDownload(ctx context.Context, roomId string, attachmentId string) (*model.AttachmentDownload, error) {
+	ret := _m.Called(ctx, roomId, attachmentId)
+
+	var r0 *model.AttachmentDownload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.AttachmentDownload, error)); ok {
+		return rf(ctx, roomId, attachmentId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.AttachmentDownload); ok {
+		r0 = rf(ctx, roomId, attachmentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AttachmentDownload)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, roomId, attachmentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PrepareAttachments provides a mock function with given fields: ctx, message
+func (_m *AttachmentUsecase) PrepareAttachments(ctx context.Context, message *model.Message) error {
+	ret := _m.Called(ctx, message)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAttachmentUsecase interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAttachmentUsecase creates a new instance of AttachmentUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAttachmentUsecase(t mockConstructorTestingTNewAttachmentUsecase) *AttachmentUsecase {
+	mock := &AttachmentUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
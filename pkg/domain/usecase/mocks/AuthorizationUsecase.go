@@ -0,0 +1,89 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuthorizationUsecase is an autogenerated mock type for the AuthorizationUsecase type
+type AuthorizationUsecase struct {
+	mock.Mock
+}
+
+// AuthorizeRoomMember provides a mock function with given fields: ctx, roomId, userId
+func (_m *AuthorizationUsecase) AuthorizeRoomMember(ctx context.Context, roomId string, userId string) (*model.RoomUser, error) {
+	ret := _m.Called(ctx, roomId, userId)
+
+	var r0 *model.RoomUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.RoomUser, error)); ok {
+		return rf(ctx, roomId, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.RoomUser); ok {
+		r0 = rf(ctx, roomId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RoomUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, roomId, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthorizeRoomRole provides a mock function with given fields: ctx, roomId, userId, roles
+func (_m *AuthorizationUsecase) AuthorizeRoomRole(ctx context.Context, roomId string, userId string, roles ...model.RoomRole) (*model.RoomUser, error) {
+	_va := make([]interface{}, len(roles))
+	for _i := range roles {
+		_va[_i] = roles[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, roomId, userId)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *model.RoomUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ...model.RoomRole) (*model.RoomUser, error)); ok {
+		return rf(ctx, roomId, userId, roles...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ...model.RoomRole) *model.RoomUser); ok {
+		r0 = rf(ctx, roomId, userId, roles...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RoomUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, ...model.RoomRole) error); ok {
+		r1 = rf(ctx, roomId, userId, roles...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewAuthorizationUsecase interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAuthorizationUsecase creates a new instance of AuthorizationUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAuthorizationUsecase(t mockConstructorTestingTNewAuthorizationUsecase) *AuthorizationUsecase {
+	mock := &AuthorizationUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
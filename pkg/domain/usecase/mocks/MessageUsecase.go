@@ -75,6 +75,119 @@ func (_m *MessageUsecase) GetMessagesByRoomID(ctx context.Context, roomId string
 	return r0, r1, r2
 }
 
+// GetMessagesSince provides a mock function with given fields: ctx, roomId, since, limit
+func (_m *MessageUsecase) GetMessagesSince(ctx context.Context, roomId string, since string, limit int) ([]*model.Message, bool, error) {
+	ret := _m.Called(ctx, roomId, since, limit)
+
+	var r0 []*model.Message
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]*model.Message, bool, error)); ok {
+		return rf(ctx, roomId, since, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []*model.Message); ok {
+		r0 = rf(ctx, roomId, since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) bool); ok {
+		r1 = rf(ctx, roomId, since, limit)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, int) error); ok {
+		r2 = rf(ctx, roomId, since, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetReplies provides a mock function with given fields: ctx, roomId, messageId, lastEvaluatedKey, limit
+func (_m *MessageUsecase) GetReplies(ctx context.Context, roomId string, messageId string, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+	ret := _m.Called(ctx, roomId, messageId, lastEvaluatedKey, limit)
+
+	var r0 []*model.Message
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int) ([]*model.Message, string, error)); ok {
+		return rf(ctx, roomId, messageId, lastEvaluatedKey, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int) []*model.Message); ok {
+		r0 = rf(ctx, roomId, messageId, lastEvaluatedKey, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, int) string); ok {
+		r1 = rf(ctx, roomId, messageId, lastEvaluatedKey, limit)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, int) error); ok {
+		r2 = rf(ctx, roomId, messageId, lastEvaluatedKey, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MarkAsRead provides a mock function with given fields: ctx, roomId, messageId
+func (_m *MessageUsecase) MarkAsRead(ctx context.Context, roomId string, messageId string) error {
+	ret := _m.Called(ctx, roomId, messageId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, roomId, messageId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchMessages provides a mock function with given fields: ctx, query, roomId, offset, limit
+func (_m *MessageUsecase) SearchMessages(ctx context.Context, query string, roomId string, offset int, limit int) ([]*model.MessageSearchHit, int, error) {
+	ret := _m.Called(ctx, query, roomId, offset, limit)
+
+	var r0 []*model.MessageSearchHit
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, int) ([]*model.MessageSearchHit, int, error)); ok {
+		return rf(ctx, query, roomId, offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, int) []*model.MessageSearchHit); ok {
+		r0 = rf(ctx, query, roomId, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.MessageSearchHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int, int) int); ok {
+		r1 = rf(ctx, query, roomId, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, int, int) error); ok {
+		r2 = rf(ctx, query, roomId, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // UpdateMessage provides a mock function with given fields: ctx, roomId, messageId, newContent
 func (_m *MessageUsecase) UpdateMessage(ctx context.Context, roomId string, messageId string, newContent string) error {
 	ret := _m.Called(ctx, roomId, messageId, newContent)
@@ -0,0 +1,97 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PresenceUsecase is an autogenerated mock type for the PresenceUsecase type
+type PresenceUsecase struct {
+	mock.Mock
+}
+
+// Connect provides a mock function with given fields: client
+func (_m *PresenceUsecase) Connect(client *model.Client) {
+	_m.Called(client)
+}
+
+// Disconnect provides a mock function with given fields: client
+func (_m *PresenceUsecase) Disconnect(client *model.Client) {
+	_m.Called(client)
+}
+
+// GetRoomPresence provides a mock function with given fields: ctx, roomId
+func (_m *PresenceUsecase) GetRoomPresence(ctx context.Context, roomId string) ([]*model.Presence, error) {
+	ret := _m.Called(ctx, roomId)
+
+	var r0 []*model.Presence
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.Presence, error)); ok {
+		return rf(ctx, roomId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.Presence); ok {
+		r0 = rf(ctx, roomId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Presence)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, roomId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserPresence provides a mock function with given fields: ctx, userId
+func (_m *PresenceUsecase) GetUserPresence(ctx context.Context, userId string) (*model.Presence, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 *model.Presence
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Presence, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Presence); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Presence)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetStatus provides a mock function with given fields: client, status
+func (_m *PresenceUsecase) SetStatus(client *model.Client, status model.PresenceStatus) {
+	_m.Called(client, status)
+}
+
+type mockConstructorTestingTNewPresenceUsecase interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPresenceUsecase creates a new instance of PresenceUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPresenceUsecase(t mockConstructorTestingTNewPresenceUsecase) *PresenceUsecase {
+	mock := &PresenceUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,57 @@
+// Code generated by mockery v2.20.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReactionUsecase is an autogenerated mock type for the ReactionUsecase type
+type ReactionUsecase struct {
+	mock.Mock
+}
+
+// AddReaction provides a mock function with given fields: ctx, roomId, messageId, emoji
+func (_m *ReactionUsecase) AddReaction(ctx context.Context, roomId string, messageId string, emoji string) error {
+	ret := _m.Called(ctx, roomId, messageId, emoji)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, roomId, messageId, emoji)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveReaction provides a mock function with given fields: ctx, roomId, messageId, emoji
+func (_m *ReactionUsecase) RemoveReaction(ctx context.Context, roomId string, messageId string, emoji string) error {
+	ret := _m.Called(ctx, roomId, messageId, emoji)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, roomId, messageId, emoji)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewReactionUsecase interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewReactionUsecase creates a new instance of ReactionUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewReactionUsecase(t mockConstructorTestingTNewReactionUsecase) *ReactionUsecase {
+	mock := &ReactionUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -29,19 +29,19 @@ func (_m *RoomUserUsecase) AddUsersToRoom(ctx context.Context, roomId string, us
 }
 
 // GetAllRoomsByUserID provides a mock function with given fields: ctx, userId
-func (_m *RoomUserUsecase) GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.Room, error) {
+func (_m *RoomUserUsecase) GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.UserRoom, error) {
 	ret := _m.Called(ctx, userId)
 
-	var r0 []*model.Room
+	var r0 []*model.UserRoom
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.Room, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.UserRoom, error)); ok {
 		return rf(ctx, userId)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.Room); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.UserRoom); ok {
 		r0 = rf(ctx, userId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*model.Room)
+			r0 = ret.Get(0).([]*model.UserRoom)
 		}
 	}
 
@@ -101,6 +101,34 @@ func (_m *RoomUserUsecase) RemoveUserFromRoom(ctx context.Context, roomId string
 	return r0
 }
 
+// TransferOwnership provides a mock function with given fields: ctx, roomId, newOwnerId
+func (_m *RoomUserUsecase) TransferOwnership(ctx context.Context, roomId string, newOwnerId string) error {
+	ret := _m.Called(ctx, roomId, newOwnerId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, roomId, newOwnerId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateUserRole provides a mock function with given fields: ctx, roomId, userId, role
+func (_m *RoomUserUsecase) UpdateUserRole(ctx context.Context, roomId string, userId string, role model.RoomRole) error {
+	ret := _m.Called(ctx, roomId, userId, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.RoomRole) error); ok {
+		r0 = rf(ctx, roomId, userId, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewRoomUserUsecase interface {
 	mock.TestingT
 	Cleanup(func())
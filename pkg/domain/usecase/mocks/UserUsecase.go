@@ -40,13 +40,13 @@ func (_m *UserUsecase) BatchGetUsers(ctx context.Context, userIds []string) ([]*
 	return r0, r1
 }
 
-// CreateUser provides a mock function with given fields: ctx, user, idToken
-func (_m *UserUsecase) CreateUser(ctx context.Context, user *model.User, idToken string) error {
-	ret := _m.Called(ctx, user, idToken)
+// CreateUser provides a mock function with given fields: ctx, user
+func (_m *UserUsecase) CreateUser(ctx context.Context, user *model.User) error {
+	ret := _m.Called(ctx, user)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *model.User, string) error); ok {
-		r0 = rf(ctx, user, idToken)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.User) error); ok {
+		r0 = rf(ctx, user)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+)
+
+// PresenceUsecase tracks the WebSocket connections of users and reports their presence.
+//
+//go:generate mockery --name=PresenceUsecase --output=mocks
+type PresenceUsecase interface {
+	model.PresenceTracker
+	GetRoomPresence(ctx context.Context, roomId string) ([]*model.Presence, error)
+	GetUserPresence(ctx context.Context, userId string) (*model.Presence, error)
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+)
+
+//go:generate mockery --name=ReactionUsecase --output=mocks
+type ReactionUsecase interface {
+	AddReaction(ctx context.Context, roomId, messageId, emoji string) error
+	RemoveReaction(ctx context.Context, roomId, messageId, emoji string) error
+}
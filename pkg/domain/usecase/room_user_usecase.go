@@ -8,8 +8,11 @@ import (
 
 //go:generate mockery --name=RoomUserUsecase --output=mocks
 type RoomUserUsecase interface {
-	GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.Room, error)
+	// GetAllRoomsByUserID returns the rooms of the user with the number of messages they have not read in each.
+	GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.UserRoom, error)
 	GetUsersByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.User, string, error)
 	RemoveUserFromRoom(ctx context.Context, roomId, userId string) error
 	AddUsersToRoom(ctx context.Context, roomId string, userIds []string) error
+	UpdateUserRole(ctx context.Context, roomId, userId string, role model.RoomRole) error
+	TransferOwnership(ctx context.Context, roomId, newOwnerId string) error
 }
@@ -8,7 +8,8 @@ import (
 
 //go:generate mockery --name=UserUsecase --output=mocks
 type UserUsecase interface {
-	CreateUser(ctx context.Context, user *model.User, idToken string) error
+	// CreateUser creates or updates the profile of user, whose ID must be that of the authenticated caller.
+	CreateUser(ctx context.Context, user *model.User) error
 	GetMultipleUsers(ctx context.Context, lastEvaluatedKey string, limit int) ([]*model.User, string, error)
 	GetUserByID(ctx context.Context, userId string) (*model.User, error)
 	BatchGetUsers(ctx context.Context, userIds []string) ([]*model.User, error)
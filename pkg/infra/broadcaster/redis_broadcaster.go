@@ -0,0 +1,126 @@
+package broadcaster
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+)
+
+const channelPrefix = "chat:"
+
+// envelope carries an event between instances. Seq increases per origin instance and topic,
+// so subscribers can drop events they have already delivered.
+type envelope struct {
+	Origin string          `json:"origin"`
+	Seq    uint64          `json:"seq"`
+	Event  *model.RawEvent `json:"event"`
+}
+
+type topicSequence struct {
+	mu  sync.Mutex
+	seq uint64
+}
+
+type RedisBroadcaster struct {
+	client     *redis.Client
+	instanceID string
+	sequences  map[string]*topicSequence
+	mu         sync.Mutex
+}
+
+func NewRedisBroadcaster(client *redis.Client) model.Broadcaster {
+	return &RedisBroadcaster{
+		client:     client,
+		instanceID: uuid.New().String(),
+		sequences:  make(map[string]*topicSequence),
+	}
+}
+
+func (rb *RedisBroadcaster) Publish(ctx context.Context, topic string, event model.Event) error {
+	rawEvent, err := model.EncodeEvent(event)
+	if err != nil {
+		return err
+	}
+
+	sequence := rb.sequence(topic)
+
+	// The lock is held until Redis accepts the event so that sequence numbers reach subscribers in order.
+	sequence.mu.Lock()
+	defer sequence.mu.Unlock()
+
+	payload, err := json.Marshal(&envelope{
+		Origin: rb.instanceID,
+		Seq:    sequence.seq + 1,
+		Event:  rawEvent,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := rb.client.Publish(ctx, channelPrefix+topic, payload).Err(); err != nil {
+		return err
+	}
+
+	sequence.seq++
+	return nil
+}
+
+func (rb *RedisBroadcaster) Subscribe(ctx context.Context, topic string, handler func(model.Event)) (func(), error) {
+	pubsub := rb.client.Subscribe(ctx, channelPrefix+topic)
+
+	// Wait for the subscription to be confirmed so that no event published after Subscribe returns is missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	go listen(pubsub.Channel(), handler)
+
+	return func() {
+		if err := pubsub.Close(); err != nil {
+			log.Printf("Failed to unsubscribe from %s: %v", topic, err)
+		}
+	}, nil
+}
+
+func (rb *RedisBroadcaster) sequence(topic string) *topicSequence {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	sequence, ok := rb.sequences[topic]
+	if !ok {
+		sequence = &topicSequence{}
+		rb.sequences[topic] = sequence
+	}
+	return sequence
+}
+
+func listen(messages <-chan *redis.Message, handler func(model.Event)) {
+	lastSeqs := make(map[string]uint64)
+
+	for message := range messages {
+		var env envelope
+		if err := json.Unmarshal([]byte(message.Payload), &env); err != nil {
+			log.Printf("Failed to unmarshal envelope: %v", err)
+			continue
+		}
+
+		if env.Seq <= lastSeqs[env.Origin] {
+			continue
+		}
+		lastSeqs[env.Origin] = env.Seq
+
+		event, err := model.DecodeEvent(env.Event)
+		if err != nil {
+			log.Printf("Failed to decode event: %v", err)
+			continue
+		}
+
+		handler(event)
+	}
+}
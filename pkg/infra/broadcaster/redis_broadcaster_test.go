@@ -0,0 +1,106 @@
+package broadcaster
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, server *miniredis.Miniredis) *redis.Client {
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return client
+}
+
+func subscribe(t *testing.T, broadcaster model.Broadcaster, topic string) (<-chan model.Event, func()) {
+	events := make(chan model.Event, 10)
+	unsubscribe, err := broadcaster.Subscribe(context.Background(), topic, func(event model.Event) {
+		events <- event
+	})
+	assert.NoError(t, err)
+	return events, unsubscribe
+}
+
+func receive(t *testing.T, events <-chan model.Event) model.Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func assertNoEvent(t *testing.T, events <-chan model.Event) {
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event: %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRedisBroadcaster_PublishAcrossInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	instanceA := NewRedisBroadcaster(newTestClient(t, server))
+	instanceB := NewRedisBroadcaster(newTestClient(t, server))
+
+	eventsA, _ := subscribe(t, instanceA, "room:1")
+	eventsB, _ := subscribe(t, instanceB, "room:1")
+	otherRoomEvents, _ := subscribe(t, instanceB, "room:2")
+
+	for _, content := range []string{"1", "2", "3"} {
+		err := instanceA.Publish(context.Background(), "room:1", &model.Message{RoomID: "1", Content: content})
+		assert.NoError(t, err)
+	}
+	err := instanceB.Publish(context.Background(), "room:1", &model.MessageDeletedDetails{RoomID: "1", MessageID: "1"})
+	assert.NoError(t, err)
+
+	for _, events := range []<-chan model.Event{eventsA, eventsB} {
+		for _, content := range []string{"1", "2", "3"} {
+			event := receive(t, events)
+			assert.Equal(t, &model.Message{RoomID: "1", Content: content}, event)
+		}
+		assert.Equal(t, &model.MessageDeletedDetails{RoomID: "1", MessageID: "1"}, receive(t, events))
+	}
+	assertNoEvent(t, otherRoomEvents)
+}
+
+func TestRedisBroadcaster_DropsDuplicates(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := newTestClient(t, server)
+	broadcaster := NewRedisBroadcaster(client)
+
+	events, _ := subscribe(t, broadcaster, "room:1")
+
+	rawEvent, err := model.EncodeEvent(&model.Message{RoomID: "1", Content: "Hello"})
+	assert.NoError(t, err)
+	payload, err := json.Marshal(&envelope{Origin: "other", Seq: 1, Event: rawEvent})
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, client.Publish(context.Background(), channelPrefix+"room:1", payload).Err())
+	}
+
+	assert.Equal(t, &model.Message{RoomID: "1", Content: "Hello"}, receive(t, events))
+	assertNoEvent(t, events)
+}
+
+func TestRedisBroadcaster_Unsubscribe(t *testing.T) {
+	server := miniredis.RunT(t)
+	broadcaster := NewRedisBroadcaster(newTestClient(t, server))
+
+	events, unsubscribe := subscribe(t, broadcaster, "room:1")
+	unsubscribe()
+
+	err := broadcaster.Publish(context.Background(), "room:1", &model.Message{RoomID: "1", Content: "Hello"})
+	assert.NoError(t, err)
+	assertNoEvent(t, events)
+}
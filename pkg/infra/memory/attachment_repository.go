@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type AttachmentRepository struct {
+	store *Store
+}
+
+func NewAttachmentRepository(store *Store) repository.AttachmentRepository {
+	return &AttachmentRepository{
+		store,
+	}
+}
+
+func (ar *AttachmentRepository) Create(ctx context.Context, attachment *model.Attachment) error {
+	ar.store.mu.Lock()
+	defer ar.store.mu.Unlock()
+
+	copied := *attachment
+	ar.store.attachments[attachment.AttachmentID] = &copied
+	return nil
+}
+
+func (ar *AttachmentRepository) GetByID(ctx context.Context, attachmentId string) (*model.Attachment, error) {
+	ar.store.mu.RLock()
+	defer ar.store.mu.RUnlock()
+
+	attachment, ok := ar.store.attachments[attachmentId]
+	if !ok {
+		return nil, apperror.NewNotFoundErr("Attachment", "AttachmentID: "+attachmentId)
+	}
+
+	copied := *attachment
+	return &copied, nil
+}
+
+func (ar *AttachmentRepository) MarkAttached(ctx context.Context, attachmentId, messageId string) error {
+	ar.store.mu.Lock()
+	defer ar.store.mu.Unlock()
+
+	attachment, ok := ar.store.attachments[attachmentId]
+	if !ok || attachment.Status != model.Pending {
+		return apperror.NewNotFoundErr("Attachment", "pending AttachmentID: "+attachmentId)
+	}
+	attachment.MessageID = messageId
+	attachment.Status = model.Attached
+
+	return nil
+}
+
+// GetPendingBefore returns the oldest pending attachments first, in the order of the StatusIndex.
+func (ar *AttachmentRepository) GetPendingBefore(ctx context.Context, before time.Time, limit int) ([]*model.Attachment, error) {
+	if limit < 1 {
+		return nil, errInvalidLimit
+	}
+
+	ar.store.mu.RLock()
+	defer ar.store.mu.RUnlock()
+
+	beforeKey := timeKey(before)
+
+	var attachments []*model.Attachment
+	for _, attachment := range ar.store.attachments {
+		if attachment.Status == model.Pending && timeKey(attachment.CreatedAt) < beforeKey {
+			copied := *attachment
+			attachments = append(attachments, &copied)
+		}
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return timeKey(attachments[i].CreatedAt) < timeKey(attachments[j].CreatedAt)
+	})
+	if len(attachments) > limit {
+		attachments = attachments[:limit]
+	}
+
+	return attachments, nil
+}
+
+func (ar *AttachmentRepository) Delete(ctx context.Context, attachmentId string) error {
+	ar.store.mu.Lock()
+	defer ar.store.mu.Unlock()
+
+	delete(ar.store.attachments, attachmentId)
+	return nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+// AttachmentStorage only keeps what is known about uploaded files. Nothing can be uploaded through its URLs,
+// so uploads are simulated with Put.
+type AttachmentStorage struct {
+	store *Store
+}
+
+func NewAttachmentStorage(store *Store) *AttachmentStorage {
+	return &AttachmentStorage{
+		store,
+	}
+}
+
+var _ repository.AttachmentStorage = (*AttachmentStorage)(nil)
+
+// Put records that a file was uploaded under the key.
+func (as *AttachmentStorage) Put(objectKey string, object *model.ObjectInfo) {
+	as.store.mu.Lock()
+	defer as.store.mu.Unlock()
+
+	copied := *object
+	as.store.objects[objectKey] = &copied
+}
+
+func (as *AttachmentStorage) PresignUpload(ctx context.Context, objectKey, contentType string, size int64, expiry time.Duration) (string, error) {
+	return "memory:///" + url.PathEscape(objectKey) + "?method=PUT", nil
+}
+
+func (as *AttachmentStorage) PresignDownload(ctx context.Context, objectKey, fileName string, expiry time.Duration) (string, error) {
+	return "memory:///" + url.PathEscape(objectKey), nil
+}
+
+func (as *AttachmentStorage) Stat(ctx context.Context, objectKey string) (*model.ObjectInfo, error) {
+	as.store.mu.RLock()
+	defer as.store.mu.RUnlock()
+
+	object, ok := as.store.objects[objectKey]
+	if !ok {
+		return nil, apperror.NewNotFoundErr("Object", "Key: "+objectKey)
+	}
+
+	copied := *object
+	return &copied, nil
+}
+
+func (as *AttachmentStorage) Delete(ctx context.Context, objectKey string) error {
+	as.store.mu.Lock()
+	defer as.store.mu.Unlock()
+
+	delete(as.store.objects, objectKey)
+	return nil
+}
@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/shunsukenagashima/chat-api/pkg/infra/repositorytest"
+)
+
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		store := NewStore()
+		return repositorytest.Repositories{
+			Room:     NewRoomRepository(store),
+			RoomUser: NewRoomUserRepository(store),
+			User:     NewUserRepository(store),
+			Message:  NewMessageRepository(store),
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type MessageRepository struct {
+	store *Store
+}
+
+func NewMessageRepository(store *Store) repository.MessageRepository {
+	return &MessageRepository{
+		store,
+	}
+}
+
+// copyMessage copies what the Messages table stores of a message; reactions are kept apart.
+func copyMessage(message *model.Message) *model.Message {
+	copied := *message
+	copied.Reactions = nil
+	if message.LastReplyAt != nil {
+		lastReplyAt := *message.LastReplyAt
+		copied.LastReplyAt = &lastReplyAt
+	}
+	if message.Attachments != nil {
+		copied.Attachments = make([]*model.Attachment, len(message.Attachments))
+		for i, attachment := range message.Attachments {
+			copiedAttachment := *attachment
+			copied.Attachments[i] = &copiedAttachment
+		}
+	}
+	return &copied
+}
+
+// GetMessagesByRoomID pages through the room newest first; the key is the creation time of the last message looked at.
+// Replies count towards the limit before they are left out, so a page may hold fewer messages than the limit.
+func (mr *MessageRepository) GetMessagesByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+	if limit < 1 {
+		return nil, "", errInvalidLimit
+	}
+
+	mr.store.mu.RLock()
+	defer mr.store.mu.RUnlock()
+
+	room := mr.store.messages[roomId]
+	keys, nextKey := page(sortedKeys(room, true), lastEvaluatedKey, limit, true)
+
+	messages := []*model.Message{}
+	for _, key := range keys {
+		if room[key].ParentMessageID == "" {
+			messages = append(messages, copyMessage(room[key]))
+		}
+	}
+
+	return messages, nextKey, nil
+}
+
+// GetReplies pages through the replies oldest first; the key is the creation time of the last reply.
+func (mr *MessageRepository) GetReplies(ctx context.Context, roomId, parentMessageId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+	if limit < 1 {
+		return nil, "", errInvalidLimit
+	}
+
+	mr.store.mu.RLock()
+	defer mr.store.mu.RUnlock()
+
+	replies := make(map[string]*model.Message)
+	for key, message := range mr.store.messages[roomId] {
+		if message.ParentMessageID == parentMessageId {
+			replies[key] = message
+		}
+	}
+	keys, nextKey := page(sortedKeys(replies, false), lastEvaluatedKey, limit, false)
+
+	messages := make([]*model.Message, len(keys))
+	for i, key := range keys {
+		messages[i] = copyMessage(replies[key])
+	}
+
+	return messages, nextKey, nil
+}
+
+func (mr *MessageRepository) GetMessagesSince(ctx context.Context, roomId string, since time.Time, limit int) ([]*model.Message, error) {
+	if limit < 1 {
+		return nil, errInvalidLimit
+	}
+
+	mr.store.mu.RLock()
+	defer mr.store.mu.RUnlock()
+
+	room := mr.store.messages[roomId]
+	sinceKey := timeKey(since)
+
+	var messages []*model.Message
+	for _, key := range sortedKeys(room, true) {
+		if key <= sinceKey || len(messages) == limit {
+			break
+		}
+		if room[key].ParentMessageID != "" {
+			continue
+		}
+		messages = append(messages, copyMessage(room[key]))
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+func (mr *MessageRepository) CountMessagesSince(ctx context.Context, userId string, since map[string]time.Time, limit int) (map[string]int, error) {
+	mr.store.mu.RLock()
+	defer mr.store.mu.RUnlock()
+
+	counts := make(map[string]int, len(since))
+	for roomId, roomSince := range since {
+		sinceKey := timeKey(roomSince)
+
+		count := 0
+		for key, message := range mr.store.messages[roomId] {
+			if key > sinceKey && message.UserID != userId {
+				count++
+			}
+		}
+		if count > limit {
+			count = limit
+		}
+		counts[roomId] = count
+	}
+
+	return counts, nil
+}
+
+func (mr *MessageRepository) GetByID(ctx context.Context, roomId, messageId string) (*model.Message, error) {
+	mr.store.mu.RLock()
+	defer mr.store.mu.RUnlock()
+
+	message, ok := mr.store.findMessage(roomId, messageId)
+	if !ok {
+		return nil, apperror.NewNotFoundErr("Message", "MessageID: "+messageId)
+	}
+
+	return copyMessage(message), nil
+}
+
+func (s *Store) findMessage(roomId, messageId string) (*model.Message, bool) {
+	for _, message := range s.messages[roomId] {
+		if message.MessageID == messageId {
+			return message, true
+		}
+	}
+	return nil, false
+}
+
+// Create refuses a message created at the same time as another of the room, which has the same primary key.
+func (mr *MessageRepository) Create(ctx context.Context, message *model.Message) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	room, ok := mr.store.messages[message.RoomID]
+	if !ok {
+		room = make(map[string]*model.Message)
+		mr.store.messages[message.RoomID] = room
+	}
+	key := timeKey(message.CreatedAt)
+	if _, exists := room[key]; exists {
+		return apperror.NewAlreadyExistsErr("Message", "RoomID: "+message.RoomID+", CreatedAt: "+key)
+	}
+	room[key] = copyMessage(message)
+
+	return nil
+}
+
+func (mr *MessageRepository) Update(ctx context.Context, roomId, messageId, newContent string) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	message, ok := mr.store.findMessage(roomId, messageId)
+	if !ok {
+		return apperror.NewNotFoundErr("Message", "MessageID: "+messageId)
+	}
+	message.Content = newContent
+
+	return nil
+}
+
+func (mr *MessageRepository) Delete(ctx context.Context, roomId, messageId string) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	message, ok := mr.store.findMessage(roomId, messageId)
+	if !ok {
+		return apperror.NewNotFoundErr("Message", "MessageID: "+messageId)
+	}
+	delete(mr.store.messages[roomId], timeKey(message.CreatedAt))
+
+	return nil
+}
+
+func (mr *MessageRepository) AddReply(ctx context.Context, parent *model.Message, repliedAt time.Time) (*model.Message, error) {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	stored, ok := mr.store.messages[parent.RoomID][timeKey(parent.CreatedAt)]
+	if !ok {
+		return nil, apperror.NewNotFoundErr("Message", "MessageID: "+parent.MessageID)
+	}
+	stored.ReplyCount++
+	stored.LastReplyAt = &repliedAt
+
+	return copyMessage(stored), nil
+}
+
+func (mr *MessageRepository) RemoveReply(ctx context.Context, parent *model.Message) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	// a parent deleted before its replies has nothing left to update
+	stored, ok := mr.store.messages[parent.RoomID][timeKey(parent.CreatedAt)]
+	if ok && stored.ReplyCount >= 1 {
+		stored.ReplyCount--
+	}
+
+	return nil
+}
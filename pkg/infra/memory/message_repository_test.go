@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageRepository_GetMessagesByRoomID(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := NewMessageRepository(NewStore())
+	for i, id := range []string{"m1", "m2", "reply", "m3"} {
+		message := &model.Message{
+			MessageID: id,
+			RoomID:    "room1",
+			CreatedAt: createdAt.Add(time.Duration(i) * time.Minute),
+		}
+		if id == "reply" {
+			message.ParentMessageID = "m2"
+		}
+		require.NoError(t, repo.Create(ctx, message))
+	}
+	require.NoError(t, repo.Create(ctx, &model.Message{MessageID: "other", RoomID: "room2", CreatedAt: createdAt}))
+
+	tests := []struct {
+		name             string
+		lastEvaluatedKey string
+		limit            int
+		expectedIDs      []string
+		expectedNextKey  string
+	}{
+		{
+			name:            "first page skips the reply but counts it",
+			limit:           2,
+			expectedIDs:     []string{"m3"},
+			expectedNextKey: createdAt.Add(2 * time.Minute).Format(time.RFC3339Nano),
+		},
+		{
+			name:             "next page",
+			lastEvaluatedKey: createdAt.Add(2 * time.Minute).Format(time.RFC3339Nano),
+			limit:            2,
+			expectedIDs:      []string{"m2", "m1"},
+			expectedNextKey:  createdAt.Format(time.RFC3339Nano),
+		},
+		{
+			name:             "nothing after the last key",
+			lastEvaluatedKey: createdAt.Format(time.RFC3339Nano),
+			limit:            2,
+			expectedIDs:      []string{},
+		},
+		{
+			name:        "last page below the limit",
+			limit:       10,
+			expectedIDs: []string{"m3", "m2", "m1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, nextKey, err := repo.GetMessagesByRoomID(ctx, "room1", tt.lastEvaluatedKey, tt.limit)
+			require.NoError(t, err)
+
+			ids := []string{}
+			for _, message := range messages {
+				ids = append(ids, message.MessageID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedNextKey, nextKey)
+		})
+	}
+}
+
+func TestMessageRepository_Replies(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := NewMessageRepository(NewStore())
+	parent := &model.Message{MessageID: "parent", RoomID: "room1", CreatedAt: createdAt}
+	require.NoError(t, repo.Create(ctx, parent))
+	for i, id := range []string{"r1", "r2", "r3"} {
+		require.NoError(t, repo.Create(ctx, &model.Message{
+			MessageID:       id,
+			RoomID:          "room1",
+			ParentMessageID: "parent",
+			CreatedAt:       createdAt.Add(time.Duration(i+1) * time.Minute),
+		}))
+		_, err := repo.AddReply(ctx, parent, createdAt.Add(time.Duration(i+1)*time.Minute))
+		require.NoError(t, err)
+	}
+
+	replies, nextKey, err := repo.GetReplies(ctx, "room1", "parent", "", 2)
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	assert.Equal(t, "r1", replies[0].MessageID)
+	assert.Equal(t, "r2", replies[1].MessageID)
+
+	replies, nextKey, err = repo.GetReplies(ctx, "room1", "parent", nextKey, 2)
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	assert.Equal(t, "r3", replies[0].MessageID)
+	assert.Empty(t, nextKey)
+
+	require.NoError(t, repo.RemoveReply(ctx, parent))
+	got, err := repo.GetByID(ctx, "room1", "parent")
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.ReplyCount)
+	assert.Equal(t, createdAt.Add(3*time.Minute), *got.LastReplyAt)
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+// MessageSearchRepository matches the query as a case-insensitive substring of the content instead of scoring
+// terms as Elasticsearch does, so matches are returned newest first.
+type MessageSearchRepository struct {
+	store *Store
+}
+
+func NewMessageSearchRepository(store *Store) repository.MessageSearchRepository {
+	return &MessageSearchRepository{
+		store,
+	}
+}
+
+func (mr *MessageSearchRepository) Index(ctx context.Context, message *model.Message) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	mr.store.indexed[message.MessageID] = copyMessage(message)
+	return nil
+}
+
+func (mr *MessageSearchRepository) BulkIndex(ctx context.Context, messages []*model.Message) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	for _, message := range messages {
+		mr.store.indexed[message.MessageID] = copyMessage(message)
+	}
+	return nil
+}
+
+func (mr *MessageSearchRepository) Delete(ctx context.Context, messageId string) error {
+	mr.store.mu.Lock()
+	defer mr.store.mu.Unlock()
+
+	delete(mr.store.indexed, messageId)
+	return nil
+}
+
+func (mr *MessageSearchRepository) Search(ctx context.Context, query string, roomIds []string, offset, limit int) ([]*model.MessageSearchHit, int, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if len(roomIds) == 0 || query == "" {
+		return []*model.MessageSearchHit{}, 0, nil
+	}
+
+	inRooms := make(map[string]bool, len(roomIds))
+	for _, roomId := range roomIds {
+		inRooms[roomId] = true
+	}
+
+	mr.store.mu.RLock()
+	defer mr.store.mu.RUnlock()
+
+	var matches []*model.Message
+	for _, message := range mr.store.indexed {
+		if inRooms[message.RoomID] && strings.Contains(strings.ToLower(message.Content), query) {
+			matches = append(matches, message)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].CreatedAt.After(matches[j].CreatedAt)
+	})
+
+	hits := []*model.MessageSearchHit{}
+	for i := offset; i < len(matches) && i < offset+limit; i++ {
+		hits = append(hits, &model.MessageSearchHit{
+			Message:    copyMessage(matches[i]),
+			Highlights: []string{highlight(matches[i].Content, query)},
+		})
+	}
+
+	return hits, len(matches), nil
+}
+
+// highlight wraps every occurrence of the lower-cased query in <em> tags and escapes the rest of the content.
+func highlight(content, query string) string {
+	var b strings.Builder
+	lower := strings.ToLower(content)
+	for {
+		i := strings.Index(lower, query)
+		// lower-casing keeps the byte offsets of the content for all but a few runes, which are then left unmarked
+		if i < 0 || len(lower) != len(content) {
+			b.WriteString(html.EscapeString(content))
+			return b.String()
+		}
+		b.WriteString(html.EscapeString(content[:i]))
+		b.WriteString("<em>" + html.EscapeString(content[i:i+len(query)]) + "</em>")
+		content, lower = content[i+len(query):], lower[i+len(query):]
+	}
+}
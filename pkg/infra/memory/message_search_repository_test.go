@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageSearchRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := NewMessageSearchRepository(NewStore())
+	require.NoError(t, repo.BulkIndex(ctx, []*model.Message{
+		{MessageID: "m1", RoomID: "room1", Content: "Hello <b>world</b>", CreatedAt: createdAt},
+		{MessageID: "m2", RoomID: "room1", Content: "hello, hello", CreatedAt: createdAt.Add(time.Minute)},
+		{MessageID: "m3", RoomID: "room2", Content: "hello from elsewhere", CreatedAt: createdAt},
+		{MessageID: "m4", RoomID: "room1", Content: "goodbye", CreatedAt: createdAt},
+	}))
+	require.NoError(t, repo.Delete(ctx, "m3"))
+
+	tests := []struct {
+		name               string
+		query              string
+		roomIds            []string
+		offset             int
+		limit              int
+		expectedIDs        []string
+		expectedHighlights [][]string
+		expectedTotal      int
+	}{
+		{
+			name:               "newest first with escaped highlights",
+			query:              "HELLO",
+			roomIds:            []string{"room1", "room2"},
+			limit:              10,
+			expectedIDs:        []string{"m2", "m1"},
+			expectedHighlights: [][]string{{"<em>hello</em>, <em>hello</em>"}, {"<em>Hello</em> &lt;b&gt;world&lt;/b&gt;"}},
+			expectedTotal:      2,
+		},
+		{
+			name:               "offset",
+			query:              "hello",
+			roomIds:            []string{"room1"},
+			offset:             1,
+			limit:              10,
+			expectedIDs:        []string{"m1"},
+			expectedHighlights: [][]string{{"<em>Hello</em> &lt;b&gt;world&lt;/b&gt;"}},
+			expectedTotal:      2,
+		},
+		{
+			name:          "no rooms",
+			query:         "hello",
+			limit:         10,
+			expectedIDs:   []string{},
+			expectedTotal: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, total, err := repo.Search(ctx, tt.query, tt.roomIds, tt.offset, tt.limit)
+			require.NoError(t, err)
+
+			ids := []string{}
+			var highlights [][]string
+			for _, hit := range hits {
+				ids = append(ids, hit.Message.MessageID)
+				highlights = append(highlights, hit.Highlights)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedHighlights, highlights)
+			assert.Equal(t, tt.expectedTotal, total)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type ReactionRepository struct {
+	store *Store
+}
+
+func NewReactionRepository(store *Store) repository.ReactionRepository {
+	return &ReactionRepository{
+		store,
+	}
+}
+
+func (rr *ReactionRepository) Add(ctx context.Context, reaction *model.Reaction) (int, bool, error) {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	key := reaction.UserID + "#" + reaction.Emoji
+	reactions, ok := rr.store.reactions[reaction.MessageID]
+	if !ok {
+		reactions = make(map[string]*model.Reaction)
+		rr.store.reactions[reaction.MessageID] = reactions
+	}
+	if _, ok := reactions[key]; ok {
+		return rr.store.counts[reaction.MessageID][reaction.Emoji], false, nil
+	}
+
+	copied := *reaction
+	reactions[key] = &copied
+	return rr.store.addCount(reaction.MessageID, reaction.Emoji, 1), true, nil
+}
+
+func (rr *ReactionRepository) Remove(ctx context.Context, messageId, userId, emoji string) (int, bool, error) {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	key := userId + "#" + emoji
+	if _, ok := rr.store.reactions[messageId][key]; !ok {
+		return rr.store.counts[messageId][emoji], false, nil
+	}
+
+	delete(rr.store.reactions[messageId], key)
+	return rr.store.addCount(messageId, emoji, -1), true, nil
+}
+
+func (s *Store) addCount(messageId, emoji string, delta int) int {
+	counts, ok := s.counts[messageId]
+	if !ok {
+		counts = make(map[string]int)
+		s.counts[messageId] = counts
+	}
+	counts[emoji] += delta
+	return counts[emoji]
+}
+
+func (rr *ReactionRepository) DeleteByMessageID(ctx context.Context, messageId string) error {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	delete(rr.store.reactions, messageId)
+	delete(rr.store.counts, messageId)
+	return nil
+}
+
+// GetCounts orders the counts of a message by emoji, as the counters are sorted in the Reactions table.
+func (rr *ReactionRepository) GetCounts(ctx context.Context, messageIds []string) (map[string][]*model.ReactionCount, error) {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+
+	counts := make(map[string][]*model.ReactionCount)
+	for _, messageId := range messageIds {
+		for _, emoji := range sortedKeys(rr.store.counts[messageId], false) {
+			count := rr.store.counts[messageId][emoji]
+			if count == 0 {
+				continue
+			}
+			counts[messageId] = append(counts[messageId], &model.ReactionCount{
+				Emoji: emoji,
+				Count: count,
+			})
+		}
+	}
+
+	return counts, nil
+}
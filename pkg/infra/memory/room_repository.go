@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type RoomRepository struct {
+	store *Store
+}
+
+func NewRoomRepository(store *Store) repository.RoomRepository {
+	return &RoomRepository{
+		store,
+	}
+}
+
+func (r *RoomRepository) GetByID(ctx context.Context, roomId string) (*model.Room, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	room, ok := r.store.rooms[roomId]
+	if !ok {
+		return nil, apperror.NewNotFoundErr("Room", "RoomID: "+roomId)
+	}
+
+	copied := *room
+	return &copied, nil
+}
+
+func (r *RoomRepository) BatchGetByIDs(ctx context.Context, roomIds []string) ([]*model.Room, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	seen := make(map[string]bool, len(roomIds))
+	rooms := []*model.Room{}
+	for _, roomId := range roomIds {
+		room, ok := r.store.rooms[roomId]
+		if !ok || seen[roomId] {
+			continue
+		}
+		seen[roomId] = true
+		copied := *room
+		rooms = append(rooms, &copied)
+	}
+
+	return rooms, nil
+}
+
+// GetByName returns nil without an error when no room has the name.
+func (r *RoomRepository) GetByName(ctx context.Context, name string) (*model.Room, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, room := range r.store.rooms {
+		if room.Name == name {
+			copied := *room
+			return &copied, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *RoomRepository) GetAllPublic(ctx context.Context) ([]*model.Room, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var rooms []*model.Room
+	for _, roomId := range sortedKeys(r.store.rooms, false) {
+		room := r.store.rooms[roomId]
+		if room.RoomType == model.Public {
+			copied := *room
+			rooms = append(rooms, &copied)
+		}
+	}
+
+	return rooms, nil
+}
+
+func (r *RoomRepository) CreateAndAddUser(ctx context.Context, room *model.Room, ownerId string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	copied := *room
+	r.store.rooms[room.RoomID] = &copied
+	r.store.putRoomUser(&model.RoomUser{
+		RoomID: room.RoomID,
+		UserID: ownerId,
+		Role:   model.Owner,
+	})
+
+	return nil
+}
+
+// Delete removes the room only; its members are left in place, as in the RoomUsers table.
+func (r *RoomRepository) Delete(ctx context.Context, roomId string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.rooms, roomId)
+	return nil
+}
+
+func (r *RoomRepository) Update(ctx context.Context, room *model.Room) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// an update creates the room when it does not exist, as UpdateItem does
+	stored, ok := r.store.rooms[room.RoomID]
+	if !ok {
+		stored = &model.Room{RoomID: room.RoomID}
+		r.store.rooms[room.RoomID] = stored
+	}
+	stored.Name = room.Name
+	stored.RoomType = room.RoomType
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type RoomUserRepository struct {
+	store *Store
+}
+
+func NewRoomUserRepository(store *Store) repository.RoomUserRepository {
+	return &RoomUserRepository{
+		store,
+	}
+}
+
+func (s *Store) putRoomUser(roomUser *model.RoomUser) {
+	members, ok := s.roomUsers[roomUser.RoomID]
+	if !ok {
+		members = make(map[string]*model.RoomUser)
+		s.roomUsers[roomUser.RoomID] = members
+	}
+	members[roomUser.UserID] = roomUser
+}
+
+func (r *RoomUserRepository) GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.RoomUser, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var roomUsers []*model.RoomUser
+	for _, roomId := range sortedKeys(r.store.roomUsers, false) {
+		if roomUser, ok := r.store.roomUsers[roomId][userId]; ok {
+			copied := *roomUser
+			roomUsers = append(roomUsers, &copied)
+		}
+	}
+
+	return roomUsers, nil
+}
+
+func (r *RoomUserRepository) GetByRoomIDAndUserID(ctx context.Context, roomId, userId string) (*model.RoomUser, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roomUser, ok := r.store.roomUsers[roomId][userId]
+	if !ok {
+		return nil, apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+userId)
+	}
+
+	copied := *roomUser
+	return &copied, nil
+}
+
+// GetUsersByRoomID pages through the members in descending order of user ID; the key is the user ID of the last one.
+func (r *RoomUserRepository) GetUsersByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.RoomUser, string, error) {
+	if limit < 1 {
+		return nil, "", errInvalidLimit
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	members := r.store.roomUsers[roomId]
+	userIds, nextKey := page(sortedKeys(members, true), lastEvaluatedKey, limit, true)
+
+	roomUsers := make([]*model.RoomUser, len(userIds))
+	for i, userId := range userIds {
+		copied := *members[userId]
+		roomUsers[i] = &copied
+	}
+
+	return roomUsers, nextKey, nil
+}
+
+func (r *RoomUserRepository) RemoveUserFromRoom(ctx context.Context, roomId, userId string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.roomUsers[roomId], userId)
+	return nil
+}
+
+// AddUsersToRoom replaces existing memberships of the users with plain ones, as the puts of the DynamoDB version do.
+func (r *RoomUserRepository) AddUsersToRoom(ctx context.Context, roomId string, userIDs []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, userId := range userIDs {
+		r.store.putRoomUser(&model.RoomUser{
+			RoomID: roomId,
+			UserID: userId,
+			Role:   model.Member,
+		})
+	}
+
+	return nil
+}
+
+func (r *RoomUserRepository) UpdateRole(ctx context.Context, roomId, userId string, role model.RoomRole) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	roomUser, ok := r.store.roomUsers[roomId][userId]
+	if !ok {
+		return apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+userId)
+	}
+	roomUser.Role = role
+
+	return nil
+}
+
+func (r *RoomUserRepository) UpdateLastRead(ctx context.Context, roomId, userId, messageId string, readAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	roomUser, ok := r.store.roomUsers[roomId][userId]
+	if !ok {
+		return false, nil
+	}
+	if !roomUser.LastReadAt.IsZero() && timeKey(roomUser.LastReadAt) >= timeKey(readAt) {
+		return false, nil
+	}
+
+	roomUser.LastReadMessageID = messageId
+	roomUser.LastReadAt = readAt
+	return true, nil
+}
+
+func (r *RoomUserRepository) TransferOwnership(ctx context.Context, roomId, currentOwnerId, newOwnerId string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	currentOwner, ok := r.store.roomUsers[roomId][currentOwnerId]
+	if !ok {
+		return apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+currentOwnerId)
+	}
+	newOwner, ok := r.store.roomUsers[roomId][newOwnerId]
+	if !ok {
+		return apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+newOwnerId)
+	}
+
+	// the previous owner stays in the room as an admin
+	currentOwner.Role = model.Admin
+	newOwner.Role = model.Owner
+	return nil
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomUserRepository_GetUsersByRoomID(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+
+	require.NoError(t, NewRoomRepository(store).CreateAndAddUser(ctx, &model.Room{RoomID: "room1", Name: "room1"}, "alice"))
+	repo := NewRoomUserRepository(store)
+	require.NoError(t, repo.AddUsersToRoom(ctx, "room1", []string{"bob", "carol"}))
+
+	roomUsers, nextKey, err := repo.GetUsersByRoomID(ctx, "room1", "", 2)
+	require.NoError(t, err)
+	require.Len(t, roomUsers, 2)
+	assert.Equal(t, "carol", roomUsers[0].UserID)
+	assert.Equal(t, "bob", roomUsers[1].UserID)
+	assert.Equal(t, "bob", nextKey)
+
+	roomUsers, nextKey, err = repo.GetUsersByRoomID(ctx, "room1", nextKey, 2)
+	require.NoError(t, err)
+	require.Len(t, roomUsers, 1)
+	assert.Equal(t, "alice", roomUsers[0].UserID)
+	assert.Equal(t, model.Owner, roomUsers[0].Role)
+	assert.Empty(t, nextKey)
+}
+
+func TestRoomUserRepository_UpdateLastRead(t *testing.T) {
+	ctx := context.Background()
+	readAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := NewRoomUserRepository(NewStore())
+	require.NoError(t, repo.AddUsersToRoom(ctx, "room1", []string{"bob"}))
+
+	tests := []struct {
+		name            string
+		userId          string
+		messageId       string
+		readAt          time.Time
+		expectedUpdated bool
+	}{
+		{
+			name:            "first read",
+			userId:          "bob",
+			messageId:       "m2",
+			readAt:          readAt,
+			expectedUpdated: true,
+		},
+		{
+			name:      "older read does not move the marker back",
+			userId:    "bob",
+			messageId: "m1",
+			readAt:    readAt.Add(-time.Minute),
+		},
+		{
+			name:      "not a member",
+			userId:    "carol",
+			messageId: "m3",
+			readAt:    readAt.Add(time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := repo.UpdateLastRead(ctx, "room1", tt.userId, tt.messageId, tt.readAt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedUpdated, updated)
+		})
+	}
+
+	roomUser, err := repo.GetByRoomIDAndUserID(ctx, "room1", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "m2", roomUser.LastReadMessageID)
+}
+
+func TestRoomUserRepository_TransferOwnership(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+
+	require.NoError(t, NewRoomRepository(store).CreateAndAddUser(ctx, &model.Room{RoomID: "room1", Name: "room1"}, "alice"))
+	repo := NewRoomUserRepository(store)
+	require.NoError(t, repo.AddUsersToRoom(ctx, "room1", []string{"bob"}))
+
+	var notFoundErr *apperror.NotFoundErr
+	err := repo.TransferOwnership(ctx, "room1", "alice", "carol")
+	assert.True(t, errors.As(err, &notFoundErr))
+
+	require.NoError(t, repo.TransferOwnership(ctx, "room1", "alice", "bob"))
+
+	alice, err := repo.GetByRoomIDAndUserID(ctx, "room1", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, model.Admin, alice.Role)
+
+	bob, err := repo.GetByRoomIDAndUserID(ctx, "room1", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, model.Owner, bob.Role)
+}
@@ -0,0 +1,84 @@
+// Package memory implements the repositories in memory, so that the API can run without DynamoDB, S3 or
+// Elasticsearch. Pagination keys and orderings follow the DynamoDB implementations; everything is lost on restart.
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+)
+
+// Store holds the tables shared by the repositories. Repositories created from the same store see each
+// other's writes, as the DynamoDB repositories do through the tables.
+type Store struct {
+	mu sync.RWMutex
+
+	rooms     map[string]*model.Room
+	roomUsers map[string]map[string]*model.RoomUser
+	users     map[string]*model.User
+	// messages are keyed by room and creation time, the primary key of the Messages table
+	messages    map[string]map[string]*model.Message
+	reactions   map[string]map[string]*model.Reaction
+	counts      map[string]map[string]int
+	attachments map[string]*model.Attachment
+	objects     map[string]*model.ObjectInfo
+	// indexed holds the messages as they were last indexed for search, by message ID
+	indexed map[string]*model.Message
+}
+
+func NewStore() *Store {
+	return &Store{
+		rooms:       make(map[string]*model.Room),
+		roomUsers:   make(map[string]map[string]*model.RoomUser),
+		users:       make(map[string]*model.User),
+		messages:    make(map[string]map[string]*model.Message),
+		reactions:   make(map[string]map[string]*model.Reaction),
+		counts:      make(map[string]map[string]int),
+		attachments: make(map[string]*model.Attachment),
+		objects:     make(map[string]*model.ObjectInfo),
+		indexed:     make(map[string]*model.Message),
+	}
+}
+
+var errInvalidLimit = errors.New("limit must be at least 1")
+
+// timeKey formats a time the way DynamoDB stores it. Keys are compared as strings, as DynamoDB compares them.
+func timeKey(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// page returns up to limit of the sorted keys after lastEvaluatedKey, which is empty for the first page, and the key
+// to continue from. Like DynamoDB, it returns a key whenever the limit is reached, even if nothing is left after it.
+func page(keys []string, lastEvaluatedKey string, limit int, descending bool) ([]string, string) {
+	start := 0
+	if lastEvaluatedKey != "" {
+		start = sort.Search(len(keys), func(i int) bool {
+			if descending {
+				return keys[i] < lastEvaluatedKey
+			}
+			return keys[i] > lastEvaluatedKey
+		})
+	}
+
+	keys = keys[start:]
+	if len(keys) < limit {
+		return keys, ""
+	}
+	return keys[:limit], keys[limit-1]
+}
+
+func sortedKeys[V any](items map[string]V, descending bool) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	if descending {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	} else {
+		sort.Strings(keys)
+	}
+	return keys
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &UserRepository{
+		store,
+	}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	copied := *user
+	r.store.users[user.UserID] = &copied
+	return nil
+}
+
+// GetMultiple pages through the users in ascending order of user ID; the key is the user ID of the last one.
+func (r *UserRepository) GetMultiple(ctx context.Context, lastEvaluatedKey string, limit int) ([]*model.User, string, error) {
+	if limit < 1 {
+		return nil, "", errInvalidLimit
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	userIds, nextKey := page(sortedKeys(r.store.users, false), lastEvaluatedKey, limit, false)
+
+	users := make([]*model.User, len(userIds))
+	for i, userId := range userIds {
+		copied := *r.store.users[userId]
+		users[i] = &copied
+	}
+
+	return users, nextKey, nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, userId string) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[userId]
+	if !ok {
+		return nil, apperror.NewNotFoundErr("User", "UserID: "+userId)
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+// BatchGetUsers leaves out the users that do not exist.
+func (r *UserRepository) BatchGetUsers(ctx context.Context, userIds []string) ([]*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []*model.User
+	for _, userId := range userIds {
+		if user, ok := r.store.users[userId]; ok {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) UpdateLastSeen(ctx context.Context, userId string, lastSeenAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userId]
+	if !ok {
+		return apperror.NewNotFoundErr("User", "UserID: "+userId)
+	}
+
+	user.LastSeenAt = &lastSeenAt
+	return nil
+}
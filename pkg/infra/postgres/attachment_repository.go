@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) repository.AttachmentRepository {
+	return &AttachmentRepository{
+		db,
+	}
+}
+
+const attachmentColumns = "attachment_id, room_id, user_id, message_id, name, size, content_type, object_key, status, created_at"
+
+func scanAttachment(row interface{ Scan(...interface{}) error }) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := row.Scan(&attachment.AttachmentID, &attachment.RoomID, &attachment.UserID, &attachment.MessageID, &attachment.Name,
+		&attachment.Size, &attachment.ContentType, &attachment.ObjectKey, &attachment.Status, &attachment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	attachment.CreatedAt = attachment.CreatedAt.UTC()
+	return &attachment, nil
+}
+
+func (ar *AttachmentRepository) Create(ctx context.Context, attachment *model.Attachment) error {
+	_, err := ar.db.ExecContext(ctx, `
+		INSERT INTO attachments (`+attachmentColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		attachment.AttachmentID, attachment.RoomID, attachment.UserID, attachment.MessageID, attachment.Name,
+		attachment.Size, attachment.ContentType, attachment.ObjectKey, attachment.Status, dbTime(attachment.CreatedAt))
+	return err
+}
+
+func (ar *AttachmentRepository) GetByID(ctx context.Context, attachmentId string) (*model.Attachment, error) {
+	row := ar.db.QueryRowContext(ctx, "SELECT "+attachmentColumns+" FROM attachments WHERE attachment_id = $1", attachmentId)
+
+	attachment, err := scanAttachment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NewNotFoundErr("Attachment", "AttachmentID: "+attachmentId)
+	}
+	return attachment, err
+}
+
+func (ar *AttachmentRepository) MarkAttached(ctx context.Context, attachmentId, messageId string) error {
+	result, err := ar.db.ExecContext(ctx, `
+		UPDATE attachments SET message_id = $2, status = $3
+		WHERE attachment_id = $1 AND status = $4`,
+		attachmentId, messageId, model.Attached, model.Pending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperror.NewNotFoundErr("Attachment", "pending AttachmentID: "+attachmentId)
+	}
+
+	return nil
+}
+
+// GetPendingBefore returns the oldest pending attachments first.
+func (ar *AttachmentRepository) GetPendingBefore(ctx context.Context, before time.Time, limit int) ([]*model.Attachment, error) {
+	rows, err := ar.db.QueryContext(ctx, `
+		SELECT `+attachmentColumns+` FROM attachments
+		WHERE status = $1 AND created_at < $2
+		ORDER BY created_at
+		LIMIT $3`,
+		model.Pending, dbTime(before), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*model.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (ar *AttachmentRepository) Delete(ctx context.Context, attachmentId string) error {
+	_, err := ar.db.ExecContext(ctx, "DELETE FROM attachments WHERE attachment_id = $1", attachmentId)
+	return err
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/shunsukenagashima/chat-api/pkg/infra/repositorytest"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestDB connects to the database at POSTGRES_URL and migrates it. Every table is emptied, so it must
+// not point to a database holding anything worth keeping.
+func openTestDB(t *testing.T) *sql.DB {
+	url := os.Getenv("POSTGRES_URL")
+	if url == "" {
+		t.Skip("POSTGRES_URL is not set")
+	}
+
+	ctx := context.Background()
+	db, err := Open(ctx, url)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, Migrate(ctx, db))
+	// migrating again must be a no-op
+	require.NoError(t, Migrate(ctx, db))
+
+	return db
+}
+
+func truncate(t *testing.T, db *sql.DB) {
+	_, err := db.Exec("TRUNCATE users, rooms, room_users, messages, reactions, attachments")
+	require.NoError(t, err)
+}
+
+func TestRepositories(t *testing.T) {
+	db := openTestDB(t)
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		truncate(t, db)
+		return repositorytest.Repositories{
+			Room:     NewRoomRepository(db),
+			RoomUser: NewRoomUserRepository(db),
+			User:     NewUserRepository(db),
+			Message:  NewMessageRepository(db),
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimPage(t *testing.T) {
+	tests := []struct {
+		name            string
+		items           []string
+		limit           int
+		expectedItems   []string
+		expectedNextKey string
+	}{
+		{
+			name:            "more than the limit",
+			items:           []string{"a", "b", "c"},
+			limit:           2,
+			expectedItems:   []string{"a", "b"},
+			expectedNextKey: "b",
+		},
+		{
+			name:          "exactly the limit is the last page",
+			items:         []string{"a", "b"},
+			limit:         2,
+			expectedItems: []string{"a", "b"},
+		},
+		{
+			name:          "empty",
+			items:         []string{},
+			limit:         2,
+			expectedItems: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, nextKey := trimPage(tt.items, tt.limit, func(item string) string { return item })
+			assert.Equal(t, tt.expectedItems, items)
+			assert.Equal(t, tt.expectedNextKey, nextKey)
+		})
+	}
+}
+
+func TestTimeKey(t *testing.T) {
+	createdAt := time.Date(2023, 6, 1, 21, 0, 0, 123456000, time.FixedZone("JST", 9*60*60))
+
+	parsed, err := parseTimeKey("Message", timeKey(createdAt))
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(parsed))
+	assert.Equal(t, "2023-06-01T12:00:00.123456Z", timeKey(createdAt))
+
+	_, err = parseTimeKey("Message", "not a time")
+	var badRequestErr *apperror.BadRequestErr
+	assert.True(t, errors.As(err, &badRequestErr))
+}
@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type MessageRepository struct {
+	db *sql.DB
+}
+
+func NewMessageRepository(db *sql.DB) repository.MessageRepository {
+	return &MessageRepository{
+		db,
+	}
+}
+
+const messageColumns = "message_id, room_id, user_id, content, created_at, parent_message_id, reply_count, last_reply_at, attachments"
+
+func scanMessage(row interface{ Scan(...interface{}) error }) (*model.Message, error) {
+	var message model.Message
+	var lastReplyAt sql.NullTime
+	var attachments []byte
+	err := row.Scan(&message.MessageID, &message.RoomID, &message.UserID, &message.Content, &message.CreatedAt,
+		&message.ParentMessageID, &message.ReplyCount, &lastReplyAt, &attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	message.CreatedAt = message.CreatedAt.UTC()
+	if lastReplyAt.Valid {
+		t := lastReplyAt.Time.UTC()
+		message.LastReplyAt = &t
+	}
+	if attachments != nil {
+		if err := json.Unmarshal(attachments, &message.Attachments); err != nil {
+			return nil, err
+		}
+	}
+
+	return &message, nil
+}
+
+func scanMessages(rows *sql.Rows) ([]*model.Message, error) {
+	defer rows.Close()
+
+	messages := []*model.Message{}
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+func messageKey(message *model.Message) string {
+	return timeKey(message.CreatedAt)
+}
+
+// GetMessagesByRoomID pages through the room newest first; the key is the creation time of the last message.
+func (mr *MessageRepository) GetMessagesByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+	return mr.page(ctx, `
+		SELECT `+messageColumns+` FROM messages
+		WHERE room_id = $1 AND parent_message_id = '' AND ($2::timestamptz IS NULL OR created_at < $2)
+		ORDER BY created_at DESC
+		LIMIT $3`,
+		roomId, lastEvaluatedKey, limit)
+}
+
+// GetReplies pages through the replies oldest first; the key is the creation time of the last reply.
+func (mr *MessageRepository) GetReplies(ctx context.Context, roomId, parentMessageId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+	return mr.page(ctx, `
+		SELECT `+messageColumns+` FROM messages
+		WHERE room_id = $1 AND parent_message_id = $4 AND ($2::timestamptz IS NULL OR created_at > $2)
+		ORDER BY created_at
+		LIMIT $3`,
+		roomId, lastEvaluatedKey, limit, parentMessageId)
+}
+
+// page runs a query of a page of messages taking the room ID, the time after the key, the limit and then args.
+func (mr *MessageRepository) page(ctx context.Context, query, roomId, lastEvaluatedKey string, limit int, args ...interface{}) ([]*model.Message, string, error) {
+	if limit < 1 {
+		return nil, "", errInvalidLimit
+	}
+
+	var after sql.NullTime
+	if lastEvaluatedKey != "" {
+		t, err := parseTimeKey("Message", lastEvaluatedKey)
+		if err != nil {
+			return nil, "", err
+		}
+		after = sql.NullTime{Time: t, Valid: true}
+	}
+
+	rows, err := mr.db.QueryContext(ctx, query, append([]interface{}{roomId, after, limit + 1}, args...)...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	messages, nextKey := trimPage(messages, limit, messageKey)
+	return messages, nextKey, nil
+}
+
+func (mr *MessageRepository) GetMessagesSince(ctx context.Context, roomId string, since time.Time, limit int) ([]*model.Message, error) {
+	if limit < 1 {
+		return nil, errInvalidLimit
+	}
+
+	rows, err := mr.db.QueryContext(ctx, `
+		SELECT * FROM (
+			SELECT `+messageColumns+` FROM messages
+			WHERE room_id = $1 AND parent_message_id = '' AND created_at > $2
+			ORDER BY created_at DESC
+			LIMIT $3
+		) AS newest
+		ORDER BY created_at`,
+		roomId, dbTime(since), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMessages(rows)
+}
+
+// CountMessagesSince counts the messages of every room with one query, passing the rooms and their times as arrays.
+func (mr *MessageRepository) CountMessagesSince(ctx context.Context, userId string, since map[string]time.Time, limit int) (map[string]int, error) {
+	roomIds := make([]string, 0, len(since))
+	sinceTimes := make([]string, 0, len(since))
+	for roomId, roomSince := range since {
+		roomIds = append(roomIds, roomId)
+		sinceTimes = append(sinceTimes, dbTime(roomSince).Format(time.RFC3339Nano))
+	}
+
+	rows, err := mr.db.QueryContext(ctx, `
+		SELECT rooms.room_id, (
+			SELECT COUNT(*) FROM (
+				SELECT 1 FROM messages
+				WHERE room_id = rooms.room_id AND created_at > rooms.since AND user_id <> $3
+				LIMIT $4
+			) AS unread
+		)
+		FROM unnest($1::text[], $2::timestamptz[]) AS rooms (room_id, since)`,
+		pq.Array(roomIds), pq.Array(sinceTimes), userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(since))
+	for rows.Next() {
+		var roomId string
+		var count int
+		if err := rows.Scan(&roomId, &count); err != nil {
+			return nil, err
+		}
+		counts[roomId] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (mr *MessageRepository) GetByID(ctx context.Context, roomId, messageId string) (*model.Message, error) {
+	row := mr.db.QueryRowContext(ctx, "SELECT "+messageColumns+" FROM messages WHERE room_id = $1 AND message_id = $2", roomId, messageId)
+
+	message, err := scanMessage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NewNotFoundErr("Message", "MessageID: "+messageId)
+	}
+	return message, err
+}
+
+// Create stores the message under its room and creation time, refusing a message created at the same time
+// as another of the room. Times are stored with the microsecond precision of PostgreSQL.
+func (mr *MessageRepository) Create(ctx context.Context, message *model.Message) error {
+	// lib/pq sends []byte as bytea, which is no valid jsonb
+	var attachments sql.NullString
+	if message.Attachments != nil {
+		encoded, err := json.Marshal(message.Attachments)
+		if err != nil {
+			return err
+		}
+		attachments = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	var lastReplyAt sql.NullTime
+	if message.LastReplyAt != nil {
+		lastReplyAt = sql.NullTime{Time: dbTime(*message.LastReplyAt), Valid: true}
+	}
+
+	result, err := mr.db.ExecContext(ctx, `
+		INSERT INTO messages (`+messageColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (room_id, created_at) DO NOTHING`,
+		message.MessageID, message.RoomID, message.UserID, message.Content, dbTime(message.CreatedAt),
+		message.ParentMessageID, message.ReplyCount, lastReplyAt, attachments)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperror.NewAlreadyExistsErr("Message", "RoomID: "+message.RoomID+", CreatedAt: "+dbTime(message.CreatedAt).Format(time.RFC3339Nano))
+	}
+
+	return nil
+}
+
+func (mr *MessageRepository) Update(ctx context.Context, roomId, messageId, newContent string) error {
+	result, err := mr.db.ExecContext(ctx, "UPDATE messages SET content = $3 WHERE room_id = $1 AND message_id = $2", roomId, messageId, newContent)
+	return notFoundIfNone(result, err, messageId)
+}
+
+func (mr *MessageRepository) Delete(ctx context.Context, roomId, messageId string) error {
+	result, err := mr.db.ExecContext(ctx, "DELETE FROM messages WHERE room_id = $1 AND message_id = $2", roomId, messageId)
+	return notFoundIfNone(result, err, messageId)
+}
+
+func notFoundIfNone(result sql.Result, err error, messageId string) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperror.NewNotFoundErr("Message", "MessageID: "+messageId)
+	}
+
+	return nil
+}
+
+func (mr *MessageRepository) AddReply(ctx context.Context, parent *model.Message, repliedAt time.Time) (*model.Message, error) {
+	row := mr.db.QueryRowContext(ctx, `
+		UPDATE messages SET reply_count = reply_count + 1, last_reply_at = $3
+		WHERE room_id = $1 AND message_id = $2
+		RETURNING `+messageColumns,
+		parent.RoomID, parent.MessageID, dbTime(repliedAt))
+
+	updated, err := scanMessage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NewNotFoundErr("Message", "MessageID: "+parent.MessageID)
+	}
+	return updated, err
+}
+
+// RemoveReply does nothing when the parent was deleted before its replies.
+func (mr *MessageRepository) RemoveReply(ctx context.Context, parent *model.Message) error {
+	_, err := mr.db.ExecContext(ctx, `
+		UPDATE messages SET reply_count = reply_count - 1
+		WHERE room_id = $1 AND message_id = $2 AND reply_count >= 1`,
+		parent.RoomID, parent.MessageID)
+	return err
+}
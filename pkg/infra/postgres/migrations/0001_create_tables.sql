@@ -0,0 +1,70 @@
+-- The tables mirror the DynamoDB tables, including what they leave out: members and messages outlive their
+-- room, so there are no foreign keys, and room names are only kept unique by the usecases.
+
+CREATE TABLE users (
+	user_id    TEXT PRIMARY KEY,
+	username   TEXT NOT NULL,
+	email      TEXT NOT NULL,
+	image_url  TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE rooms (
+	room_id   TEXT PRIMARY KEY,
+	name      TEXT NOT NULL,
+	room_type TEXT NOT NULL
+);
+
+CREATE INDEX rooms_name_idx ON rooms (name);
+
+CREATE TABLE room_users (
+	room_id              TEXT NOT NULL,
+	user_id              TEXT NOT NULL,
+	role                 TEXT NOT NULL,
+	last_read_message_id TEXT NOT NULL DEFAULT '',
+	last_read_at         TIMESTAMPTZ,
+	PRIMARY KEY (room_id, user_id)
+);
+
+CREATE INDEX room_users_user_id_idx ON room_users (user_id);
+
+CREATE TABLE messages (
+	room_id           TEXT NOT NULL,
+	created_at        TIMESTAMPTZ NOT NULL,
+	message_id        TEXT NOT NULL,
+	user_id           TEXT NOT NULL,
+	content           TEXT NOT NULL,
+	parent_message_id TEXT NOT NULL DEFAULT '',
+	reply_count       INTEGER NOT NULL DEFAULT 0,
+	last_reply_at     TIMESTAMPTZ,
+	attachments       JSONB,
+	PRIMARY KEY (room_id, created_at),
+	UNIQUE (room_id, message_id)
+);
+
+CREATE INDEX messages_parent_message_id_idx ON messages (parent_message_id, created_at) WHERE parent_message_id <> '';
+
+CREATE TABLE reactions (
+	message_id TEXT NOT NULL,
+	user_id    TEXT NOT NULL,
+	emoji      TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (message_id, user_id, emoji)
+);
+
+CREATE INDEX reactions_message_id_emoji_idx ON reactions (message_id, emoji);
+
+CREATE TABLE attachments (
+	attachment_id TEXT PRIMARY KEY,
+	room_id       TEXT NOT NULL,
+	user_id       TEXT NOT NULL,
+	message_id    TEXT NOT NULL DEFAULT '',
+	name          TEXT NOT NULL,
+	size          BIGINT NOT NULL,
+	content_type  TEXT NOT NULL,
+	object_key    TEXT NOT NULL,
+	status        TEXT NOT NULL,
+	created_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX attachments_status_created_at_idx ON attachments (status, created_at);
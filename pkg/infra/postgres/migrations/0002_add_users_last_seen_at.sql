@@ -0,0 +1,2 @@
+-- The time a user was last seen, recorded when their last connection closes.
+ALTER TABLE users ADD COLUMN last_seen_at TIMESTAMPTZ;
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+// ReactionRepository counts the reactions with an emoji when they are read rather than keeping counters,
+// which the index on message and emoji keeps cheap.
+type ReactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) repository.ReactionRepository {
+	return &ReactionRepository{
+		db,
+	}
+}
+
+func (rr *ReactionRepository) Add(ctx context.Context, reaction *model.Reaction) (int, bool, error) {
+	result, err := rr.db.ExecContext(ctx, `
+		INSERT INTO reactions (message_id, user_id, emoji, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING`,
+		reaction.MessageID, reaction.UserID, reaction.Emoji, dbTime(reaction.CreatedAt))
+
+	return rr.count(ctx, result, err, reaction.MessageID, reaction.Emoji)
+}
+
+func (rr *ReactionRepository) Remove(ctx context.Context, messageId, userId, emoji string) (int, bool, error) {
+	result, err := rr.db.ExecContext(ctx, "DELETE FROM reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3", messageId, userId, emoji)
+
+	return rr.count(ctx, result, err, messageId, emoji)
+}
+
+// count reads the number of reactions with the emoji back after a write, which changed something when it
+// affected a row.
+func (rr *ReactionRepository) count(ctx context.Context, result sql.Result, err error, messageId, emoji string) (int, bool, error) {
+	if err != nil {
+		return 0, false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, false, err
+	}
+
+	var count int
+	err = rr.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reactions WHERE message_id = $1 AND emoji = $2", messageId, emoji).Scan(&count)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return count, affected > 0, nil
+}
+
+// GetCounts orders the counts of a message by emoji, as the counters are sorted in the Reactions table.
+func (rr *ReactionRepository) GetCounts(ctx context.Context, messageIds []string) (map[string][]*model.ReactionCount, error) {
+	rows, err := rr.db.QueryContext(ctx, `
+		SELECT message_id, emoji, COUNT(*) FROM reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji
+		ORDER BY message_id, emoji`,
+		pq.Array(messageIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string][]*model.ReactionCount)
+	for rows.Next() {
+		var messageId string
+		var count model.ReactionCount
+		if err := rows.Scan(&messageId, &count.Emoji, &count.Count); err != nil {
+			return nil, err
+		}
+		counts[messageId] = append(counts[messageId], &count)
+	}
+
+	return counts, rows.Err()
+}
+
+func (rr *ReactionRepository) DeleteByMessageID(ctx context.Context, messageId string) error {
+	_, err := rr.db.ExecContext(ctx, "DELETE FROM reactions WHERE message_id = $1", messageId)
+	return err
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type RoomRepository struct {
+	db *sql.DB
+}
+
+func NewRoomRepository(db *sql.DB) repository.RoomRepository {
+	return &RoomRepository{
+		db,
+	}
+}
+
+func scanRoom(row interface{ Scan(...interface{}) error }) (*model.Room, error) {
+	var room model.Room
+	if err := row.Scan(&room.RoomID, &room.Name, &room.RoomType); err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *RoomRepository) GetByID(ctx context.Context, roomId string) (*model.Room, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT room_id, name, room_type FROM rooms WHERE room_id = $1", roomId)
+
+	room, err := scanRoom(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NewNotFoundErr("Room", "RoomID: "+roomId)
+	}
+	return room, err
+}
+
+func (r *RoomRepository) BatchGetByIDs(ctx context.Context, roomIds []string) ([]*model.Room, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT room_id, name, room_type FROM rooms WHERE room_id = ANY($1) ORDER BY room_id", pq.Array(roomIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rooms := []*model.Room{}
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+
+	return rooms, rows.Err()
+}
+
+// GetByName returns nil without an error when no room has the name.
+func (r *RoomRepository) GetByName(ctx context.Context, name string) (*model.Room, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT room_id, name, room_type FROM rooms WHERE name = $1 LIMIT 1", name)
+
+	room, err := scanRoom(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return room, err
+}
+
+func (r *RoomRepository) GetAllPublic(ctx context.Context) ([]*model.Room, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT room_id, name, room_type FROM rooms WHERE room_type = $1 ORDER BY room_id", model.Public)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rooms []*model.Room
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+
+	return rooms, rows.Err()
+}
+
+func (r *RoomRepository) CreateAndAddUser(ctx context.Context, room *model.Room, ownerId string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := upsertRoom(ctx, tx, room); err != nil {
+			return err
+		}
+		return putRoomUser(ctx, tx, room.RoomID, ownerId, model.Owner)
+	})
+}
+
+// Delete removes the room only; its members are left in place, as in the RoomUsers table.
+func (r *RoomRepository) Delete(ctx context.Context, roomId string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM rooms WHERE room_id = $1", roomId)
+	return err
+}
+
+// Update creates the room when it does not exist, as UpdateItem does.
+func (r *RoomRepository) Update(ctx context.Context, room *model.Room) error {
+	return upsertRoom(ctx, r.db, room)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func upsertRoom(ctx context.Context, db execer, room *model.Room) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO rooms (room_id, name, room_type) VALUES ($1, $2, $3)
+		ON CONFLICT (room_id) DO UPDATE SET name = excluded.name, room_type = excluded.room_type`,
+		room.RoomID, room.Name, room.RoomType)
+	return err
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type RoomUserRepository struct {
+	db *sql.DB
+}
+
+func NewRoomUserRepository(db *sql.DB) repository.RoomUserRepository {
+	return &RoomUserRepository{
+		db,
+	}
+}
+
+const roomUserColumns = "room_id, user_id, role, last_read_message_id, last_read_at"
+
+func scanRoomUser(row interface{ Scan(...interface{}) error }) (*model.RoomUser, error) {
+	var roomUser model.RoomUser
+	var lastReadAt sql.NullTime
+	if err := row.Scan(&roomUser.RoomID, &roomUser.UserID, &roomUser.Role, &roomUser.LastReadMessageID, &lastReadAt); err != nil {
+		return nil, err
+	}
+	if lastReadAt.Valid {
+		roomUser.LastReadAt = lastReadAt.Time.UTC()
+	}
+	return &roomUser, nil
+}
+
+func scanRoomUsers(rows *sql.Rows) ([]*model.RoomUser, error) {
+	defer rows.Close()
+
+	roomUsers := []*model.RoomUser{}
+	for rows.Next() {
+		roomUser, err := scanRoomUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		roomUsers = append(roomUsers, roomUser)
+	}
+
+	return roomUsers, rows.Err()
+}
+
+// putRoomUser replaces any membership of the user with a fresh one, as PutItem does.
+func putRoomUser(ctx context.Context, db execer, roomId, userId string, role model.RoomRole) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO room_users (room_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (room_id, user_id) DO UPDATE SET role = excluded.role, last_read_message_id = '', last_read_at = NULL`,
+		roomId, userId, role)
+	return err
+}
+
+func (r *RoomUserRepository) GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.RoomUser, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+roomUserColumns+" FROM room_users WHERE user_id = $1 ORDER BY room_id", userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRoomUsers(rows)
+}
+
+func (r *RoomUserRepository) GetByRoomIDAndUserID(ctx context.Context, roomId, userId string) (*model.RoomUser, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+roomUserColumns+" FROM room_users WHERE room_id = $1 AND user_id = $2", roomId, userId)
+
+	roomUser, err := scanRoomUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+userId)
+	}
+	return roomUser, err
+}
+
+// GetUsersByRoomID pages through the members in descending order of user ID; the key is the user ID of the last one.
+func (r *RoomUserRepository) GetUsersByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.RoomUser, string, error) {
+	if limit < 1 {
+		return nil, "", errInvalidLimit
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+roomUserColumns+` FROM room_users
+		WHERE room_id = $1 AND ($2 = '' OR user_id < $2)
+		ORDER BY user_id DESC
+		LIMIT $3`,
+		roomId, lastEvaluatedKey, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	roomUsers, err := scanRoomUsers(rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	roomUsers, nextKey := trimPage(roomUsers, limit, func(roomUser *model.RoomUser) string { return roomUser.UserID })
+	return roomUsers, nextKey, nil
+}
+
+func (r *RoomUserRepository) RemoveUserFromRoom(ctx context.Context, roomId, userId string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM room_users WHERE room_id = $1 AND user_id = $2", roomId, userId)
+	return err
+}
+
+// AddUsersToRoom adds all of the users or none of them. Existing memberships of the users are replaced with
+// plain ones, as the puts of the DynamoDB version do.
+func (r *RoomUserRepository) AddUsersToRoom(ctx context.Context, roomId string, userIDs []string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, userId := range userIDs {
+			if err := putRoomUser(ctx, tx, roomId, userId, model.Member); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *RoomUserRepository) UpdateRole(ctx context.Context, roomId, userId string, role model.RoomRole) error {
+	return updateRole(ctx, r.db, roomId, userId, role)
+}
+
+func updateRole(ctx context.Context, db execer, roomId, userId string, role model.RoomRole) error {
+	result, err := db.ExecContext(ctx, "UPDATE room_users SET role = $3 WHERE room_id = $1 AND user_id = $2", roomId, userId, role)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+userId)
+	}
+
+	return nil
+}
+
+func (r *RoomUserRepository) UpdateLastRead(ctx context.Context, roomId, userId, messageId string, readAt time.Time) (bool, error) {
+	// the marker only moves forward when two reads race
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE room_users SET last_read_message_id = $3, last_read_at = $4
+		WHERE room_id = $1 AND user_id = $2 AND (last_read_at IS NULL OR last_read_at < $4)`,
+		roomId, userId, messageId, dbTime(readAt))
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := result.RowsAffected()
+	return updated > 0, err
+}
+
+func (r *RoomUserRepository) TransferOwnership(ctx context.Context, roomId, currentOwnerId, newOwnerId string) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// the previous owner stays in the room as an admin
+		if err := updateRole(ctx, tx, roomId, currentOwnerId, model.Admin); err != nil {
+			return err
+		}
+		return updateRole(ctx, tx, roomId, newOwnerId, model.Owner)
+	})
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) repository.UserRepository {
+	return &UserRepository{
+		db,
+	}
+}
+
+const userColumns = "user_id, username, email, image_url, created_at, last_seen_at"
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*model.User, error) {
+	var user model.User
+	var lastSeenAt sql.NullTime
+	if err := row.Scan(&user.UserID, &user.Username, &user.Email, &user.ImageURL, &user.CreatedAt, &lastSeenAt); err != nil {
+		return nil, err
+	}
+	user.CreatedAt = user.CreatedAt.UTC()
+	if lastSeenAt.Valid {
+		lastSeen := lastSeenAt.Time.UTC()
+		user.LastSeenAt = &lastSeen
+	}
+	return &user, nil
+}
+
+// Create replaces the user when it already exists, as PutItem does.
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (`+userColumns+`) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			username = excluded.username, email = excluded.email, image_url = excluded.image_url, created_at = excluded.created_at,
+			last_seen_at = excluded.last_seen_at`,
+		user.UserID, user.Username, user.Email, user.ImageURL, dbTime(user.CreatedAt), dbNullTime(user.LastSeenAt))
+	return err
+}
+
+// GetMultiple pages through the users in ascending order of user ID; the key is the user ID of the last one.
+func (r *UserRepository) GetMultiple(ctx context.Context, lastEvaluatedKey string, limit int) ([]*model.User, string, error) {
+	if limit < 1 {
+		return nil, "", errInvalidLimit
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+userColumns+` FROM users
+		WHERE user_id > $1
+		ORDER BY user_id
+		LIMIT $2`,
+		lastEvaluatedKey, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	users, nextKey := trimPage(users, limit, func(user *model.User) string { return user.UserID })
+	return users, nextKey, nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, userId string) (*model.User, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE user_id = $1", userId)
+
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NewNotFoundErr("User", "UserID: "+userId)
+	}
+	return user, err
+}
+
+// BatchGetUsers leaves out the users that do not exist.
+func (r *UserRepository) BatchGetUsers(ctx context.Context, userIds []string) ([]*model.User, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+userColumns+" FROM users WHERE user_id = ANY($1) ORDER BY user_id", pq.Array(userIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *UserRepository) UpdateLastSeen(ctx context.Context, userId string, lastSeenAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE users SET last_seen_at = $2 WHERE user_id = $1", userId, dbTime(lastSeenAt))
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperror.NewNotFoundErr("User", "UserID: "+userId)
+	}
+	return nil
+}
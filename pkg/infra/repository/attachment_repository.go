@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+)
+
+type AttachmentRepositoryImpl struct {
+	db     *dynamodb.DynamoDB
+	dbName string
+}
+
+func NewAttachmentRepository(db *dynamodb.DynamoDB, tables schema.TableNames) repository.AttachmentRepository {
+	return &AttachmentRepositoryImpl{
+		db,
+		tables.Attachments,
+	}
+}
+
+func (ar *AttachmentRepositoryImpl) Create(ctx context.Context, attachment *model.Attachment) error {
+	item, err := dynamodbattribute.MarshalMap(attachment)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(ar.dbName),
+		Item:      item,
+	}
+
+	_, err = ar.db.PutItemWithContext(ctx, input)
+	return err
+}
+
+func (ar *AttachmentRepositoryImpl) GetByID(ctx context.Context, attachmentId string) (*model.Attachment, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(ar.dbName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"attachmentId": {
+				S: aws.String(attachmentId),
+			},
+		},
+	}
+
+	result, err := ar.db.GetItemWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Item) == 0 {
+		return nil, apperror.NewNotFoundErr("Attachment", "AttachmentID: "+attachmentId)
+	}
+
+	var attachment model.Attachment
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &attachment); err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (ar *AttachmentRepositoryImpl) MarkAttached(ctx context.Context, attachmentId, messageId string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(ar.dbName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"attachmentId": {
+				S: aws.String(attachmentId),
+			},
+		},
+		ExpressionAttributeNames: map[string]*string{
+			"#S": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":m": {
+				S: aws.String(messageId),
+			},
+			":a": {
+				S: aws.String(string(model.Attached)),
+			},
+			":p": {
+				S: aws.String(string(model.Pending)),
+			},
+		},
+		ConditionExpression: aws.String("#S = :p"),
+		UpdateExpression:    aws.String("SET messageId = :m, #S = :a"),
+	}
+
+	_, err := ar.db.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return apperror.NewNotFoundErr("Attachment", "pending AttachmentID: "+attachmentId)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (ar *AttachmentRepositoryImpl) GetPendingBefore(ctx context.Context, before time.Time, limit int) ([]*model.Attachment, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ar.dbName),
+		IndexName:              aws.String(schema.AttachmentStatusIndexName),
+		Limit:                  aws.Int64(int64(limit)),
+		KeyConditionExpression: aws.String("#S = :p and createdAt < :b"),
+		ExpressionAttributeNames: map[string]*string{
+			"#S": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":p": {
+				S: aws.String(string(model.Pending)),
+			},
+			":b": {
+				S: aws.String(before.Format(time.RFC3339Nano)),
+			},
+		},
+	}
+
+	result, err := ar.db.QueryWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []*model.Attachment
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &attachments); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (ar *AttachmentRepositoryImpl) Delete(ctx context.Context, attachmentId string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(ar.dbName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"attachmentId": {
+				S: aws.String(attachmentId),
+			},
+		},
+	}
+
+	_, err := ar.db.DeleteItemWithContext(ctx, input)
+	return err
+}
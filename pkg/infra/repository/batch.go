@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// maxBatchGetItems is the most items a BatchGetItem request can read.
+	maxBatchGetItems = 100
+	// maxBatchWriteItems is the most items a BatchWriteItem request can write.
+	maxBatchWriteItems = 25
+	// unprocessedRetryDelay is how long to wait before sending the keys or items a throttled batch left unprocessed.
+	unprocessedRetryDelay = 100 * time.Millisecond
+	// maxConcurrentQueries is how many queries of different partitions are run at once.
+	maxConcurrentQueries = 8
+)
+
+// batchGetItems reads the items with the keys from the table in batches, reading the keys DynamoDB leaves
+// unprocessed again until none are left. Keys must not repeat, and missing items are left out.
+func batchGetItems(ctx context.Context, db *dynamodb.DynamoDB, tableName string, keys []map[string]*dynamodb.AttributeValue) ([]map[string]*dynamodb.AttributeValue, error) {
+	var items []map[string]*dynamodb.AttributeValue
+	for start := 0; start < len(keys); start += maxBatchGetItems {
+		end := start + maxBatchGetItems
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		batch := map[string]*dynamodb.KeysAndAttributes{tableName: {Keys: keys[start:end]}}
+		for len(batch) > 0 {
+			output, err := db.BatchGetItemWithContext(ctx, &dynamodb.BatchGetItemInput{RequestItems: batch})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, output.Responses[tableName]...)
+			batch = output.UnprocessedKeys
+			if len(batch) > 0 {
+				time.Sleep(unprocessedRetryDelay)
+			}
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/repositorytest"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestDB connects to the DynamoDB Local at DYNAMODB_ENDPOINT. The tests recreate tables of their own prefix,
+// so the tables the API uses there are left alone.
+func openTestDB(t *testing.T) *dynamodb.DynamoDB {
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("DYNAMODB_ENDPOINT is not set")
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(schema.DefaultRegion),
+		Endpoint:    aws.String(endpoint),
+		Credentials: credentials.NewStaticCredentials("local", "local", ""),
+	})
+	require.NoError(t, err)
+
+	return dynamodb.New(sess)
+}
+
+// testTables are the tables the tests create, named apart from those of the API.
+var testTables = schema.NewTableNames("ConformanceTest-")
+
+// recreateTables drops the tables and migrates them again, so they are created from the schema the app uses.
+func recreateTables(t *testing.T, db *dynamodb.DynamoDB) {
+	ctx := context.Background()
+	require.NoError(t, schema.DropTables(ctx, db, testTables))
+	require.NoError(t, schema.Migrate(ctx, db, testTables))
+}
+
+func TestRepositories(t *testing.T) {
+	db := openTestDB(t)
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		recreateTables(t, db)
+		return repositorytest.Repositories{
+			Room:     NewRoomRepository(db, testTables),
+			RoomUser: NewRoomUserRepository(db, testTables),
+			User:     NewUserRepository(db, testTables),
+			Message:  NewMessageRepository(db, testTables),
+		}
+	})
+}
@@ -2,13 +2,17 @@ package repository
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/shunsukenagashima/chat-api/pkg/apperror"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
 )
 
 type MessageRepositoryImpl struct {
@@ -16,10 +20,10 @@ type MessageRepositoryImpl struct {
 	dbName string
 }
 
-func NewMessageRepository(db *dynamodb.DynamoDB) repository.MessageRepository {
+func NewMessageRepository(db *dynamodb.DynamoDB, tables schema.TableNames) repository.MessageRepository {
 	return &MessageRepositoryImpl{
 		db,
-		"Messages",
+		tables.Messages,
 	}
 }
 
@@ -28,7 +32,9 @@ func (mr *MessageRepositoryImpl) GetMessagesByRoomID(ctx context.Context, roomId
 		TableName:              aws.String(mr.dbName),
 		Limit:                  aws.Int64(int64(limit)),
 		KeyConditionExpression: aws.String("roomId = :r"),
-		ScanIndexForward:       aws.Bool(false),
+		// replies are read through their thread, so a page may hold fewer messages than the limit
+		FilterExpression: aws.String("attribute_not_exists(parentMessageId)"),
+		ScanIndexForward: aws.Bool(false),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":r": {
 				S: aws.String(roomId),
@@ -65,10 +71,176 @@ func (mr *MessageRepositoryImpl) GetMessagesByRoomID(ctx context.Context, roomId
 	return messages, nextKey, nil
 }
 
+func (mr *MessageRepositoryImpl) GetReplies(ctx context.Context, roomId, parentMessageId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(mr.dbName),
+		IndexName:              aws.String(schema.ParentMessageIndexName),
+		Limit:                  aws.Int64(int64(limit)),
+		KeyConditionExpression: aws.String("parentMessageId = :p"),
+		ScanIndexForward:       aws.Bool(true),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":p": {
+				S: aws.String(parentMessageId),
+			},
+		},
+	}
+
+	if lastEvaluatedKey != "" {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			"parentMessageId": {
+				S: aws.String(parentMessageId),
+			},
+			"roomId": {
+				S: aws.String(roomId),
+			},
+			"createdAt": {
+				S: aws.String(lastEvaluatedKey),
+			},
+		}
+	}
+
+	result, err := mr.db.QueryWithContext(ctx, input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var messages []*model.Message
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &messages); err != nil {
+		return nil, "", err
+	}
+
+	var nextKey string
+	if result.LastEvaluatedKey != nil {
+		nextKey = *result.LastEvaluatedKey["createdAt"].S
+	}
+
+	return messages, nextKey, nil
+}
+
+func (mr *MessageRepositoryImpl) GetMessagesSince(ctx context.Context, roomId string, since time.Time, limit int) ([]*model.Message, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(mr.dbName),
+		KeyConditionExpression: aws.String("roomId = :r and createdAt > :s"),
+		FilterExpression:       aws.String("attribute_not_exists(parentMessageId)"),
+		ScanIndexForward:       aws.Bool(false),
+		// A consistent read makes sure messages stored before the client was registered on the hub are returned.
+		ConsistentRead: aws.Bool(true),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":r": {
+				S: aws.String(roomId),
+			},
+			":s": {
+				S: aws.String(since.Format(time.RFC3339Nano)),
+			},
+		},
+	}
+
+	// the filter is applied after the items are read, so pages are read until enough messages are left
+	var messages []*model.Message
+	var pageErr error
+	err := mr.db.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var pageMessages []*model.Message
+		if pageErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageMessages); pageErr != nil {
+			return false
+		}
+		messages = append(messages, pageMessages...)
+		return len(messages) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pageErr != nil {
+		return nil, pageErr
+	}
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+// CountMessagesSince runs a query per room, as a count cannot span partitions, a few rooms at a time.
+func (mr *MessageRepositoryImpl) CountMessagesSince(ctx context.Context, userId string, since map[string]time.Time, limit int) (map[string]int, error) {
+	counts := make(map[string]int, len(since))
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	semaphore := make(chan struct{}, maxConcurrentQueries)
+	for roomId, roomSince := range since {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(roomId string, roomSince time.Time) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			count, err := mr.countRoomMessagesSince(ctx, roomId, userId, roomSince, limit)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			counts[roomId] = count
+		}(roomId, roomSince)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return counts, nil
+}
+
+func (mr *MessageRepositoryImpl) countRoomMessagesSince(ctx context.Context, roomId, userId string, since time.Time, limit int) (int, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(mr.dbName),
+		Select:                 aws.String(dynamodb.SelectCount),
+		KeyConditionExpression: aws.String("roomId = :r and createdAt > :s"),
+		FilterExpression:       aws.String("userId <> :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":r": {
+				S: aws.String(roomId),
+			},
+			":s": {
+				S: aws.String(since.Format(time.RFC3339Nano)),
+			},
+			":u": {
+				S: aws.String(userId),
+			},
+		},
+	}
+
+	count := 0
+	for {
+		result, err := mr.db.QueryWithContext(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+
+		count += int(aws.Int64Value(result.Count))
+		if count >= limit {
+			return limit, nil
+		}
+		if result.LastEvaluatedKey == nil {
+			return count, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
 func (mr *MessageRepositoryImpl) GetByID(ctx context.Context, roomId, messageId string) (*model.Message, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(mr.dbName),
-		IndexName:              aws.String("MessageIdIndex"),
+		IndexName:              aws.String(schema.MessageIDIndexName),
 		KeyConditionExpression: aws.String("roomId = :r and messageId = :m"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":r": {
@@ -86,7 +258,7 @@ func (mr *MessageRepositoryImpl) GetByID(ctx context.Context, roomId, messageId
 	}
 
 	if len(result.Items) == 0 {
-		return nil, apperror.NewNotFoundErr("Message", "Message'ID: "+messageId)
+		return nil, apperror.NewNotFoundErr("Message", "MessageID: "+messageId)
 	}
 
 	var message *model.Message
@@ -106,10 +278,15 @@ func (mr *MessageRepositoryImpl) Create(ctx context.Context, message *model.Mess
 	input := &dynamodb.PutItemInput{
 		TableName: aws.String(mr.dbName),
 		Item:      item,
+		// the creation time is the sort key, so a message created at the same time would be overwritten
+		ConditionExpression: aws.String("attribute_not_exists(createdAt)"),
 	}
 
-	_, err = mr.db.PutItem(input)
+	_, err = mr.db.PutItemWithContext(ctx, input)
 	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return apperror.NewAlreadyExistsErr("Message", "RoomID: "+message.RoomID+", CreatedAt: "+message.CreatedAt.Format(time.RFC3339Nano))
+		}
 		return err
 	}
 
@@ -117,21 +294,7 @@ func (mr *MessageRepositoryImpl) Create(ctx context.Context, message *model.Mess
 }
 
 func (mr *MessageRepositoryImpl) Update(ctx context.Context, roomId, messageId, newContent string) error {
-	queryInput := &dynamodb.QueryInput{
-		TableName:              aws.String(mr.dbName),
-		IndexName:              aws.String("MessageIdIndex"),
-		KeyConditionExpression: aws.String("roomId = :r and messageId = :m"),
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":r": {
-				S: aws.String(roomId),
-			},
-			":m": {
-				S: aws.String(messageId),
-			},
-		},
-	}
-
-	result, err := mr.db.Query(queryInput)
+	key, err := mr.keyByID(ctx, roomId, messageId)
 	if err != nil {
 		return err
 	}
@@ -143,7 +306,7 @@ func (mr *MessageRepositoryImpl) Update(ctx context.Context, roomId, messageId,
 			},
 		},
 		TableName:        aws.String(mr.dbName),
-		Key:              result.Items[0],
+		Key:              key,
 		ReturnValues:     aws.String("UPDATED_NEW"),
 		UpdateExpression: aws.String("set content = :c"),
 	}
@@ -157,10 +320,31 @@ func (mr *MessageRepositoryImpl) Update(ctx context.Context, roomId, messageId,
 }
 
 func (mr *MessageRepositoryImpl) Delete(ctx context.Context, roomId, messageId string) error {
+	key, err := mr.keyByID(ctx, roomId, messageId)
+	if err != nil {
+		return err
+	}
+
+	deleteInput := &dynamodb.DeleteItemInput{
+		TableName: aws.String(mr.dbName),
+		Key:       key,
+	}
+
+	_, err = mr.db.DeleteItem(deleteInput)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// keyByID looks up the primary key of a message by its ID, since messages are keyed by their creation time.
+func (mr *MessageRepositoryImpl) keyByID(ctx context.Context, roomId, messageId string) (map[string]*dynamodb.AttributeValue, error) {
 	queryInput := &dynamodb.QueryInput{
 		TableName:              aws.String(mr.dbName),
-		IndexName:              aws.String("MessageIdIndex"),
+		IndexName:              aws.String(schema.MessageIDIndexName),
 		KeyConditionExpression: aws.String("roomId = :r and messageId = :m"),
+		ProjectionExpression:   aws.String("roomId, createdAt"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":r": {
 				S: aws.String(roomId),
@@ -171,20 +355,96 @@ func (mr *MessageRepositoryImpl) Delete(ctx context.Context, roomId, messageId s
 		},
 	}
 
-	result, err := mr.db.Query(queryInput)
+	result, err := mr.db.QueryWithContext(ctx, queryInput)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	deleteInput := &dynamodb.DeleteItemInput{
+	if len(result.Items) == 0 {
+		return nil, apperror.NewNotFoundErr("Message", "MessageID: "+messageId)
+	}
+
+	return result.Items[0], nil
+}
+
+func (mr *MessageRepositoryImpl) AddReply(ctx context.Context, parent *model.Message, repliedAt time.Time) (*model.Message, error) {
+	key, err := messageKey(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.UpdateItemInput{
 		TableName: aws.String(mr.dbName),
-		Key:       result.Items[0],
+		Key:       key,
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {
+				N: aws.String("1"),
+			},
+			":t": {
+				S: aws.String(repliedAt.Format(time.RFC3339Nano)),
+			},
+		},
+		ConditionExpression: aws.String("attribute_exists(messageId)"),
+		UpdateExpression:    aws.String("ADD replyCount :one SET lastReplyAt = :t"),
+		ReturnValues:        aws.String(dynamodb.ReturnValueAllNew),
 	}
 
-	_, err = mr.db.DeleteItem(deleteInput)
+	result, err := mr.db.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, apperror.NewNotFoundErr("Message", "MessageID: "+parent.MessageID)
+		}
+		return nil, err
+	}
+
+	var updated model.Message
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (mr *MessageRepositoryImpl) RemoveReply(ctx context.Context, parent *model.Message) error {
+	key, err := messageKey(parent)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(mr.dbName),
+		Key:       key,
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {
+				N: aws.String("1"),
+			},
+			":d": {
+				N: aws.String("-1"),
+			},
+		},
+		// a parent deleted before its replies has nothing left to update
+		ConditionExpression: aws.String("attribute_exists(messageId) and replyCount >= :one"),
+		UpdateExpression:    aws.String("ADD replyCount :d"),
+	}
+
+	_, err = mr.db.UpdateItemWithContext(ctx, input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil
+	}
+	return err
+}
+
+// messageKey builds the primary key of a message the same way it was marshalled when the message was created.
+func messageKey(message *model.Message) (map[string]*dynamodb.AttributeValue, error) {
+	createdAt, err := dynamodbattribute.Marshal(message.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]*dynamodb.AttributeValue{
+		"roomId": {
+			S: aws.String(message.RoomID),
+		},
+		"createdAt": createdAt,
+	}, nil
 }
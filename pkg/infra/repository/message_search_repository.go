@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+// messageIndexMapping indexes only the fields messages are searched and filtered by.
+// The content is analyzed with the standard analyzer and its n-grams, so that Japanese text,
+// which has no spaces between words, can be found by any part of a word.
+const messageIndexMapping = `{
+	"settings": {
+		"analysis": {
+			"analyzer": {
+				"ngram": {
+					"tokenizer": "ngram",
+					"filter": ["lowercase"]
+				}
+			},
+			"tokenizer": {
+				"ngram": {
+					"type": "ngram",
+					"min_gram": 2,
+					"max_gram": 2,
+					"token_chars": ["letter", "digit"]
+				}
+			}
+		}
+	},
+	"mappings": {
+		"dynamic": false,
+		"properties": {
+			"messageId": {"type": "keyword"},
+			"roomId": {"type": "keyword"},
+			"userId": {"type": "keyword"},
+			"parentMessageId": {"type": "keyword"},
+			"createdAt": {"type": "date"},
+			"content": {
+				"type": "text",
+				"fields": {
+					"ngram": {"type": "text", "analyzer": "ngram"}
+				}
+			}
+		}
+	}
+}`
+
+// messageDocument is what is indexed of a message. Reactions, attachments and thread summaries change
+// without the message being reindexed, so they are read from the Messages table instead.
+type messageDocument struct {
+	MessageID       string    `json:"messageId"`
+	RoomID          string    `json:"roomId"`
+	UserID          string    `json:"userId"`
+	ParentMessageID string    `json:"parentMessageId,omitempty"`
+	Content         string    `json:"content"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type MessageSearchRepositoryImpl struct {
+	client    *elasticsearch.Client
+	indexName string
+}
+
+func NewMessageSearchRepository(client *elasticsearch.Client) repository.MessageSearchRepository {
+	return &MessageSearchRepositoryImpl{
+		client,
+		"messages",
+	}
+}
+
+// CreateMessageIndex creates the index of messages with its mapping unless it exists already.
+func CreateMessageIndex(ctx context.Context, client *elasticsearch.Client) error {
+	res, err := client.Indices.Exists([]string{"messages"}, client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	res, err = client.Indices.Create(
+		"messages",
+		client.Indices.Create.WithContext(ctx),
+		client.Indices.Create.WithBody(strings.NewReader(messageIndexMapping)),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return responseError(res)
+}
+
+func (mr *MessageSearchRepositoryImpl) Index(ctx context.Context, message *model.Message) error {
+	body, err := json.Marshal(newMessageDocument(message))
+	if err != nil {
+		return err
+	}
+
+	res, err := mr.client.Index(
+		mr.indexName,
+		bytes.NewReader(body),
+		mr.client.Index.WithContext(ctx),
+		mr.client.Index.WithDocumentID(message.MessageID),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return responseError(res)
+}
+
+func (mr *MessageSearchRepositoryImpl) BulkIndex(ctx context.Context, messages []*model.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, message := range messages {
+		action := map[string]interface{}{
+			"index": map[string]string{"_id": message.MessageID},
+		}
+		if err := encoder.Encode(action); err != nil {
+			return err
+		}
+		if err := encoder.Encode(newMessageDocument(message)); err != nil {
+			return err
+		}
+	}
+
+	res, err := mr.client.Bulk(
+		&body,
+		mr.client.Bulk.WithContext(ctx),
+		mr.client.Bulk.WithIndex(mr.indexName),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if err := responseError(res); err != nil {
+		return err
+	}
+
+	// a bulk request succeeds as a whole even when some of its items fail
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Errors {
+		return nil
+	}
+	for _, item := range result.Items {
+		for _, action := range item {
+			if len(action.Error) > 0 {
+				return fmt.Errorf("failed to index message %s: %s", action.ID, action.Error)
+			}
+		}
+	}
+	return nil
+}
+
+func (mr *MessageSearchRepositoryImpl) Delete(ctx context.Context, messageId string) error {
+	res, err := mr.client.Delete(
+		mr.indexName,
+		messageId,
+		mr.client.Delete.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return responseError(res)
+}
+
+func (mr *MessageSearchRepositoryImpl) Search(ctx context.Context, query string, roomIds []string, offset, limit int) ([]*model.MessageSearchHit, int, error) {
+	if len(roomIds) == 0 {
+		return []*model.MessageSearchHit{}, 0, nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"from": offset,
+		"size": limit,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"multi_match": map[string]interface{}{
+						"query":  query,
+						"fields": []string{"content^2", "content.ngram"},
+					},
+				},
+				"filter": map[string]interface{}{
+					"terms": map[string]interface{}{
+						"roomId": roomIds,
+					},
+				},
+			},
+		},
+		"sort": []interface{}{
+			"_score",
+			map[string]string{"createdAt": "desc"},
+		},
+		"highlight": map[string]interface{}{
+			"encoder":   "html",
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"fields": map[string]interface{}{
+				"content":       map[string]interface{}{},
+				"content.ngram": map[string]interface{}{},
+			},
+		},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res, err := mr.client.Search(
+		mr.client.Search.WithContext(ctx),
+		mr.client.Search.WithIndex(mr.indexName),
+		mr.client.Search.WithBody(bytes.NewReader(body)),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	if err := responseError(res); err != nil {
+		return nil, 0, err
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source    messageDocument     `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]*model.MessageSearchHit, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		// whole words are highlighted in content; the n-grams only when they are all that matched
+		highlights := hit.Highlight["content"]
+		if len(highlights) == 0 {
+			highlights = hit.Highlight["content.ngram"]
+		}
+		hits = append(hits, &model.MessageSearchHit{
+			Message: &model.Message{
+				MessageID:       hit.Source.MessageID,
+				RoomID:          hit.Source.RoomID,
+				UserID:          hit.Source.UserID,
+				ParentMessageID: hit.Source.ParentMessageID,
+				Content:         hit.Source.Content,
+				CreatedAt:       hit.Source.CreatedAt,
+			},
+			Highlights: highlights,
+		})
+	}
+
+	return hits, result.Hits.Total.Value, nil
+}
+
+func newMessageDocument(message *model.Message) *messageDocument {
+	return &messageDocument{
+		MessageID:       message.MessageID,
+		RoomID:          message.RoomID,
+		UserID:          message.UserID,
+		ParentMessageID: message.ParentMessageID,
+		Content:         message.Content,
+		CreatedAt:       message.CreatedAt,
+	}
+}
+
+func responseError(res *esapi.Response) error {
+	if !res.IsError() {
+		return nil
+	}
+	body, _ := io.ReadAll(res.Body)
+	return fmt.Errorf("elasticsearch responded with %s: %s", res.Status(), body)
+}
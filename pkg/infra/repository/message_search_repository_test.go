@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeElasticsearch serves the handler as an Elasticsearch node and returns a client of it.
+func newFakeElasticsearch(t *testing.T, handler http.HandlerFunc) *elasticsearch.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the client refuses to talk to servers that do not identify as Elasticsearch
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+	require.NoError(t, err)
+	return client
+}
+
+func TestMessageSearchRepository_Search(t *testing.T) {
+	createdAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var request map[string]interface{}
+	client := newFakeElasticsearch(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages/_search", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		io.WriteString(w, `{
+			"hits": {
+				"total": {"value": 2},
+				"hits": [
+					{
+						"_source": {"messageId": "1", "roomId": "1", "userId": "2", "content": "hello <b>world</b>", "createdAt": "2023-06-01T12:00:00Z"},
+						"highlight": {"content": ["<em>hello</em> &lt;b&gt;world&lt;/b&gt;"], "content.ngram": ["<em>he</em>llo"]}
+					},
+					{
+						"_source": {"messageId": "2", "roomId": "2", "userId": "2", "parentMessageId": "1", "content": "こんにちは", "createdAt": "2023-06-01T12:00:00Z"},
+						"highlight": {"content.ngram": ["<em>こん</em>にちは"]}
+					}
+				]
+			}
+		}`)
+	})
+	repo := NewMessageSearchRepository(client)
+
+	hits, total, err := repo.Search(context.Background(), "hello", []string{"1", "2"}, 10, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []*model.MessageSearchHit{
+		{
+			Message:    &model.Message{MessageID: "1", RoomID: "1", UserID: "2", Content: "hello <b>world</b>", CreatedAt: createdAt},
+			Highlights: []string{"<em>hello</em> &lt;b&gt;world&lt;/b&gt;"},
+		},
+		{
+			Message:    &model.Message{MessageID: "2", RoomID: "2", UserID: "2", ParentMessageID: "1", Content: "こんにちは", CreatedAt: createdAt},
+			Highlights: []string{"<em>こん</em>にちは"},
+		},
+	}, hits)
+
+	assert.Equal(t, float64(10), request["from"])
+	assert.Equal(t, float64(20), request["size"])
+	filter := request["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"]
+	assert.Equal(t, map[string]interface{}{"terms": map[string]interface{}{"roomId": []interface{}{"1", "2"}}}, filter)
+	assert.Equal(t, "html", request["highlight"].(map[string]interface{})["encoder"])
+}
+
+func TestMessageSearchRepository_SearchWithoutRooms(t *testing.T) {
+	client := newFakeElasticsearch(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+	repo := NewMessageSearchRepository(client)
+
+	hits, total, err := repo.Search(context.Background(), "hello", nil, 0, 20)
+
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+	assert.Equal(t, 0, total)
+}
+
+func TestMessageSearchRepository_BulkIndex(t *testing.T) {
+	testCases := []struct {
+		name        string
+		response    string
+		expectedErr string
+	}{
+		{
+			name:     "Success",
+			response: `{"errors": false, "items": [{"index": {"_id": "1", "status": 201}}, {"index": {"_id": "2", "status": 201}}]}`,
+		},
+		{
+			name:        "Item Failed",
+			response:    `{"errors": true, "items": [{"index": {"_id": "1", "status": 201}}, {"index": {"_id": "2", "status": 400, "error": {"type": "mapper_parsing_exception"}}}]}`,
+			expectedErr: `failed to index message 2: {"type": "mapper_parsing_exception"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var lines []string
+			client := newFakeElasticsearch(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/messages/_bulk", r.URL.Path)
+				body, _ := io.ReadAll(r.Body)
+				lines = strings.Split(strings.TrimSpace(string(body)), "\n")
+				io.WriteString(w, tc.response)
+			})
+			repo := NewMessageSearchRepository(client)
+
+			err := repo.BulkIndex(context.Background(), []*model.Message{
+				{MessageID: "1", RoomID: "1", Content: "hello", Reactions: []*model.ReactionCount{{Emoji: "👍", Count: 1}}},
+				{MessageID: "2", RoomID: "1", Content: "world"},
+			})
+
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			require.Len(t, lines, 4)
+			assert.JSONEq(t, `{"index": {"_id": "1"}}`, lines[0])
+			assert.JSONEq(t, `{"messageId": "1", "roomId": "1", "userId": "", "content": "hello", "createdAt": "0001-01-01T00:00:00Z"}`, lines[1])
+		})
+	}
+}
+
+func TestMessageSearchRepository_DeleteMissing(t *testing.T) {
+	client := newFakeElasticsearch(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/messages/_doc/1", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"result": "not_found"}`)
+	})
+	repo := NewMessageSearchRepository(client)
+
+	assert.NoError(t, repo.Delete(context.Background(), "1"))
+}
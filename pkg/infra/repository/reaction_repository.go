@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+)
+
+// The Reactions table is keyed by message. Each message has one item per user and emoji, and one item of counts
+// with a counter attribute per emoji, which is updated atomically, so reacting never rewrites the message or a list
+// of every reaction to it, and the counts of a page of messages are read with one batch.
+const (
+	reactionCountsKey   = "counts"
+	reactionCountPrefix = "count#"
+	reactionUserPrefix  = "user#"
+)
+
+type ReactionRepositoryImpl struct {
+	db     *dynamodb.DynamoDB
+	dbName string
+}
+
+func NewReactionRepository(db *dynamodb.DynamoDB, tables schema.TableNames) repository.ReactionRepository {
+	return &ReactionRepositoryImpl{
+		db,
+		tables.Reactions,
+	}
+}
+
+func (rr *ReactionRepositoryImpl) Add(ctx context.Context, reaction *model.Reaction) (int, bool, error) {
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Put: &dynamodb.Put{
+					TableName: aws.String(rr.dbName),
+					Item: map[string]*dynamodb.AttributeValue{
+						"messageId": {
+							S: aws.String(reaction.MessageID),
+						},
+						"reactionKey": {
+							S: aws.String(userReactionKey(reaction.UserID, reaction.Emoji)),
+						},
+						"userId": {
+							S: aws.String(reaction.UserID),
+						},
+						"emoji": {
+							S: aws.String(reaction.Emoji),
+						},
+						"createdAt": {
+							S: aws.String(reaction.CreatedAt.Format(time.RFC3339Nano)),
+						},
+					},
+					ConditionExpression: aws.String("attribute_not_exists(reactionKey)"),
+				},
+			},
+			rr.updateCount(reaction.MessageID, reaction.Emoji, 1),
+		},
+	}
+
+	return rr.write(ctx, input, reaction.MessageID, reaction.Emoji)
+}
+
+func (rr *ReactionRepositoryImpl) Remove(ctx context.Context, messageId, userId, emoji string) (int, bool, error) {
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Delete: &dynamodb.Delete{
+					TableName: aws.String(rr.dbName),
+					Key: map[string]*dynamodb.AttributeValue{
+						"messageId": {
+							S: aws.String(messageId),
+						},
+						"reactionKey": {
+							S: aws.String(userReactionKey(userId, emoji)),
+						},
+					},
+					ConditionExpression: aws.String("attribute_exists(reactionKey)"),
+				},
+			},
+			rr.updateCount(messageId, emoji, -1),
+		},
+	}
+
+	return rr.write(ctx, input, messageId, emoji)
+}
+
+// GetCounts reads the items of counts of the messages in batches.
+func (rr *ReactionRepositoryImpl) GetCounts(ctx context.Context, messageIds []string) (map[string][]*model.ReactionCount, error) {
+	// a batch must not ask for the same key twice
+	seen := make(map[string]bool, len(messageIds))
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(messageIds))
+	for _, messageId := range messageIds {
+		if seen[messageId] {
+			continue
+		}
+		seen[messageId] = true
+		keys = append(keys, reactionCountsItemKey(messageId))
+	}
+
+	items, err := batchGetItems(ctx, rr.db, rr.dbName, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string][]*model.ReactionCount)
+	for _, item := range items {
+		messageId := aws.StringValue(item["messageId"].S)
+		if messageCounts := reactionCounts(item); len(messageCounts) > 0 {
+			counts[messageId] = messageCounts
+		}
+	}
+
+	return counts, nil
+}
+
+// DeleteByMessageID deletes the reaction items and counters of the message in batches, writing the items
+// DynamoDB leaves unprocessed again until none are left.
+func (rr *ReactionRepositoryImpl) DeleteByMessageID(ctx context.Context, messageId string) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(rr.dbName),
+		KeyConditionExpression: aws.String("messageId = :m"),
+		ProjectionExpression:   aws.String("messageId, reactionKey"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":m": {
+				S: aws.String(messageId),
+			},
+		},
+	}
+
+	var requests []*dynamodb.WriteRequest
+	err := rr.db.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			requests = append(requests, &dynamodb.WriteRequest{
+				DeleteRequest: &dynamodb.DeleteRequest{Key: item},
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		batch := map[string][]*dynamodb.WriteRequest{rr.dbName: requests[start:end]}
+		for len(batch) > 0 {
+			output, err := rr.db.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{RequestItems: batch})
+			if err != nil {
+				return err
+			}
+			batch = output.UnprocessedItems
+			if len(batch) > 0 {
+				time.Sleep(unprocessedRetryDelay)
+			}
+		}
+	}
+
+	return nil
+}
+
+// write runs the transaction and reads the counter of the emoji back from the item of counts. A failed condition on the reaction item
+// means there is nothing to change, which is reported with false rather than an error.
+func (rr *ReactionRepositoryImpl) write(ctx context.Context, input *dynamodb.TransactWriteItemsInput, messageId, emoji string) (int, bool, error) {
+	changed := true
+	if _, err := rr.db.TransactWriteItemsWithContext(ctx, input); err != nil {
+		if !isConditionalCheckFailed(err) {
+			return 0, false, err
+		}
+		changed = false
+	}
+
+	result, err := rr.db.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(rr.dbName),
+		Key:                  reactionCountsItemKey(messageId),
+		ProjectionExpression: aws.String("#C"),
+		ExpressionAttributeNames: map[string]*string{
+			"#C": aws.String(reactionCountPrefix + emoji),
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return countAttribute(result.Item[reactionCountPrefix+emoji]), changed, nil
+}
+
+func (rr *ReactionRepositoryImpl) updateCount(messageId, emoji string, delta int) *dynamodb.TransactWriteItem {
+	return &dynamodb.TransactWriteItem{
+		Update: &dynamodb.Update{
+			TableName: aws.String(rr.dbName),
+			Key:       reactionCountsItemKey(messageId),
+			ExpressionAttributeNames: map[string]*string{
+				"#C": aws.String(reactionCountPrefix + emoji),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":d": {
+					N: aws.String(strconv.Itoa(delta)),
+				},
+			},
+			UpdateExpression: aws.String("ADD #C :d"),
+		},
+	}
+}
+
+func userReactionKey(userId, emoji string) string {
+	return reactionUserPrefix + userId + "#" + emoji
+}
+
+func reactionCountsItemKey(messageId string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"messageId": {
+			S: aws.String(messageId),
+		},
+		"reactionKey": {
+			S: aws.String(reactionCountsKey),
+		},
+	}
+}
+
+// reactionCounts returns the counts of an item of counts, ordered by emoji.
+func reactionCounts(item map[string]*dynamodb.AttributeValue) []*model.ReactionCount {
+	var counts []*model.ReactionCount
+	for name, value := range item {
+		if !strings.HasPrefix(name, reactionCountPrefix) {
+			continue
+		}
+		count := countAttribute(value)
+		// counters are left at zero when the last reaction with an emoji is removed
+		if count == 0 {
+			continue
+		}
+		counts = append(counts, &model.ReactionCount{
+			Emoji: strings.TrimPrefix(name, reactionCountPrefix),
+			Count: count,
+		})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Emoji < counts[j].Emoji })
+	return counts
+}
+
+func countAttribute(value *dynamodb.AttributeValue) int {
+	if value == nil {
+		return 0
+	}
+	count, err := strconv.Atoi(aws.StringValue(value.N))
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// isConditionalCheckFailed reports whether a transaction was canceled because a condition did not hold.
+func isConditionalCheckFailed(err error) bool {
+	canceledErr, ok := err.(*dynamodb.TransactionCanceledException)
+	if !ok {
+		return false
+	}
+	for _, reason := range canceledErr.CancellationReasons {
+		if aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
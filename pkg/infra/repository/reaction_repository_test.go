@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReactionCounts(t *testing.T) {
+	item := map[string]*dynamodb.AttributeValue{
+		"messageId":   {S: aws.String("1")},
+		"reactionKey": {S: aws.String(reactionCountsKey)},
+		"count#👍":     {N: aws.String("2")},
+		"count#🎉":     {N: aws.String("0")},
+		"count#😀":     {N: aws.String("1")},
+	}
+
+	assert.Equal(t, []*model.ReactionCount{
+		{Emoji: "👍", Count: 2},
+		{Emoji: "😀", Count: 1},
+	}, reactionCounts(item))
+	assert.Empty(t, reactionCounts(map[string]*dynamodb.AttributeValue{"messageId": {S: aws.String("1")}}))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/shunsukenagashima/chat-api/pkg/apperror"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
 )
 
 type RoomRepositoryImpl struct {
@@ -17,11 +18,11 @@ type RoomRepositoryImpl struct {
 	roomUserDBName string
 }
 
-func NewRoomRepository(db *dynamodb.DynamoDB) repository.RoomRepository {
+func NewRoomRepository(db *dynamodb.DynamoDB, tables schema.TableNames) repository.RoomRepository {
 	return &RoomRepositoryImpl{
 		db,
-		"Rooms",
-		"RoomUsers",
+		tables.Rooms,
+		tables.RoomUsers,
 	}
 }
 
@@ -53,10 +54,39 @@ func (r *RoomRepositoryImpl) GetByID(ctx context.Context, roomId string) (*model
 	return &room, nil
 }
 
+func (r *RoomRepositoryImpl) BatchGetByIDs(ctx context.Context, roomIds []string) ([]*model.Room, error) {
+	// a batch must not ask for the same key twice
+	seen := make(map[string]bool, len(roomIds))
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(roomIds))
+	for _, roomId := range roomIds {
+		if seen[roomId] {
+			continue
+		}
+		seen[roomId] = true
+		keys = append(keys, map[string]*dynamodb.AttributeValue{
+			"roomId": {
+				S: aws.String(roomId),
+			},
+		})
+	}
+
+	items, err := batchGetItems(ctx, r.db, r.roomDBName, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make([]*model.Room, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &rooms); err != nil {
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
 func (r *RoomRepositoryImpl) GetByName(ctx context.Context, name string) (*model.Room, error) {
 	input := &dynamodb.QueryInput{
 		TableName: aws.String(r.roomDBName),
-		IndexName: aws.String("NameIndex"),
+		IndexName: aws.String(schema.RoomNameIndexName),
 		KeyConditions: map[string]*dynamodb.Condition{
 			"name": {
 				ComparisonOperator: aws.String("EQ"),
@@ -91,22 +121,29 @@ func (r *RoomRepositoryImpl) GetByName(ctx context.Context, name string) (*model
 func (r *RoomRepositoryImpl) GetAllPublic(ctx context.Context) ([]*model.Room, error) {
 	input := &dynamodb.ScanInput{
 		TableName:        aws.String(r.roomDBName),
-		FilterExpression: aws.String("room_type = :public"),
+		FilterExpression: aws.String("roomType = :public"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":public": {
-				S: aws.String("Public"),
+				S: aws.String(string(model.Public)),
 			},
 		},
 	}
 
-	result, err := r.db.Scan(input)
+	var rooms []*model.Room
+	var pageErr error
+	err := r.db.ScanPagesWithContext(ctx, input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var pageRooms []*model.Room
+		if pageErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageRooms); pageErr != nil {
+			return false
+		}
+		rooms = append(rooms, pageRooms...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	var rooms []*model.Room
-	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &rooms); err != nil {
-		return nil, err
+	if pageErr != nil {
+		return nil, pageErr
 	}
 
 	return rooms, nil
@@ -136,6 +173,9 @@ func (r *RoomRepositoryImpl) CreateAndAddUser(ctx context.Context, room *model.R
 		"userId": {
 			S: aws.String(ownerId),
 		},
+		"role": {
+			S: aws.String(string(model.Owner)),
+		},
 	}
 
 	transactItems = append(transactItems, &dynamodb.TransactWriteItem{
@@ -154,7 +194,7 @@ func (r *RoomRepositoryImpl) CreateAndAddUser(ctx context.Context, room *model.R
 
 func (r *RoomRepositoryImpl) Delete(ctx context.Context, roomId string) error {
 	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String("Rooms"),
+		TableName: aws.String(r.roomDBName),
 		Key: map[string]*dynamodb.AttributeValue{
 			"roomId": {
 				S: aws.String(roomId),
@@ -2,12 +2,16 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
 )
 
 type RoomUserRepositoryImpl struct {
@@ -15,17 +19,17 @@ type RoomUserRepositoryImpl struct {
 	dbName string
 }
 
-func NewRoomUserRepository(db *dynamodb.DynamoDB) repository.RoomUserRepository {
+func NewRoomUserRepository(db *dynamodb.DynamoDB, tables schema.TableNames) repository.RoomUserRepository {
 	return &RoomUserRepositoryImpl{
 		db,
-		"RoomUsers",
+		tables.RoomUsers,
 	}
 }
 
 func (r *RoomUserRepositoryImpl) GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.RoomUser, error) {
 	input := &dynamodb.QueryInput{
-		TableName: aws.String("RoomUsers"),
-		IndexName: aws.String("UserIDIndex"),
+		TableName: aws.String(r.dbName),
+		IndexName: aws.String(schema.RoomUserUserIDIndexName),
 		KeyConditions: map[string]*dynamodb.Condition{
 			"userId": {
 				ComparisonOperator: aws.String("EQ"),
@@ -50,6 +54,36 @@ func (r *RoomUserRepositoryImpl) GetAllRoomsByUserID(ctx context.Context, userId
 	return roomUsers, nil
 }
 
+func (r *RoomUserRepositoryImpl) GetByRoomIDAndUserID(ctx context.Context, roomId, userId string) (*model.RoomUser, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.dbName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"roomId": {
+				S: aws.String(roomId),
+			},
+			"userId": {
+				S: aws.String(userId),
+			},
+		},
+	}
+
+	result, err := r.db.GetItemWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Item) == 0 {
+		return nil, apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+userId)
+	}
+
+	var roomUser model.RoomUser
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &roomUser); err != nil {
+		return nil, err
+	}
+
+	return &roomUser, nil
+}
+
 func (r *RoomUserRepositoryImpl) GetUsersByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.RoomUser, string, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(r.dbName),
@@ -122,6 +156,9 @@ func (r *RoomUserRepositoryImpl) AddUsersToRoom(ctx context.Context, roomId stri
 					"userId": {
 						S: aws.String(userId),
 					},
+					"role": {
+						S: aws.String(string(model.Member)),
+					},
 				},
 			},
 		}
@@ -132,3 +169,119 @@ func (r *RoomUserRepositoryImpl) AddUsersToRoom(ctx context.Context, roomId stri
 	_, err := r.db.TransactWriteItems(input)
 	return err
 }
+
+func (r *RoomUserRepositoryImpl) UpdateRole(ctx context.Context, roomId, userId string, role model.RoomRole) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.dbName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"roomId": {
+				S: aws.String(roomId),
+			},
+			"userId": {
+				S: aws.String(userId),
+			},
+		},
+		ExpressionAttributeNames: map[string]*string{
+			"#R": aws.String("role"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":r": {
+				S: aws.String(string(role)),
+			},
+		},
+		ConditionExpression: aws.String("attribute_exists(userId)"),
+		UpdateExpression:    aws.String("SET #R = :r"),
+	}
+
+	_, err := r.db.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+userId)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *RoomUserRepositoryImpl) UpdateLastRead(ctx context.Context, roomId, userId, messageId string, readAt time.Time) (bool, error) {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.dbName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"roomId": {
+				S: aws.String(roomId),
+			},
+			"userId": {
+				S: aws.String(userId),
+			},
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":m": {
+				S: aws.String(messageId),
+			},
+			":t": {
+				S: aws.String(readAt.Format(time.RFC3339Nano)),
+			},
+		},
+		// the marker only moves forward when two reads race
+		ConditionExpression: aws.String("attribute_exists(userId) and (attribute_not_exists(lastReadAt) or lastReadAt < :t)"),
+		UpdateExpression:    aws.String("SET lastReadMessageId = :m, lastReadAt = :t"),
+	}
+
+	_, err := r.db.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *RoomUserRepositoryImpl) TransferOwnership(ctx context.Context, roomId, currentOwnerId, newOwnerId string) error {
+	updateRole := func(userId string, role model.RoomRole) *dynamodb.TransactWriteItem {
+		return &dynamodb.TransactWriteItem{
+			Update: &dynamodb.Update{
+				TableName: aws.String(r.dbName),
+				Key: map[string]*dynamodb.AttributeValue{
+					"roomId": {
+						S: aws.String(roomId),
+					},
+					"userId": {
+						S: aws.String(userId),
+					},
+				},
+				ExpressionAttributeNames: map[string]*string{
+					"#R": aws.String("role"),
+				},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":r": {
+						S: aws.String(string(role)),
+					},
+				},
+				ConditionExpression: aws.String("attribute_exists(userId)"),
+				UpdateExpression:    aws.String("SET #R = :r"),
+			},
+		}
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			// the previous owner stays in the room as an admin
+			updateRole(currentOwnerId, model.Admin),
+			updateRole(newOwnerId, model.Owner),
+		},
+	}
+
+	_, err := r.db.TransactWriteItemsWithContext(ctx, input)
+	if canceledErr, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		// the reasons are in the order of the items, so they tell which of the users is not in the room
+		for i, userId := range []string{currentOwnerId, newOwnerId} {
+			if i < len(canceledErr.CancellationReasons) && aws.StringValue(canceledErr.CancellationReasons[i].Code) == "ConditionalCheckFailed" {
+				return apperror.NewNotFoundErr("RoomUser", "RoomID: "+roomId+", UserID: "+userId)
+			}
+		}
+	}
+	return err
+}
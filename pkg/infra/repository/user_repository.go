@@ -2,13 +2,16 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/shunsukenagashima/chat-api/pkg/apperror"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
 )
 
 type UserRepositoryImpl struct {
@@ -16,10 +19,10 @@ type UserRepositoryImpl struct {
 	dbName string
 }
 
-func NewUserRepository(db *dynamodb.DynamoDB) repository.UserRepository {
+func NewUserRepository(db *dynamodb.DynamoDB, tables schema.TableNames) repository.UserRepository {
 	return &UserRepositoryImpl{
 		db,
-		"Users",
+		tables.Users,
 	}
 }
 
@@ -137,3 +140,31 @@ func (r *UserRepositoryImpl) BatchGetUsers(ctx context.Context, userIds []string
 
 	return users, nil
 }
+
+func (r *UserRepositoryImpl) UpdateLastSeen(ctx context.Context, userId string, lastSeenAt time.Time) error {
+	value, err := dynamodbattribute.Marshal(lastSeenAt)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.dbName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"userId": {
+				S: aws.String(userId),
+			},
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":t": value,
+		},
+		// without the condition, updating a missing user would create an item with nothing but the time
+		ConditionExpression: aws.String("attribute_exists(userId)"),
+		UpdateExpression:    aws.String("SET lastSeenAt = :t"),
+	}
+
+	_, err = r.db.UpdateItemWithContext(ctx, input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return apperror.NewNotFoundErr("User", "UserID: "+userId)
+	}
+	return err
+}
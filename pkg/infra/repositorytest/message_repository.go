@@ -0,0 +1,323 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMessage(messageId, roomId, userId string, minutes int) *model.Message {
+	return &model.Message{
+		MessageID: messageId,
+		RoomID:    roomId,
+		UserID:    userId,
+		Content:   "content of " + messageId,
+		CreatedAt: at(minutes),
+	}
+}
+
+func newReply(messageId string, parent *model.Message, minutes int) *model.Message {
+	reply := newMessage(messageId, parent.RoomID, parent.UserID, minutes)
+	reply.ParentMessageID = parent.MessageID
+	return reply
+}
+
+func createMessages(t *testing.T, repos Repositories, messages ...*model.Message) {
+	t.Helper()
+
+	for _, message := range messages {
+		require.NoError(t, repos.Message.Create(context.Background(), message))
+	}
+}
+
+func messageID(message *model.Message) string {
+	return message.MessageID
+}
+
+func messageIDs(messages []*model.Message) []string {
+	ids := []string{}
+	for _, message := range messages {
+		ids = append(ids, message.MessageID)
+	}
+	return ids
+}
+
+func RunMessageRepository(t *testing.T, newRepositories NewRepositories) {
+	ctx := context.Background()
+
+	t.Run("Create and GetByID", func(t *testing.T) {
+		repos := newRepositories(t)
+		message := newMessage("m1", "room1", "alice", 0)
+		message.Attachments = []*model.Attachment{{
+			AttachmentID: "a1",
+			RoomID:       "room1",
+			UserID:       "alice",
+			MessageID:    "m1",
+			Name:         "photo.png",
+			Size:         1024,
+			ContentType:  "image/png",
+			ObjectKey:    "rooms/room1/a1/photo.png",
+			Status:       model.Attached,
+			CreatedAt:    at(0),
+		}}
+		reply := newReply("r1", message, 1)
+		createMessages(t, repos, message, reply)
+
+		got, err := repos.Message.GetByID(ctx, "room1", "m1")
+		require.NoError(t, err)
+		assert.Equal(t, message, got)
+
+		got, err = repos.Message.GetByID(ctx, "room1", "r1")
+		require.NoError(t, err)
+		assert.Equal(t, reply, got)
+	})
+
+	t.Run("GetByID of a missing message", func(t *testing.T) {
+		repos := newRepositories(t)
+		createMessages(t, repos, newMessage("m1", "room1", "alice", 0))
+
+		_, err := repos.Message.GetByID(ctx, "room2", "m1")
+		assertNotFound(t, err)
+		_, err = repos.Message.GetByID(ctx, "room1", "missing")
+		assertNotFound(t, err)
+	})
+
+	t.Run("GetMessagesByRoomID pages newest first without replies", func(t *testing.T) {
+		repos := newRepositories(t)
+		m2 := newMessage("m2", "room1", "alice", 2)
+		createMessages(t, repos,
+			newMessage("m1", "room1", "alice", 1),
+			m2,
+			newReply("r1", m2, 3),
+			newMessage("m3", "room1", "bob", 4),
+			newReply("r2", m2, 5),
+			newMessage("m4", "room1", "alice", 6),
+			newMessage("m5", "room1", "bob", 7),
+			newMessage("other", "room2", "alice", 8),
+		)
+
+		for _, limit := range []int{1, 2, 10} {
+			got := collectPages(t, limit, func(lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+				return repos.Message.GetMessagesByRoomID(ctx, "room1", lastEvaluatedKey, limit)
+			}, messageID)
+			assert.Equal(t, []string{"m5", "m4", "m3", "m2", "m1"}, got, "limit %d", limit)
+		}
+	})
+
+	t.Run("GetMessagesByRoomID of an empty room", func(t *testing.T) {
+		repos := newRepositories(t)
+
+		messages, nextKey, err := repos.Message.GetMessagesByRoomID(ctx, "room1", "", 10)
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+		assert.Empty(t, nextKey)
+	})
+
+	t.Run("GetReplies pages oldest first", func(t *testing.T) {
+		repos := newRepositories(t)
+		parent := newMessage("parent", "room1", "alice", 0)
+		other := newMessage("other", "room1", "alice", 1)
+		createMessages(t, repos,
+			parent,
+			other,
+			newReply("r1", parent, 2),
+			newReply("o1", other, 3),
+			newReply("r2", parent, 4),
+			newReply("r3", parent, 5),
+		)
+
+		for _, limit := range []int{1, 2, 10} {
+			got := collectPages(t, limit, func(lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+				return repos.Message.GetReplies(ctx, "room1", "parent", lastEvaluatedKey, limit)
+			}, messageID)
+			assert.Equal(t, []string{"r1", "r2", "r3"}, got, "limit %d", limit)
+		}
+	})
+
+	t.Run("GetMessagesSince returns the newest messages oldest first", func(t *testing.T) {
+		repos := newRepositories(t)
+		createMessages(t, repos,
+			newMessage("m1", "room1", "alice", 1),
+			newMessage("m2", "room1", "alice", 2),
+			newMessage("m3", "room1", "alice", 3),
+			newMessage("m4", "room1", "alice", 4),
+			newMessage("other", "room2", "alice", 5),
+		)
+
+		tests := []struct {
+			name        string
+			since       time.Time
+			limit       int
+			expectedIDs []string
+		}{
+			{name: "all after since", since: at(1), limit: 10, expectedIDs: []string{"m2", "m3", "m4"}},
+			{name: "limited to the newest", since: at(0), limit: 2, expectedIDs: []string{"m3", "m4"}},
+			{name: "nothing after since", since: at(4), limit: 10, expectedIDs: []string{}},
+		}
+
+		for _, tt := range tests {
+			messages, err := repos.Message.GetMessagesSince(ctx, "room1", tt.since, tt.limit)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, tt.expectedIDs, messageIDs(messages), tt.name)
+		}
+	})
+
+	t.Run("Create refuses a message at the time of another of the room", func(t *testing.T) {
+		repos := newRepositories(t)
+		createMessages(t, repos,
+			newMessage("m1", "room1", "alice", 1),
+			newMessage("other", "room2", "alice", 1),
+		)
+
+		err := repos.Message.Create(ctx, newMessage("m2", "room1", "bob", 1))
+		var alreadyExistsErr *apperror.AlreadyExistsErr
+		assert.ErrorAs(t, err, &alreadyExistsErr)
+
+		got, err := repos.Message.GetByID(ctx, "room1", "m1")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", got.UserID)
+		_, err = repos.Message.GetByID(ctx, "room1", "m2")
+		assertNotFound(t, err)
+	})
+
+	t.Run("GetMessagesSince leaves out replies", func(t *testing.T) {
+		repos := newRepositories(t)
+		parent := newMessage("m1", "room1", "alice", 1)
+		createMessages(t, repos,
+			parent,
+			newReply("r1", parent, 2),
+			newMessage("m2", "room1", "alice", 3),
+			newReply("r2", parent, 4),
+			newReply("r3", parent, 5),
+		)
+
+		messages, err := repos.Message.GetMessagesSince(ctx, "room1", at(0), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m1", "m2"}, messageIDs(messages))
+
+		// the limit counts messages, not the replies in between
+		messages, err = repos.Message.GetMessagesSince(ctx, "room1", at(0), 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m2"}, messageIDs(messages))
+	})
+
+	t.Run("CountMessagesSince counts the messages of others up to the limit", func(t *testing.T) {
+		repos := newRepositories(t)
+		createMessages(t, repos,
+			newMessage("m1", "room1", "alice", 1),
+			newMessage("m2", "room1", "bob", 2),
+			newMessage("m3", "room1", "alice", 3),
+			newMessage("m4", "room1", "alice", 4),
+			newMessage("other", "room2", "alice", 5),
+		)
+
+		tests := []struct {
+			name          string
+			userId        string
+			since         time.Time
+			limit         int
+			expectedCount int
+		}{
+			{name: "own messages are left out", userId: "bob", since: at(0), limit: 100, expectedCount: 3},
+			{name: "after since only", userId: "bob", since: at(3), limit: 100, expectedCount: 1},
+			{name: "capped at the limit", userId: "bob", since: at(0), limit: 2, expectedCount: 2},
+			{name: "nothing unread", userId: "alice", since: at(2), limit: 100, expectedCount: 0},
+		}
+
+		for _, tt := range tests {
+			counts, err := repos.Message.CountMessagesSince(ctx, tt.userId, map[string]time.Time{"room1": tt.since}, tt.limit)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, map[string]int{"room1": tt.expectedCount}, counts, tt.name)
+		}
+	})
+
+	t.Run("CountMessagesSince counts every room from its own time", func(t *testing.T) {
+		repos := newRepositories(t)
+		createMessages(t, repos,
+			newMessage("m1", "room1", "alice", 1),
+			newMessage("m2", "room1", "alice", 2),
+			newMessage("m3", "room2", "alice", 3),
+			newMessage("m4", "room2", "alice", 4),
+		)
+
+		counts, err := repos.Message.CountMessagesSince(ctx, "bob", map[string]time.Time{
+			"room1": at(0),
+			"room2": at(3),
+			"empty": at(0),
+		}, 100)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"room1": 2, "room2": 1, "empty": 0}, counts)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepositories(t)
+		message := newMessage("m1", "room1", "alice", 0)
+		createMessages(t, repos, message)
+
+		require.NoError(t, repos.Message.Update(ctx, "room1", "m1", "edited"))
+
+		got, err := repos.Message.GetByID(ctx, "room1", "m1")
+		require.NoError(t, err)
+		message.Content = "edited"
+		assert.Equal(t, message, got)
+
+		err = repos.Message.Update(ctx, "room1", "missing", "edited")
+		assertNotFound(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := newRepositories(t)
+		createMessages(t, repos, newMessage("m1", "room1", "alice", 0), newMessage("m2", "room1", "alice", 1))
+
+		require.NoError(t, repos.Message.Delete(ctx, "room1", "m1"))
+
+		_, err := repos.Message.GetByID(ctx, "room1", "m1")
+		assertNotFound(t, err)
+		_, err = repos.Message.GetByID(ctx, "room1", "m2")
+		assert.NoError(t, err)
+
+		err = repos.Message.Delete(ctx, "room1", "m1")
+		assertNotFound(t, err)
+	})
+
+	t.Run("AddReply and RemoveReply keep the thread summary", func(t *testing.T) {
+		repos := newRepositories(t)
+		parent := newMessage("parent", "room1", "alice", 0)
+		createMessages(t, repos, parent)
+
+		updated, err := repos.Message.AddReply(ctx, parent, at(1))
+		require.NoError(t, err)
+		assert.Equal(t, 1, updated.ReplyCount)
+
+		updated, err = repos.Message.AddReply(ctx, parent, at(2))
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated.ReplyCount)
+		require.NotNil(t, updated.LastReplyAt)
+		assert.True(t, at(2).Equal(*updated.LastReplyAt), "LastReplyAt is %v", updated.LastReplyAt)
+
+		require.NoError(t, repos.Message.RemoveReply(ctx, parent))
+		require.NoError(t, repos.Message.RemoveReply(ctx, parent))
+		// the count does not go below zero
+		require.NoError(t, repos.Message.RemoveReply(ctx, parent))
+
+		got, err := repos.Message.GetByID(ctx, "room1", "parent")
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.ReplyCount)
+		assert.Equal(t, parent.Content, got.Content)
+	})
+
+	t.Run("AddReply to a missing message", func(t *testing.T) {
+		repos := newRepositories(t)
+
+		_, err := repos.Message.AddReply(ctx, newMessage("missing", "room1", "alice", 0), at(1))
+		assertNotFound(t, err)
+
+		// a parent deleted before its replies has nothing left to update
+		assert.NoError(t, repos.Message.RemoveReply(ctx, newMessage("missing", "room1", "alice", 0)))
+	})
+}
@@ -0,0 +1,89 @@
+// Package repositorytest is the contract every implementation of the room, room user, user and message
+// repositories is held to. Implementations run it from their own tests:
+//
+//	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories { ... })
+//
+// The contract leaves out what differs between storages on purpose: the order of users and of the rooms of a
+// user, and whether the last page of a paginated query comes with a key that leads to an empty page.
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Repositories are implementations sharing one storage, so that each sees what the others wrote.
+type Repositories struct {
+	Room     repository.RoomRepository
+	RoomUser repository.RoomUserRepository
+	User     repository.UserRepository
+	Message  repository.MessageRepository
+}
+
+// NewRepositories returns repositories over an empty storage. It is called at the start of every test.
+type NewRepositories func(t *testing.T) Repositories
+
+// Run runs the contract of every repository.
+func Run(t *testing.T, newRepositories NewRepositories) {
+	t.Run("RoomRepository", func(t *testing.T) {
+		RunRoomRepository(t, newRepositories)
+	})
+	t.Run("RoomUserRepository", func(t *testing.T) {
+		RunRoomUserRepository(t, newRepositories)
+	})
+	t.Run("UserRepository", func(t *testing.T) {
+		RunUserRepository(t, newRepositories)
+	})
+	t.Run("MessageRepository", func(t *testing.T) {
+		RunMessageRepository(t, newRepositories)
+	})
+}
+
+// baseTime is whole seconds, so that it survives storages keeping less than nanoseconds.
+var baseTime = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return baseTime.Add(time.Duration(minutes) * time.Minute)
+}
+
+func assertNotFound(t *testing.T, err error) {
+	t.Helper()
+
+	var notFoundErr *apperror.NotFoundErr
+	assert.True(t, errors.As(err, &notFoundErr), "expected a NotFoundErr, got %v", err)
+}
+
+// maxPages stops paging through results that never end, which would otherwise hang the test.
+const maxPages = 100
+
+// collectPages pages through a query with the limit and returns the IDs of everything it returned, in order.
+func collectPages[T any](t *testing.T, limit int, query func(lastEvaluatedKey string, limit int) ([]T, string, error), id func(T) string) []string {
+	t.Helper()
+
+	ids := []string{}
+	lastEvaluatedKey := ""
+	for i := 0; i < maxPages; i++ {
+		items, nextKey, err := query(lastEvaluatedKey, limit)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(items), limit)
+
+		for _, item := range items {
+			ids = append(ids, id(item))
+		}
+
+		if nextKey == "" {
+			return ids
+		}
+		require.NotEqual(t, lastEvaluatedKey, nextKey, "the key did not move forward")
+		lastEvaluatedKey = nextKey
+	}
+
+	t.Fatalf("paging did not end after %d pages", maxPages)
+	return nil
+}
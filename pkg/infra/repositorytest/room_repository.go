@@ -0,0 +1,113 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func RunRoomRepository(t *testing.T, newRepositories NewRepositories) {
+	ctx := context.Background()
+
+	t.Run("CreateAndAddUser adds the owner", func(t *testing.T) {
+		repos := newRepositories(t)
+		room := &model.Room{RoomID: "room1", Name: "general", RoomType: model.Private}
+
+		require.NoError(t, repos.Room.CreateAndAddUser(ctx, room, "alice"))
+
+		got, err := repos.Room.GetByID(ctx, "room1")
+		require.NoError(t, err)
+		assert.Equal(t, room, got)
+
+		owner, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, model.Owner, owner.Role)
+	})
+
+	t.Run("GetByID of a missing room", func(t *testing.T) {
+		repos := newRepositories(t)
+
+		_, err := repos.Room.GetByID(ctx, "missing")
+		assertNotFound(t, err)
+	})
+
+	t.Run("BatchGetByIDs leaves out missing rooms", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.Room.CreateAndAddUser(ctx, &model.Room{RoomID: "room1", Name: "general", RoomType: model.Public}, "alice"))
+		require.NoError(t, repos.Room.CreateAndAddUser(ctx, &model.Room{RoomID: "room2", Name: "random", RoomType: model.Private}, "alice"))
+
+		rooms, err := repos.Room.BatchGetByIDs(ctx, []string{"room2", "missing", "room1", "room2"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*model.Room{
+			{RoomID: "room1", Name: "general", RoomType: model.Public},
+			{RoomID: "room2", Name: "random", RoomType: model.Private},
+		}, rooms)
+
+		rooms, err = repos.Room.BatchGetByIDs(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, rooms)
+	})
+
+	t.Run("GetByName", func(t *testing.T) {
+		repos := newRepositories(t)
+		room := &model.Room{RoomID: "room1", Name: "general", RoomType: model.Public}
+		require.NoError(t, repos.Room.CreateAndAddUser(ctx, room, "alice"))
+
+		got, err := repos.Room.GetByName(ctx, "general")
+		require.NoError(t, err)
+		assert.Equal(t, room, got)
+
+		got, err = repos.Room.GetByName(ctx, "missing")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("GetAllPublic leaves out private rooms", func(t *testing.T) {
+		repos := newRepositories(t)
+		for _, room := range []*model.Room{
+			{RoomID: "public1", Name: "public1", RoomType: model.Public},
+			{RoomID: "private", Name: "private", RoomType: model.Private},
+			{RoomID: "public2", Name: "public2", RoomType: model.Public},
+		} {
+			require.NoError(t, repos.Room.CreateAndAddUser(ctx, room, "alice"))
+		}
+
+		rooms, err := repos.Room.GetAllPublic(ctx)
+		require.NoError(t, err)
+
+		ids := []string{}
+		for _, room := range rooms {
+			ids = append(ids, room.RoomID)
+		}
+		assert.ElementsMatch(t, []string{"public1", "public2"}, ids)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.Room.CreateAndAddUser(ctx, &model.Room{RoomID: "room1", Name: "general", RoomType: model.Public}, "alice"))
+
+		updated := &model.Room{RoomID: "room1", Name: "random", RoomType: model.Private}
+		require.NoError(t, repos.Room.Update(ctx, updated))
+
+		got, err := repos.Room.GetByID(ctx, "room1")
+		require.NoError(t, err)
+		assert.Equal(t, updated, got)
+
+		got, err = repos.Room.GetByName(ctx, "general")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.Room.CreateAndAddUser(ctx, &model.Room{RoomID: "room1", Name: "general", RoomType: model.Public}, "alice"))
+
+		require.NoError(t, repos.Room.Delete(ctx, "room1"))
+
+		_, err := repos.Room.GetByID(ctx, "room1")
+		assertNotFound(t, err)
+	})
+}
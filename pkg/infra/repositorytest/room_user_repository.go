@@ -0,0 +1,158 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func RunRoomUserRepository(t *testing.T, newRepositories NewRepositories) {
+	ctx := context.Background()
+
+	t.Run("AddUsersToRoom adds members", func(t *testing.T) {
+		repos := newRepositories(t)
+
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room1", []string{"alice", "bob"}))
+
+		for _, userId := range []string{"alice", "bob"} {
+			roomUser, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", userId)
+			require.NoError(t, err)
+			assert.Equal(t, "room1", roomUser.RoomID)
+			assert.Equal(t, userId, roomUser.UserID)
+			assert.Equal(t, model.Member, roomUser.Role)
+			assert.Empty(t, roomUser.LastReadMessageID)
+			assert.True(t, roomUser.LastReadAt.IsZero())
+		}
+	})
+
+	t.Run("GetByRoomIDAndUserID of a non-member", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room1", []string{"alice"}))
+
+		_, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "bob")
+		assertNotFound(t, err)
+	})
+
+	t.Run("GetAllRoomsByUserID", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room1", []string{"alice", "bob"}))
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room2", []string{"alice"}))
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room3", []string{"bob"}))
+
+		roomUsers, err := repos.RoomUser.GetAllRoomsByUserID(ctx, "alice")
+		require.NoError(t, err)
+
+		roomIds := []string{}
+		for _, roomUser := range roomUsers {
+			assert.Equal(t, "alice", roomUser.UserID)
+			roomIds = append(roomIds, roomUser.RoomID)
+		}
+		assert.ElementsMatch(t, []string{"room1", "room2"}, roomIds)
+	})
+
+	t.Run("GetUsersByRoomID pages in descending order of user ID", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room1", []string{"u3", "u1", "u5", "u2", "u4"}))
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room2", []string{"u6"}))
+
+		got := collectPages(t, 2, func(lastEvaluatedKey string, limit int) ([]*model.RoomUser, string, error) {
+			return repos.RoomUser.GetUsersByRoomID(ctx, "room1", lastEvaluatedKey, limit)
+		}, func(roomUser *model.RoomUser) string {
+			return roomUser.UserID
+		})
+		assert.Equal(t, []string{"u5", "u4", "u3", "u2", "u1"}, got)
+	})
+
+	t.Run("RemoveUserFromRoom", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room1", []string{"alice", "bob"}))
+
+		require.NoError(t, repos.RoomUser.RemoveUserFromRoom(ctx, "room1", "bob"))
+
+		_, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "bob")
+		assertNotFound(t, err)
+		_, err = repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "alice")
+		assert.NoError(t, err)
+	})
+
+	t.Run("UpdateRole", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room1", []string{"alice"}))
+
+		require.NoError(t, repos.RoomUser.UpdateRole(ctx, "room1", "alice", model.Admin))
+
+		roomUser, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, model.Admin, roomUser.Role)
+
+		err = repos.RoomUser.UpdateRole(ctx, "room1", "bob", model.Admin)
+		assertNotFound(t, err)
+	})
+
+	t.Run("UpdateLastRead only moves forward", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room1", []string{"bob"}))
+
+		tests := []struct {
+			name            string
+			userId          string
+			messageId       string
+			minutes         int
+			expectedUpdated bool
+		}{
+			{name: "first read", userId: "bob", messageId: "m2", minutes: 2, expectedUpdated: true},
+			{name: "older message", userId: "bob", messageId: "m1", minutes: 1},
+			{name: "same message", userId: "bob", messageId: "m2", minutes: 2},
+			{name: "newer message", userId: "bob", messageId: "m3", minutes: 3, expectedUpdated: true},
+			{name: "non-member", userId: "carol", messageId: "m4", minutes: 4},
+		}
+
+		for _, tt := range tests {
+			updated, err := repos.RoomUser.UpdateLastRead(ctx, "room1", tt.userId, tt.messageId, at(tt.minutes))
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, tt.expectedUpdated, updated, tt.name)
+		}
+
+		roomUser, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "bob")
+		require.NoError(t, err)
+		assert.Equal(t, "m3", roomUser.LastReadMessageID)
+		assert.True(t, at(3).Equal(roomUser.LastReadAt), "LastReadAt is %v", roomUser.LastReadAt)
+
+		_, err = repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "carol")
+		assertNotFound(t, err)
+	})
+
+	t.Run("TransferOwnership", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.Room.CreateAndAddUser(ctx, &model.Room{RoomID: "room1", Name: "general", RoomType: model.Public}, "alice"))
+		require.NoError(t, repos.RoomUser.AddUsersToRoom(ctx, "room1", []string{"bob"}))
+
+		require.NoError(t, repos.RoomUser.TransferOwnership(ctx, "room1", "alice", "bob"))
+
+		alice, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, model.Admin, alice.Role)
+
+		bob, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "bob")
+		require.NoError(t, err)
+		assert.Equal(t, model.Owner, bob.Role)
+	})
+
+	t.Run("TransferOwnership to a non-member changes nothing", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.Room.CreateAndAddUser(ctx, &model.Room{RoomID: "room1", Name: "general", RoomType: model.Public}, "alice"))
+
+		err := repos.RoomUser.TransferOwnership(ctx, "room1", "alice", "carol")
+		assertNotFound(t, err)
+
+		alice, err := repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, model.Owner, alice.Role)
+
+		_, err = repos.RoomUser.GetByRoomIDAndUserID(ctx, "room1", "carol")
+		assertNotFound(t, err)
+	})
+}
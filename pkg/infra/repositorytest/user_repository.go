@@ -0,0 +1,91 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUser(userId string) *model.User {
+	return &model.User{
+		UserID:    userId,
+		Username:  userId,
+		Email:     userId + "@example.com",
+		ImageURL:  "https://example.com/" + userId + ".png",
+		CreatedAt: baseTime,
+	}
+}
+
+func RunUserRepository(t *testing.T, newRepositories NewRepositories) {
+	ctx := context.Background()
+
+	t.Run("Create and GetByID", func(t *testing.T) {
+		repos := newRepositories(t)
+		user := newUser("alice")
+
+		require.NoError(t, repos.User.Create(ctx, user))
+
+		got, err := repos.User.GetByID(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, user, got)
+	})
+
+	t.Run("GetByID of a missing user", func(t *testing.T) {
+		repos := newRepositories(t)
+
+		_, err := repos.User.GetByID(ctx, "missing")
+		assertNotFound(t, err)
+	})
+
+	t.Run("GetMultiple pages through every user once", func(t *testing.T) {
+		repos := newRepositories(t)
+		userIds := []string{"u1", "u2", "u3", "u4", "u5"}
+		for _, userId := range userIds {
+			require.NoError(t, repos.User.Create(ctx, newUser(userId)))
+		}
+
+		got := collectPages(t, 2, func(lastEvaluatedKey string, limit int) ([]*model.User, string, error) {
+			return repos.User.GetMultiple(ctx, lastEvaluatedKey, limit)
+		}, func(user *model.User) string {
+			return user.UserID
+		})
+		assert.ElementsMatch(t, userIds, got)
+	})
+
+	t.Run("UpdateLastSeen records the time on the user", func(t *testing.T) {
+		repos := newRepositories(t)
+		require.NoError(t, repos.User.Create(ctx, newUser("alice")))
+
+		lastSeenAt := baseTime.Add(time.Hour)
+		require.NoError(t, repos.User.UpdateLastSeen(ctx, "alice", lastSeenAt))
+
+		got, err := repos.User.GetByID(ctx, "alice")
+		require.NoError(t, err)
+		expected := newUser("alice")
+		expected.LastSeenAt = &lastSeenAt
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("UpdateLastSeen of a missing user", func(t *testing.T) {
+		repos := newRepositories(t)
+
+		assertNotFound(t, repos.User.UpdateLastSeen(ctx, "missing", baseTime))
+		_, err := repos.User.GetByID(ctx, "missing")
+		assertNotFound(t, err)
+	})
+
+	t.Run("BatchGetUsers leaves out missing users", func(t *testing.T) {
+		repos := newRepositories(t)
+		for _, userId := range []string{"alice", "bob", "carol"} {
+			require.NoError(t, repos.User.Create(ctx, newUser(userId)))
+		}
+
+		users, err := repos.User.BatchGetUsers(ctx, []string{"alice", "carol", "missing"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*model.User{newUser("alice"), newUser("carol")}, users)
+	})
+}
@@ -0,0 +1,32 @@
+package schema
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// DefaultRegion is the region of the tables when none is given.
+const DefaultRegion = "ap-northeast-1"
+
+// NewClient returns a client of DynamoDB at the endpoint, such as DynamoDB Local, or of AWS when the endpoint is
+// empty. An empty region means DefaultRegion.
+func NewClient(endpoint, region string) (*dynamodb.DynamoDB, error) {
+	if region == "" {
+		region = DefaultRegion
+	}
+
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamodb.New(sess), nil
+}
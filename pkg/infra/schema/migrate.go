@@ -0,0 +1,297 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// MigrationsTableName is the table recording the versions of the migrations that have been applied,
+// before it is prefixed.
+const MigrationsTableName = "SchemaMigrations"
+
+// Migration is one change to the schema. Up must be idempotent, since a migration interrupted before its
+// version was recorded runs again.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *dynamodb.DynamoDB, tables TableNames) error
+}
+
+// Migrations are applied in order of version. Changes to the schema are appended here; versions that
+// have been released are never edited.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create the users, rooms, room users, messages, reactions and attachments tables",
+		Up: func(ctx context.Context, db *dynamodb.DynamoDB, tables TableNames) error {
+			return ensureTables(ctx, db,
+				UsersTable(tables.Users),
+				RoomsTable(tables.Rooms),
+				RoomUsersTable(tables.RoomUsers),
+				MessagesTable(tables.Messages),
+				ReactionsTable(tables.Reactions),
+				AttachmentsTable(tables.Attachments),
+			)
+		},
+	},
+}
+
+// indexPollInterval is how often an index being created is checked for being active.
+var indexPollInterval = 2 * time.Second
+
+// Migrate applies the migrations that have not been applied yet and records their versions.
+// Running it again is a no-op.
+func Migrate(ctx context.Context, db *dynamodb.DynamoDB, tables TableNames) error {
+	if err := EnsureTable(ctx, db, migrationsTable(tables.Migrations)); err != nil {
+		return fmt.Errorf("failed to create the migrations table: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, db, tables.Migrations)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range Migrations {
+		if applied[migration.Version] {
+			continue
+		}
+
+		log.Printf("Applying migration %d: %s", migration.Version, migration.Description)
+		if err := migration.Up(ctx, db, tables); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
+		}
+		if err := recordVersion(ctx, db, tables.Migrations, migration); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// EnsureTable creates the table if it does not exist, and adds the global secondary indexes of the definition
+// the table lacks. Nothing is ever removed. Local secondary indexes can only be created with the table, so a
+// missing one is an error.
+func EnsureTable(ctx context.Context, db *dynamodb.DynamoDB, definition *dynamodb.CreateTableInput) error {
+	tableName := aws.StringValue(definition.TableName)
+
+	described, err := db.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: definition.TableName})
+	if isResourceNotFound(err) {
+		log.Printf("Creating table %s", tableName)
+		if _, err := db.CreateTableWithContext(ctx, definition); err != nil {
+			return err
+		}
+		return db.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{TableName: definition.TableName})
+	}
+	if err != nil {
+		return err
+	}
+
+	indexes, err := missingIndexes(definition, described.Table)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		log.Printf("Creating index %s on table %s", aws.StringValue(index.IndexName), tableName)
+		// DynamoDB creates one global secondary index per update
+		_, err := db.UpdateTableWithContext(ctx, &dynamodb.UpdateTableInput{
+			TableName:            definition.TableName,
+			AttributeDefinitions: indexAttributes(definition, index),
+			GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+				{
+					Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+						IndexName:             index.IndexName,
+						KeySchema:             index.KeySchema,
+						Projection:            index.Projection,
+						ProvisionedThroughput: index.ProvisionedThroughput,
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if err := waitUntilIndexActive(ctx, db, definition.TableName, index.IndexName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DropTables deletes every table of the schema, including the record of the migrations.
+func DropTables(ctx context.Context, db *dynamodb.DynamoDB, tables TableNames) error {
+	for _, table := range append(Tables(tables), migrationsTable(tables.Migrations)) {
+		_, err := db.DeleteTableWithContext(ctx, &dynamodb.DeleteTableInput{TableName: table.TableName})
+		if isResourceNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := db.WaitUntilTableNotExistsWithContext(ctx, &dynamodb.DescribeTableInput{TableName: table.TableName}); err != nil {
+			return err
+		}
+		log.Printf("Table %s deleted", aws.StringValue(table.TableName))
+	}
+
+	return nil
+}
+
+// missingIndexes returns the global secondary indexes of the definition the table lacks.
+func missingIndexes(definition *dynamodb.CreateTableInput, table *dynamodb.TableDescription) ([]*dynamodb.GlobalSecondaryIndex, error) {
+	existing := map[string]bool{}
+	for _, index := range table.LocalSecondaryIndexes {
+		existing[aws.StringValue(index.IndexName)] = true
+	}
+	for _, index := range table.GlobalSecondaryIndexes {
+		existing[aws.StringValue(index.IndexName)] = true
+	}
+
+	for _, index := range definition.LocalSecondaryIndexes {
+		if !existing[aws.StringValue(index.IndexName)] {
+			return nil, fmt.Errorf("table %s lacks the local secondary index %s, which can only be created with the table; the table has to be recreated",
+				aws.StringValue(definition.TableName), aws.StringValue(index.IndexName))
+		}
+	}
+
+	payPerRequest := table.BillingModeSummary != nil && aws.StringValue(table.BillingModeSummary.BillingMode) == dynamodb.BillingModePayPerRequest
+
+	var missing []*dynamodb.GlobalSecondaryIndex
+	for _, index := range definition.GlobalSecondaryIndexes {
+		if existing[aws.StringValue(index.IndexName)] {
+			continue
+		}
+		if payPerRequest {
+			// on-demand tables reject a provisioned throughput
+			index = &dynamodb.GlobalSecondaryIndex{
+				IndexName:  index.IndexName,
+				KeySchema:  index.KeySchema,
+				Projection: index.Projection,
+			}
+		}
+		missing = append(missing, index)
+	}
+
+	return missing, nil
+}
+
+// indexAttributes returns the definitions of the attributes the index is keyed by.
+func indexAttributes(definition *dynamodb.CreateTableInput, index *dynamodb.GlobalSecondaryIndex) []*dynamodb.AttributeDefinition {
+	var attributes []*dynamodb.AttributeDefinition
+	for _, key := range index.KeySchema {
+		for _, attribute := range definition.AttributeDefinitions {
+			if aws.StringValue(attribute.AttributeName) == aws.StringValue(key.AttributeName) {
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	return attributes
+}
+
+func waitUntilIndexActive(ctx context.Context, db *dynamodb.DynamoDB, tableName, indexName *string) error {
+	ticker := time.NewTicker(indexPollInterval)
+	defer ticker.Stop()
+
+	for {
+		described, err := db.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: tableName})
+		if err != nil {
+			return err
+		}
+		for _, index := range described.Table.GlobalSecondaryIndexes {
+			if aws.StringValue(index.IndexName) == aws.StringValue(indexName) && aws.StringValue(index.IndexStatus) == dynamodb.IndexStatusActive {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func migrationsTable(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("version"),
+				AttributeType: aws.String("N"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("version"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		TableName:   aws.String(name),
+	}
+}
+
+func appliedVersions(ctx context.Context, db *dynamodb.DynamoDB, tableName string) (map[int]bool, error) {
+	applied := map[int]bool{}
+	var parseErr error
+	err := db.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName:      aws.String(tableName),
+		ConsistentRead: aws.Bool(true),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			var version int
+			if version, parseErr = strconv.Atoi(aws.StringValue(item["version"].N)); parseErr != nil {
+				return false
+			}
+			applied[version] = true
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	return applied, nil
+}
+
+func recordVersion(ctx context.Context, db *dynamodb.DynamoDB, tableName string, migration Migration) error {
+	_, err := db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item: map[string]*dynamodb.AttributeValue{
+			"version": {
+				N: aws.String(strconv.Itoa(migration.Version)),
+			},
+			"description": {
+				S: aws.String(migration.Description),
+			},
+			"appliedAt": {
+				S: aws.String(time.Now().UTC().Format(time.RFC3339Nano)),
+			},
+		},
+	})
+	return err
+}
+
+func ensureTables(ctx context.Context, db *dynamodb.DynamoDB, definitions ...*dynamodb.CreateTableInput) error {
+	for _, definition := range definitions {
+		if err := EnsureTable(ctx, db, definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isResourceNotFound(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == dynamodb.ErrCodeResourceNotFoundException
+}
@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTableNames(t *testing.T) {
+	tables := NewTableNames("staging-")
+	assert.Equal(t, "staging-Messages", tables.Messages)
+	assert.Equal(t, "staging-SchemaMigrations", tables.Migrations)
+
+	for _, table := range Tables(tables) {
+		assert.True(t, strings.HasPrefix(aws.StringValue(table.TableName), "staging-"), aws.StringValue(table.TableName))
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	require.NotEmpty(t, Migrations)
+	for i, migration := range Migrations {
+		assert.Equal(t, i+1, migration.Version, "versions are numbered from 1 without gaps")
+		assert.NotEmpty(t, migration.Description)
+		assert.NotNil(t, migration.Up)
+	}
+}
+
+func TestMissingIndexes(t *testing.T) {
+	indexNames := func(indexes []*dynamodb.GlobalSecondaryIndex) []string {
+		var names []string
+		for _, index := range indexes {
+			names = append(names, aws.StringValue(index.IndexName))
+		}
+		return names
+	}
+
+	tests := []struct {
+		name               string
+		definition         *dynamodb.CreateTableInput
+		table              *dynamodb.TableDescription
+		expectedIndexNames []string
+		expectedErr        bool
+	}{
+		{
+			name:       "nothing is missing",
+			definition: MessagesTable(MessagesTableName),
+			table: &dynamodb.TableDescription{
+				LocalSecondaryIndexes:  []*dynamodb.LocalSecondaryIndexDescription{{IndexName: aws.String(MessageIDIndexName)}},
+				GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{{IndexName: aws.String(ParentMessageIndexName)}},
+			},
+		},
+		{
+			name:       "a global secondary index is missing",
+			definition: MessagesTable(MessagesTableName),
+			table: &dynamodb.TableDescription{
+				LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndexDescription{{IndexName: aws.String(MessageIDIndexName)}},
+			},
+			expectedIndexNames: []string{ParentMessageIndexName},
+		},
+		{
+			name:        "a local secondary index is missing",
+			definition:  MessagesTable(MessagesTableName),
+			table:       &dynamodb.TableDescription{},
+			expectedErr: true,
+		},
+		{
+			name:       "indexes the definition does not have are left alone",
+			definition: UsersTable(UsersTableName),
+			table: &dynamodb.TableDescription{
+				GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{{IndexName: aws.String("EmailIndex")}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexes, err := missingIndexes(tt.definition, tt.table)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIndexNames, indexNames(indexes))
+		})
+	}
+}
+
+func TestMissingIndexes_PayPerRequest(t *testing.T) {
+	indexes, err := missingIndexes(RoomsTable(RoomsTableName), &dynamodb.TableDescription{
+		BillingModeSummary: &dynamodb.BillingModeSummary{BillingMode: aws.String(dynamodb.BillingModePayPerRequest)},
+	})
+	require.NoError(t, err)
+	require.Len(t, indexes, 1)
+	assert.Nil(t, indexes[0].ProvisionedThroughput)
+}
+
+// TestMigrate runs against the DynamoDB Local at DYNAMODB_ENDPOINT, on tables of its own prefix.
+func TestMigrate(t *testing.T) {
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("DYNAMODB_ENDPOINT is not set")
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(DefaultRegion),
+		Endpoint:    aws.String(endpoint),
+		Credentials: credentials.NewStaticCredentials("local", "local", ""),
+	})
+	require.NoError(t, err)
+	db := dynamodb.New(sess)
+	ctx := context.Background()
+
+	tables := NewTableNames("MigrateTest-")
+	require.NoError(t, DropTables(ctx, db, tables))
+
+	// a table created before its index was added to the schema
+	rooms := RoomsTable(tables.Rooms)
+	rooms.GlobalSecondaryIndexes = nil
+	_, err = db.CreateTable(rooms)
+	require.NoError(t, err)
+
+	require.NoError(t, Migrate(ctx, db, tables))
+	// migrating again must be a no-op
+	require.NoError(t, Migrate(ctx, db, tables))
+
+	for _, table := range Tables(tables) {
+		described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: table.TableName})
+		require.NoError(t, err)
+		indexes, err := missingIndexes(table, described.Table)
+		require.NoError(t, err)
+		assert.Empty(t, indexes, aws.StringValue(table.TableName))
+	}
+
+	applied, err := appliedVersions(ctx, db, tables.Migrations)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(Migrations))
+}
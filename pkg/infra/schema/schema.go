@@ -0,0 +1,354 @@
+// Package schema declares the DynamoDB tables of the API. The repositories read the names of the tables and
+// their indexes from here, and the migrate command creates the tables from the same definitions.
+package schema
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// The names of the tables before they are prefixed.
+const (
+	UsersTableName       = "Users"
+	RoomsTableName       = "Rooms"
+	RoomUsersTableName   = "RoomUsers"
+	MessagesTableName    = "Messages"
+	ReactionsTableName   = "Reactions"
+	AttachmentsTableName = "Attachments"
+)
+
+const (
+	// RoomNameIndexName finds a room by its name.
+	RoomNameIndexName = "NameIndex"
+	// RoomUserUserIDIndexName finds the rooms of a user.
+	RoomUserUserIDIndexName = "UserIDIndex"
+	// MessageIDIndexName finds a message by its ID within its room.
+	MessageIDIndexName = "MessageIdIndex"
+	// ParentMessageIndexName finds the replies to a message.
+	ParentMessageIndexName = "ParentMessageIndex"
+	// AttachmentStatusIndexName finds the attachments in a status, oldest first.
+	AttachmentStatusIndexName = "StatusIndex"
+)
+
+// TableNames are the names of the tables, which differ between environments sharing an AWS account by a prefix.
+type TableNames struct {
+	Users       string
+	Rooms       string
+	RoomUsers   string
+	Messages    string
+	Reactions   string
+	Attachments string
+	Migrations  string
+}
+
+// NewTableNames prefixes the name of every table with prefix, such as "staging-".
+func NewTableNames(prefix string) TableNames {
+	return TableNames{
+		Users:       prefix + UsersTableName,
+		Rooms:       prefix + RoomsTableName,
+		RoomUsers:   prefix + RoomUsersTableName,
+		Messages:    prefix + MessagesTableName,
+		Reactions:   prefix + ReactionsTableName,
+		Attachments: prefix + AttachmentsTableName,
+		Migrations:  prefix + MigrationsTableName,
+	}
+}
+
+// Tables returns the definitions of every table the API uses.
+func Tables(tables TableNames) []*dynamodb.CreateTableInput {
+	return []*dynamodb.CreateTableInput{
+		UsersTable(tables.Users),
+		RoomsTable(tables.Rooms),
+		RoomUsersTable(tables.RoomUsers),
+		MessagesTable(tables.Messages),
+		ReactionsTable(tables.Reactions),
+		AttachmentsTable(tables.Attachments),
+	}
+}
+
+// UsersTable returns the definition of the Users table under the name.
+func UsersTable(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("userId"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("userId"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+		TableName: aws.String(name),
+	}
+}
+
+// RoomsTable returns the definition of the Rooms table under the name.
+func RoomsTable(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("roomId"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("name"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("roomId"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(RoomNameIndexName),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("name"),
+						KeyType:       aws.String("HASH"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(10),
+					WriteCapacityUnits: aws.Int64(10),
+				},
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+		TableName: aws.String(name),
+	}
+}
+
+// RoomUsersTable returns the definition of the RoomUsers table under the name.
+func RoomUsersTable(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("roomId"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("userId"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("roomId"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("userId"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(RoomUserUserIDIndexName),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("userId"),
+						KeyType:       aws.String("HASH"),
+					},
+					{
+						AttributeName: aws.String("roomId"),
+						KeyType:       aws.String("RANGE"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(10),
+					WriteCapacityUnits: aws.Int64(10),
+				},
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+		TableName: aws.String(name),
+	}
+}
+
+// MessagesTable returns the definition of the Messages table under the name.
+func MessagesTable(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("roomId"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("createdAt"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("messageId"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("parentMessageId"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("roomId"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("createdAt"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		// a message is looked up by its ID within the room
+		LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndex{
+			{
+				IndexName: aws.String(MessageIDIndexName),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("roomId"),
+						KeyType:       aws.String("HASH"),
+					},
+					{
+						AttributeName: aws.String("messageId"),
+						KeyType:       aws.String("RANGE"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+			},
+		},
+		// replies are looked up by the message starting their thread
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(ParentMessageIndexName),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("parentMessageId"),
+						KeyType:       aws.String("HASH"),
+					},
+					{
+						AttributeName: aws.String("createdAt"),
+						KeyType:       aws.String("RANGE"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(10),
+					WriteCapacityUnits: aws.Int64(10),
+				},
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+		TableName: aws.String(name),
+	}
+}
+
+// ReactionsTable returns the definition of the Reactions table under the name.
+func ReactionsTable(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("messageId"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("reactionKey"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("messageId"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("reactionKey"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+		TableName: aws.String(name),
+	}
+}
+
+// AttachmentsTable returns the definition of the Attachments table under the name.
+func AttachmentsTable(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("attachmentId"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("status"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("createdAt"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("attachmentId"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		// finds the uploads that were never attached, oldest first, so that they can be cleaned up
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(AttachmentStatusIndexName),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("status"),
+						KeyType:       aws.String("HASH"),
+					},
+					{
+						AttributeName: aws.String("createdAt"),
+						KeyType:       aws.String("RANGE"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(10),
+					WriteCapacityUnits: aws.Int64(10),
+				},
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+		TableName: aws.String(name),
+	}
+}
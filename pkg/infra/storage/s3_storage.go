@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"mime"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+)
+
+type S3Storage struct {
+	client *s3.S3
+	bucket string
+}
+
+func NewS3Storage(client *s3.S3, bucket string) repository.AttachmentStorage {
+	return &S3Storage{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+func (s *S3Storage) PresignUpload(ctx context.Context, objectKey, contentType string, size int64, expiry time.Duration) (string, error) {
+	req, _ := s.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(objectKey),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	})
+	req.SetContext(ctx)
+	return req.Presign(expiry)
+}
+
+func (s *S3Storage) PresignDownload(ctx context.Context, objectKey, fileName string, expiry time.Duration) (string, error) {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": fileName})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket:                     aws.String(s.bucket),
+		Key:                        aws.String(objectKey),
+		ResponseContentDisposition: aws.String(disposition),
+	})
+	req.SetContext(ctx)
+	return req.Presign(expiry)
+}
+
+func (s *S3Storage) Stat(ctx context.Context, objectKey string) (*model.ObjectInfo, error) {
+	result, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		// HEAD responses have no body, so a missing object comes back as a bare NotFound.
+		if aerr, ok := err.(awserr.Error); ok && (aerr.Code() == "NotFound" || aerr.Code() == s3.ErrCodeNoSuchKey) {
+			return nil, apperror.NewNotFoundErr("Object", "Key: "+objectKey)
+		}
+		return nil, err
+	}
+
+	return &model.ObjectInfo{
+		Size:        aws.Int64Value(result.ContentLength),
+		ContentType: aws.StringValue(result.ContentType),
+	}, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, objectKey string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+	})
+	return err
+}
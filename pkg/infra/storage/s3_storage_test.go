@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/google/uuid"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLocalStackStorage returns a storage on a fresh bucket of the LocalStack at LOCALSTACK_ENDPOINT
+// (e.g. "http://localhost:4566"), skipping the test when it is not set.
+func newLocalStackStorage(t *testing.T) *S3Storage {
+	endpoint := os.Getenv("LOCALSTACK_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("LOCALSTACK_ENDPOINT is not set")
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("ap-northeast-1"),
+		Endpoint:         aws.String(endpoint),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("dummy", "dummy", ""),
+	})
+	require.NoError(t, err)
+
+	client := s3.New(sess)
+	bucket := "test-" + uuid.New().String()
+	_, err = client.CreateBucket(&s3.CreateBucketInput{
+		Bucket: aws.String(bucket),
+		CreateBucketConfiguration: &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String("ap-northeast-1"),
+		},
+	})
+	require.NoError(t, err)
+
+	return NewS3Storage(client, bucket).(*S3Storage)
+}
+
+func TestS3Storage(t *testing.T) {
+	storage := newLocalStackStorage(t)
+	ctx := context.Background()
+	key := "rooms/1/1/hello.txt"
+	content := "hello"
+
+	_, err := storage.Stat(ctx, key)
+	var notFoundErr *apperror.NotFoundErr
+	assert.True(t, errors.As(err, &notFoundErr))
+
+	uploadURL, err := storage.PresignUpload(ctx, key, "text/plain", int64(len(content)), time.Minute)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPut, uploadURL, strings.NewReader(content))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "text/plain")
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	info, err := storage.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+
+	downloadURL, err := storage.PresignDownload(ctx, key, "hello.txt", time.Minute)
+	require.NoError(t, err)
+
+	response, err = http.Get(downloadURL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, string(body))
+	assert.Equal(t, `attachment; filename=hello.txt`, response.Header.Get("Content-Disposition"))
+
+	require.NoError(t, storage.Delete(ctx, key))
+	_, err = storage.Stat(ctx, key)
+	assert.True(t, errors.As(err, &notFoundErr))
+}
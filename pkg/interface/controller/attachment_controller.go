@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/validation"
+)
+
+type AttachmentController struct {
+	attachmentUsecase usecase.AttachmentUsecase
+	validator         *validation.Validator
+}
+
+func NewAttachmentController(attachmentUsecase usecase.AttachmentUsecase, validator *validation.Validator) *AttachmentController {
+	return &AttachmentController{
+		attachmentUsecase,
+		validator,
+	}
+}
+
+func (ac *AttachmentController) CreateUpload(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+
+	var req struct {
+		Name        string `json:"name" validate:"required,max=255"`
+		ContentType string `json:"contentType" validate:"required"`
+		Size        int64  `json:"size" validate:"required,gt=0"`
+	}
+
+	if err := ac.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	upload, err := ac.attachmentUsecase.CreateUpload(ctx.Request.Context(), roomId, req.Name, req.ContentType, req.Size)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"result": upload})
+}
+
+func (ac *AttachmentController) GetDownload(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+	attachmentId := ctx.Param("attachmentId")
+
+	download, err := ac.attachmentUsecase.GetDownload(ctx.Request.Context(), roomId, attachmentId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"result": download})
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := newTestValidator()
+
+	upload := &model.AttachmentUpload{
+		Attachment: &model.Attachment{AttachmentID: "1", RoomID: "1", Name: "cat.png", Size: 1024, ContentType: "image/png", Status: model.Pending},
+		UploadURL:  "https://upload",
+	}
+
+	testCases := []struct {
+		name         string
+		body         string
+		mockErr      error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			body:         `{"name":"cat.png","contentType":"image/png","size":1024}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Missing Size",
+			body:         `{"name":"cat.png","contentType":"image/png"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Too Large",
+			body:         `{"name":"cat.png","contentType":"image/png","size":1024}`,
+			mockErr:      apperror.NewBadRequestErr("Attachment", "Size: 1024 exceeds the limit of 512 bytes"),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Not Member",
+			body:         `{"name":"cat.png","contentType":"image/png","size":1024}`,
+			mockErr:      apperror.NewForbiddenErr("Room", "RoomID: 1"),
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.AttachmentUsecase)
+			mockUsecase.On("CreateUpload", mock.Anything, "1", "cat.png", "image/png", int64(1024)).Return(upload, tc.mockErr)
+
+			params := gin.Params{{Key: "roomId", Value: "1"}}
+			_, ctx, response := prepareRequestAndContext(http.MethodPost, "rooms/1/attachments", params, strings.NewReader(tc.body))
+
+			ac := NewAttachmentController(mockUsecase, validator)
+			serve(ctx, ac.CreateUpload)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			if tc.expectedCode == http.StatusCreated {
+				var result struct {
+					Result *model.AttachmentUpload `json:"result"`
+				}
+				if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, upload, result.Result)
+			}
+			if tc.mockErr == nil && tc.expectedCode == http.StatusBadRequest {
+				mockUsecase.AssertNotCalled(t, "CreateUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetDownload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		mockReturn   *model.AttachmentDownload
+		mockErr      error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			mockReturn:   &model.AttachmentDownload{DownloadURL: "https://download"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Not Member",
+			mockErr:      apperror.NewForbiddenErr("Room", "RoomID: 1"),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Unknown Attachment",
+			mockErr:      apperror.NewNotFoundErr("Attachment", "AttachmentID: 2"),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.AttachmentUsecase)
+			mockUsecase.On("GetDownload", mock.Anything, "1", "2").Return(tc.mockReturn, tc.mockErr)
+
+			params := gin.Params{{Key: "roomId", Value: "1"}, {Key: "attachmentId", Value: "2"}}
+			_, ctx, response := prepareRequestAndContext(http.MethodGet, "rooms/1/attachments/2", params, nil)
+
+			ac := NewAttachmentController(mockUsecase, newTestValidator())
+			serve(ctx, ac.GetDownload)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			if tc.mockErr == nil {
+				var result struct {
+					Result *model.AttachmentDownload `json:"result"`
+				}
+				if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, tc.mockReturn, result.Result)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package controller
 
 type Controllers struct {
-	HelloController    *HelloController
-	RoomController     *RoomController
-	WSController       *WSController
-	UserController     *UserController
-	RoomUserController *RoomUserController
-	MessageController  *MessageController
+	HelloController      *HelloController
+	RoomController       *RoomController
+	WSController         *WSController
+	UserController       *UserController
+	RoomUserController   *RoomUserController
+	MessageController    *MessageController
+	PresenceController   *PresenceController
+	ReactionController   *ReactionController
+	AttachmentController *AttachmentController
 }
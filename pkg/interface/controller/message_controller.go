@@ -5,17 +5,19 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/validation"
 )
 
 type MessageController struct {
 	messageUsecase usecase.MessageUsecase
-	validator      *validator.Validate
+	validator      *validation.Validator
 }
 
-func NewMessageController(messageUsecase usecase.MessageUsecase, validator *validator.Validate) *MessageController {
+func NewMessageController(messageUsecase usecase.MessageUsecase, validator *validation.Validator) *MessageController {
 	return &MessageController{
 		messageUsecase,
 		validator,
@@ -29,12 +31,38 @@ func (mc *MessageController) GetMessagesByRoomID(ctx *gin.Context) {
 
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.NewValidationErr("Query", "limit: "+limit))
+		return
 	}
 
 	result, nextKey, err := mc.messageUsecase.GetMessagesByRoomID(ctx.Request.Context(), roomId, lastEvaluatedKey, limitInt)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"result":  result,
+		"nextKey": nextKey,
+	})
+}
+
+func (mc *MessageController) GetReplies(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+	messageId := ctx.Param("messageId")
+	lastEvaluatedKey := ctx.Query("lastEvaluatedKey")
+	limit := ctx.DefaultQuery("limit", "20")
+
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		ctx.Error(apperror.NewValidationErr("Query", "limit: "+limit))
+		return
+	}
+
+	result, nextKey, err := mc.messageUsecase.GetReplies(ctx.Request.Context(), roomId, messageId, lastEvaluatedKey, limitInt)
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -46,29 +74,41 @@ func (mc *MessageController) GetMessagesByRoomID(ctx *gin.Context) {
 func (mc *MessageController) CreateMessage(ctx *gin.Context) {
 	roomId := ctx.Param("roomId")
 
+	userId, ok := authctx.UserID(ctx.Request.Context())
+	if !ok {
+		ctx.Error(apperror.NewUnauthenticatedErr("User", "is not verified"))
+		return
+	}
+
 	var req struct {
-		UserID  string `json:"userId" validate:"required"`
-		Content string `json:"content" validate:"required,min=1"`
+		Content         string   `json:"content"`
+		ParentMessageID string   `json:"parentMessageId"`
+		AttachmentIDs   []string `json:"attachmentIds" validate:"dive,required"`
 	}
 
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := mc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := mc.validator.Struct(req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// a message can be only attachments, but not empty
+	if req.Content == "" && len(req.AttachmentIDs) == 0 {
+		ctx.Error(apperror.NewValidationErr("Message", "content or attachmentIds is required"))
 		return
 	}
 
 	message := &model.Message{
-		RoomID:  roomId,
-		UserID:  req.UserID,
-		Content: req.Content,
+		RoomID:          roomId,
+		UserID:          userId,
+		Content:         req.Content,
+		ParentMessageID: req.ParentMessageID,
+	}
+	for _, attachmentId := range req.AttachmentIDs {
+		message.Attachments = append(message.Attachments, &model.Attachment{AttachmentID: attachmentId})
 	}
 
 	if err := mc.messageUsecase.CreateMessage(ctx.Request.Context(), message); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -83,18 +123,13 @@ func (mc *MessageController) UpdateMessage(ctx *gin.Context) {
 		Content string `json:"content" validate:"required,min=1"`
 	}
 
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if err := mc.validator.Struct(req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := mc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
 		return
 	}
 
 	if err := mc.messageUsecase.UpdateMessage(ctx.Request.Context(), roomId, messageId, req.Content); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -106,9 +141,61 @@ func (mc *MessageController) DeleteMessage(ctx *gin.Context) {
 	messageId := ctx.Param("messageId")
 
 	if err := mc.messageUsecase.DeleteMessage(ctx.Request.Context(), roomId, messageId); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"result": "message deleted successfully"})
 }
+
+func (mc *MessageController) MarkAsRead(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+
+	var req struct {
+		MessageID string `json:"messageId" validate:"required"`
+	}
+
+	if err := mc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := mc.messageUsecase.MarkAsRead(ctx.Request.Context(), roomId, req.MessageID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"result": "read marker updated successfully"})
+}
+
+func (mc *MessageController) SearchMessages(ctx *gin.Context) {
+	query := ctx.Query("q")
+	if query == "" {
+		ctx.Error(apperror.NewValidationErr("Query", "q is required"))
+		return
+	}
+	roomId := ctx.Query("roomId")
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil {
+		ctx.Error(apperror.NewValidationErr("Query", "limit: "+ctx.Query("limit")))
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil {
+		ctx.Error(apperror.NewValidationErr("Query", "offset: "+ctx.Query("offset")))
+		return
+	}
+
+	result, total, err := mc.messageUsecase.SearchMessages(ctx.Request.Context(), query, roomId, offset, limit)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"result": result,
+		"total":  total,
+	})
+}
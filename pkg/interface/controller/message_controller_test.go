@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
 	"github.com/shunsukenagashima/chat-api/pkg/clock"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
@@ -46,7 +48,7 @@ func TestGetMessagesByRoomID(t *testing.T) {
 
 	mc := NewMessageController(mockUsecase, validator)
 
-	mc.GetMessagesByRoomID(ctx)
+	serve(ctx, mc.GetMessagesByRoomID)
 
 	assert.Equal(t, http.StatusOK, response.Code)
 	mockUsecase.AssertExpectations(t)
@@ -62,61 +64,114 @@ func TestGetMessagesByRoomID(t *testing.T) {
 	assert.Equal(t, mockMessages, result.Result)
 }
 
+func TestGetMessagesByRoomID_InvalidLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(mocks.MessageUsecase)
+
+	request, _ := http.NewRequest(http.MethodGet, "messages?limit=many", nil)
+	response := httptest.NewRecorder()
+
+	ctx, _ := gin.CreateTestContext(response)
+	ctx.Params = gin.Params{{Key: "roomId", Value: "1"}}
+	ctx.Request = request
+
+	mc := NewMessageController(mockUsecase, newTestValidator())
+
+	serve(ctx, mc.GetMessagesByRoomID)
+
+	// the handler stops at the invalid limit instead of going on to answer a second time
+	checkErrorResponse(t, apperror.NewValidationErr("Query", "limit: many"), response)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockUsecase.AssertNotCalled(t, "GetMessagesByRoomID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestCreateMessage(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	validator := newTestValidator()
 
 	teatCases := []struct {
 		name         string
-		reqBody      map[string]string
+		userId       string
+		reqBody      map[string]interface{}
 		mockReturn   error
 		expectedCode int
 	}{
 
 		{
-			name: "Success",
-			reqBody: map[string]string{
-				"userId":  "1",
+			name:   "Success",
+			userId: "1",
+			reqBody: map[string]interface{}{
 				"content": "Hello",
 			},
 			mockReturn:   nil,
 			expectedCode: http.StatusCreated,
 		},
+		{
+			name:   "Attachments Only",
+			userId: "1",
+			reqBody: map[string]interface{}{
+				"attachmentIds": []string{"1", "2"},
+			},
+			mockReturn:   nil,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "Empty AttachmentID",
+			userId: "1",
+			reqBody: map[string]interface{}{
+				"content":       "Hello",
+				"attachmentIds": []string{""},
+			},
+			mockReturn:   nil,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Attachment Rejected",
+			userId: "1",
+			reqBody: map[string]interface{}{
+				"attachmentIds": []string{"1"},
+			},
+			mockReturn:   apperror.NewBadRequestErr("Attachment", "AttachmentID: 1 was not uploaded"),
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:         "Invalid Body",
-			reqBody:      map[string]string{},
+			userId:       "1",
+			reqBody:      map[string]interface{}{},
 			mockReturn:   nil,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Missing userId",
-			reqBody: map[string]string{
+			name:   "Unauthenticated",
+			userId: "",
+			reqBody: map[string]interface{}{
 				"content": "Hello",
 			},
 			mockReturn:   nil,
-			expectedCode: http.StatusBadRequest,
+			expectedCode: http.StatusUnauthorized,
 		},
 		{
-			name: "Missing content",
-			reqBody: map[string]string{
+			name:   "Missing content",
+			userId: "1",
+			reqBody: map[string]interface{}{
 				"userId": "1",
 			},
 			mockReturn:   nil,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Empty Content",
-			reqBody: map[string]string{
-				"userId":  "1",
+			name:   "Empty Content",
+			userId: "1",
+			reqBody: map[string]interface{}{
 				"content": "",
 			},
 			mockReturn:   nil,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Create Failed",
-			reqBody: map[string]string{
-				"userId":  "1",
+			name:   "Create Failed",
+			userId: "1",
+			reqBody: map[string]interface{}{
 				"content": "Hello",
 			},
 			mockReturn:   errors.New("some error"),
@@ -127,7 +182,9 @@ func TestCreateMessage(t *testing.T) {
 	for _, tc := range teatCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockUsecase := new(mocks.MessageUsecase)
-			mockUsecase.On("CreateMessage", mock.Anything, mock.Anything).Return(tc.mockReturn)
+			mockUsecase.On("CreateMessage", mock.Anything, mock.MatchedBy(func(message *model.Message) bool {
+				return message.UserID == tc.userId
+			})).Return(tc.mockReturn)
 
 			reqBody, err := json.Marshal(tc.reqBody)
 			if err != nil {
@@ -135,6 +192,7 @@ func TestCreateMessage(t *testing.T) {
 			}
 
 			request, _ := http.NewRequest(http.MethodPost, "messages", bytes.NewBuffer(reqBody))
+			request = withUserID(request, tc.userId)
 			response := httptest.NewRecorder()
 
 			ctx, _ := gin.CreateTestContext(response)
@@ -143,9 +201,12 @@ func TestCreateMessage(t *testing.T) {
 
 			mc := NewMessageController(mockUsecase, validator)
 
-			mc.CreateMessage(ctx)
+			serve(ctx, mc.CreateMessage)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
+			if tc.expectedCode == http.StatusCreated {
+				mockUsecase.AssertExpectations(t)
+			}
 		})
 	}
 }
@@ -220,7 +281,7 @@ func TestUpdateMessage(t *testing.T) {
 
 			mc := NewMessageController(mockUsecase, validator)
 
-			mc.UpdateMessage(ctx)
+			serve(ctx, mc.UpdateMessage)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
 		})
@@ -247,6 +308,11 @@ func TestDeleteMessage(t *testing.T) {
 			mockReturn:   errors.New("some error"),
 			expectedCode: http.StatusInternalServerError,
 		},
+		{
+			name:         "Not Member",
+			mockReturn:   apperror.NewForbiddenErr("Room", "RoomID: 1"),
+			expectedCode: http.StatusForbidden,
+		},
 	}
 
 	for _, tc := range teatCases {
@@ -263,9 +329,121 @@ func TestDeleteMessage(t *testing.T) {
 
 			mc := NewMessageController(mockUsecase, validator)
 
-			mc.DeleteMessage(ctx)
+			serve(ctx, mc.DeleteMessage)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
 		})
 	}
 }
+
+func TestMarkAsRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := newTestValidator()
+
+	testCases := []struct {
+		name         string
+		body         string
+		mockReturn   error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			body:         `{"messageId":"1"}`,
+			mockReturn:   nil,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Missing MessageID",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Unknown Message",
+			body:         `{"messageId":"1"}`,
+			mockReturn:   apperror.NewNotFoundErr("Message", "MessageID: 1"),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.MessageUsecase)
+			mockUsecase.On("MarkAsRead", mock.Anything, "1", "1").Return(tc.mockReturn)
+
+			_, ctx, response := prepareRequestAndContext(http.MethodPut, "rooms/1/read", gin.Params{{Key: "roomId", Value: "1"}}, strings.NewReader(tc.body))
+
+			mc := NewMessageController(mockUsecase, validator)
+
+			serve(ctx, mc.MarkAsRead)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			if tc.expectedCode == http.StatusBadRequest {
+				mockUsecase.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSearchMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	hits := []*model.MessageSearchHit{
+		{Message: &model.Message{MessageID: "1", RoomID: "1", Content: "Hello"}, Highlights: []string{"<em>Hello</em>"}},
+	}
+
+	testCases := []struct {
+		name         string
+		url          string
+		mockErr      error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			url:          "/search/messages?q=hello&roomId=1",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Missing Query",
+			url:          "/search/messages?roomId=1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid Limit",
+			url:          "/search/messages?q=hello&roomId=1&limit=ten",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Not Member",
+			url:          "/search/messages?q=hello&roomId=1",
+			mockErr:      apperror.NewForbiddenErr("Room", "RoomID: 1"),
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.MessageUsecase)
+			mockUsecase.On("SearchMessages", mock.Anything, "hello", "1", 0, 20).Return(hits, 1, tc.mockErr)
+
+			_, ctx, response := prepareRequestAndContext(http.MethodGet, tc.url, nil, nil)
+			mc := NewMessageController(mockUsecase, newTestValidator())
+			serve(ctx, mc.SearchMessages)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			switch {
+			case tc.expectedCode == http.StatusOK:
+				var result struct {
+					Result []*model.MessageSearchHit `json:"result"`
+					Total  int                       `json:"total"`
+				}
+				if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, hits, result.Result)
+				assert.Equal(t, 1, result.Total)
+			case tc.mockErr == nil:
+				mockUsecase.AssertNotCalled(t, "SearchMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+)
+
+type PresenceController struct {
+	presenceUsecase usecase.PresenceUsecase
+}
+
+func NewPresenceController(presenceUsecase usecase.PresenceUsecase) *PresenceController {
+	return &PresenceController{
+		presenceUsecase,
+	}
+}
+
+func (pc *PresenceController) GetRoomPresence(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+
+	presences, err := pc.presenceUsecase.GetRoomPresence(ctx.Request.Context(), roomId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"result": presences})
+}
+
+func (pc *PresenceController) GetUserPresence(ctx *gin.Context) {
+	userId := ctx.Param("userId")
+
+	presence, err := pc.presenceUsecase.GetUserPresence(ctx.Request.Context(), userId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"result": presence})
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetRoomPresence(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	presences := []*model.Presence{
+		{UserID: "1", Status: model.Online},
+		{UserID: "2", Status: model.Offline},
+	}
+
+	testCases := []struct {
+		name              string
+		mockReturn        []*model.Presence
+		mockErr           error
+		expectedCode      int
+		expectedPresences []*model.Presence
+	}{
+		{
+			name:              "Success",
+			mockReturn:        presences,
+			expectedCode:      http.StatusOK,
+			expectedPresences: presences,
+		},
+		{
+			name:         "Not Member",
+			mockErr:      apperror.NewForbiddenErr("Room", "RoomID: 1"),
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.PresenceUsecase)
+			mockUsecase.On("GetRoomPresence", mock.Anything, "1").Return(tc.mockReturn, tc.mockErr)
+			pc := NewPresenceController(mockUsecase)
+
+			_, ctx, response := prepareRequestAndContext(http.MethodGet, "/rooms/1/presence", gin.Params{{Key: "roomId", Value: "1"}}, nil)
+			serve(ctx, pc.GetRoomPresence)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			if tc.mockErr == nil {
+				var result struct {
+					Result []*model.Presence `json:"result"`
+				}
+				if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, tc.expectedPresences, result.Result)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetUserPresence(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		mockReturn   *model.Presence
+		mockErr      error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			mockReturn:   &model.Presence{UserID: "1", Status: model.Away},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Unknown User",
+			mockErr:      apperror.NewNotFoundErr("User", "UserID: 1"),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.PresenceUsecase)
+			mockUsecase.On("GetUserPresence", mock.Anything, "1").Return(tc.mockReturn, tc.mockErr)
+			pc := NewPresenceController(mockUsecase)
+
+			_, ctx, response := prepareRequestAndContext(http.MethodGet, "/users/1/presence", gin.Params{{Key: "userId", Value: "1"}}, nil)
+			serve(ctx, pc.GetUserPresence)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			if tc.mockErr == nil {
+				var result struct {
+					Result *model.Presence `json:"result"`
+				}
+				if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, tc.mockReturn, result.Result)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/validation"
+)
+
+type ReactionController struct {
+	reactionUsecase usecase.ReactionUsecase
+	validator       *validation.Validator
+}
+
+func NewReactionController(reactionUsecase usecase.ReactionUsecase, validator *validation.Validator) *ReactionController {
+	return &ReactionController{
+		reactionUsecase,
+		validator,
+	}
+}
+
+func (rc *ReactionController) AddReaction(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+	messageId := ctx.Param("messageId")
+
+	var req struct {
+		Emoji string `json:"emoji" validate:"required,max=32"`
+	}
+
+	if err := rc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := rc.reactionUsecase.AddReaction(ctx.Request.Context(), roomId, messageId, req.Emoji); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"result": "reaction added successfully"})
+}
+
+func (rc *ReactionController) RemoveReaction(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+	messageId := ctx.Param("messageId")
+	emoji := ctx.Param("emoji")
+
+	if err := rc.reactionUsecase.RemoveReaction(ctx.Request.Context(), roomId, messageId, emoji); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"result": "reaction removed successfully"})
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddReaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := newTestValidator()
+
+	testCases := []struct {
+		name         string
+		body         string
+		mockReturn   error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			body:         `{"emoji":"👍"}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Missing Emoji",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Not Member",
+			body:         `{"emoji":"👍"}`,
+			mockReturn:   apperror.NewForbiddenErr("Room", "RoomID: 1"),
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.ReactionUsecase)
+			mockUsecase.On("AddReaction", mock.Anything, "1", "2", "👍").Return(tc.mockReturn)
+
+			params := gin.Params{{Key: "roomId", Value: "1"}, {Key: "messageId", Value: "2"}}
+			_, ctx, response := prepareRequestAndContext(http.MethodPost, "rooms/1/messages/2/reactions", params, strings.NewReader(tc.body))
+
+			rc := NewReactionController(mockUsecase, validator)
+			serve(ctx, rc.AddReaction)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			if tc.expectedCode == http.StatusBadRequest {
+				mockUsecase.AssertNotCalled(t, "AddReaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRemoveReaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := newTestValidator()
+
+	testCases := []struct {
+		name         string
+		mockReturn   error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Remove Failed",
+			mockReturn:   errors.New("some error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.ReactionUsecase)
+			mockUsecase.On("RemoveReaction", mock.Anything, "1", "2", "👍").Return(tc.mockReturn)
+
+			params := gin.Params{{Key: "roomId", Value: "1"}, {Key: "messageId", Value: "2"}, {Key: "emoji", Value: "👍"}}
+			_, ctx, response := prepareRequestAndContext(http.MethodDelete, "rooms/1/messages/2/reactions/👍", params, nil)
+
+			rc := NewReactionController(mockUsecase, validator)
+			serve(ctx, rc.RemoveReaction)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -4,18 +4,20 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/validation"
 )
 
 type RoomController struct {
 	roomUsecase usecase.RoomUsecase
-	validator   *validator.Validate
+	validator   *validation.Validator
 }
 
-func NewRoomController(roomUsecase usecase.RoomUsecase, validator *validator.Validate) *RoomController {
+func NewRoomController(roomUsecase usecase.RoomUsecase, validator *validation.Validator) *RoomController {
 	return &RoomController{
 		roomUsecase,
 		validator,
@@ -27,7 +29,8 @@ func (rc *RoomController) GetRoomByID(ctx *gin.Context) {
 
 	result, err := rc.roomUsecase.GetRoomByID(ctx.Request.Context(), roomId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"result": result})
@@ -36,33 +39,33 @@ func (rc *RoomController) GetRoomByID(ctx *gin.Context) {
 func (rc *RoomController) GetAllPublicRoom(ctx *gin.Context) {
 	result, err := rc.roomUsecase.GetAllPublicRoom(ctx.Request.Context())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"result": result})
 }
 
 func (rc *RoomController) CreateRoom(ctx *gin.Context) {
+	ownerId, ok := authctx.UserID(ctx.Request.Context())
+	if !ok {
+		ctx.Error(apperror.NewUnauthenticatedErr("User", "is not verified"))
+		return
+	}
+
 	var req struct {
 		Name     string `json:"name" validate:"required,min=1,max=30,alnumdash"`
 		RoomType string `json:"roomType" validate:"required,oneof=public private"`
-		OwnerID  string `json:"ownerId" validate:"required"`
-	}
-
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
 	}
 
-	err := rc.validator.Struct(req)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := rc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
 		return
 	}
 
 	roomType, err := model.ParseRoomType(req.RoomType)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.NewValidationErr("Room", "RoomType: "+req.RoomType))
 		return
 	}
 
@@ -72,8 +75,8 @@ func (rc *RoomController) CreateRoom(ctx *gin.Context) {
 		RoomType: roomType,
 	}
 
-	if err := rc.roomUsecase.CreateRoom(ctx, room, req.OwnerID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := rc.roomUsecase.CreateRoom(ctx.Request.Context(), room, ownerId); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -84,7 +87,7 @@ func (rc *RoomController) DeleteRoom(ctx *gin.Context) {
 	roomId := ctx.Param("roomId")
 
 	if err := rc.roomUsecase.DeleteRoom(ctx.Request.Context(), roomId); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -99,20 +102,14 @@ func (rc *RoomController) UpdateRoom(ctx *gin.Context) {
 		RoomType string `json:"roomType" validate:"required,oneof=public private"`
 	}
 
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	err := rc.validator.Struct(req)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := rc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
 		return
 	}
 
 	roomType, err := model.ParseRoomType(req.RoomType)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.NewValidationErr("Room", "RoomType: "+req.RoomType))
 		return
 	}
 
@@ -122,8 +119,9 @@ func (rc *RoomController) UpdateRoom(ctx *gin.Context) {
 		RoomType: roomType,
 	}
 
-	if err := rc.roomUsecase.UpdateRoom(ctx, room); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := rc.roomUsecase.UpdateRoom(ctx.Request.Context(), room); err != nil {
+		ctx.Error(err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"result": "room updated successfully"})
@@ -5,14 +5,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
-	"regexp"
 	"testing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/goccy/go-json"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/validation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,66 +24,78 @@ func TestCreateRoom(t *testing.T) {
 
 	testCases := []struct {
 		name         string
+		ownerId      string
 		reqBody      map[string]string
 		mockReturn   error
 		expectedCode int
 	}{
 		{
-			name: "Success",
+			name:    "Success",
+			ownerId: "1",
 			reqBody: map[string]string{
 				"name":     "chat_room",
 				"roomType": "public",
-				"ownerId":  "1",
 			},
 			mockReturn:   nil,
 			expectedCode: http.StatusCreated,
 		},
 		{
-			name: "Invalid roomType",
+			name:    "Invalid roomType",
+			ownerId: "1",
 			reqBody: map[string]string{
 				"name":     "chat_room",
 				"roomType": "invalid",
-				"ownerId":  "1",
 			},
 			mockReturn:   nil,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Invalid name",
+			name:    "Invalid name",
+			ownerId: "1",
 			reqBody: map[string]string{
 				"name":     "invalid%room",
 				"roomType": "public",
-				"ownerId":  "1",
 			},
 			mockReturn:   nil,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Missing roomType",
+			name:    "Missing roomType",
+			ownerId: "1",
 			reqBody: map[string]string{
-				"name":    "chat_room",
-				"ownerId": "1",
+				"name": "chat_room",
 			},
 			mockReturn:   nil,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Missing name",
+			name:    "Missing name",
+			ownerId: "1",
 			reqBody: map[string]string{
 				"roomType": "public",
-				"ownerId":  "1",
 			},
 			mockReturn:   nil,
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Missing ownerId",
+			name:    "Room Name Taken",
+			ownerId: "1",
+			reqBody: map[string]string{
+				"name":     "chat_room",
+				"roomType": "public",
+			},
+			mockReturn:   apperror.NewAlreadyExistsErr("Room", "RoomName: chat_room"),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:    "Unauthenticated",
+			ownerId: "",
 			reqBody: map[string]string{
 				"name":     "chat_room",
 				"roomType": "public",
 			},
 			mockReturn:   nil,
-			expectedCode: http.StatusBadRequest,
+			expectedCode: http.StatusUnauthorized,
 		},
 	}
 
@@ -93,16 +106,17 @@ func TestCreateRoom(t *testing.T) {
 			reqBody, _ := json.Marshal(tc.reqBody)
 
 			request, _ := http.NewRequest(http.MethodPost, "/rooms", bytes.NewBuffer(reqBody))
+			request = withUserID(request, tc.ownerId)
 			response := httptest.NewRecorder()
 
 			ctx, _ := gin.CreateTestContext(response)
 			ctx.Request = request
 
-			mockUsecase.On("CreateRoom", mock.Anything, mock.Anything, mock.Anything).Return(tc.mockReturn)
+			mockUsecase.On("CreateRoom", mock.Anything, mock.Anything, tc.ownerId).Return(tc.mockReturn)
 
 			uc := NewRoomController(mockUsecase, validator)
 
-			uc.CreateRoom(ctx)
+			serve(ctx, uc.CreateRoom)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
 
@@ -168,7 +182,7 @@ func TestGetRoomByID(t *testing.T) {
 
 			mockUsecase.On("GetRoomByID", mock.Anything, tc.roomId).Return(tc.mockReturn, tc.expectedErr)
 
-			uc.GetRoomByID(ctx)
+			serve(ctx, uc.GetRoomByID)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
 
@@ -238,7 +252,7 @@ func TestGetAllPublicRooms(t *testing.T) {
 
 			mockUsecase.On("GetAllPublicRoom", mock.Anything).Return(tc.mockReturn, tc.expectedErr)
 
-			uc.GetAllPublicRoom(ctx)
+			serve(ctx, uc.GetAllPublicRoom)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
 
@@ -296,7 +310,7 @@ func TestDeleteRoom(t *testing.T) {
 
 			mockUsecase.On("DeleteRoom", mock.Anything, tc.roomId).Return(tc.expectedErr)
 
-			uc.DeleteRoom(ctx)
+			serve(ctx, uc.DeleteRoom)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
 
@@ -376,7 +390,7 @@ func TestUpdateRoom(t *testing.T) {
 
 			mockUsecase.On("UpdateRoom", mock.Anything, mock.Anything).Return(tc.expectedErr)
 
-			uc.UpdateRoom(ctx)
+			serve(ctx, uc.UpdateRoom)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
 
@@ -396,14 +410,17 @@ func TestUpdateRoom(t *testing.T) {
 	}
 }
 
-func newTestValidator() *validator.Validate {
-	v := validator.New()
-	if err := v.RegisterValidation("alnumdash", isAlnumOrDash); err != nil {
-		panic(err)
+func withUserID(request *http.Request, userId string) *http.Request {
+	if userId == "" {
+		return request
 	}
-	return v
+	return request.WithContext(authctx.WithUserID(request.Context(), userId))
 }
 
-func isAlnumOrDash(fl validator.FieldLevel) bool {
-	return regexp.MustCompile(`^[a-zA-Z0-9_-]+$`).MatchString(fl.Field().String())
+func newTestValidator() *validation.Validator {
+	v, err := validation.NewValidator()
+	if err != nil {
+		panic(err)
+	}
+	return v
 }
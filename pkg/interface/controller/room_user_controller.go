@@ -5,16 +5,18 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/validation"
 )
 
 type RoomUserController struct {
 	roomUserUsecase usecase.RoomUserUsecase
-	validator       *validator.Validate
+	validator       *validation.Validator
 }
 
-func NewRoomUserController(roomUserUsecase usecase.RoomUserUsecase, validator *validator.Validate) *RoomUserController {
+func NewRoomUserController(roomUserUsecase usecase.RoomUserUsecase, validator *validation.Validator) *RoomUserController {
 	return &RoomUserController{
 		roomUserUsecase,
 		validator,
@@ -24,9 +26,9 @@ func NewRoomUserController(roomUserUsecase usecase.RoomUserUsecase, validator *v
 func (rc *RoomUserController) GetAllRoomsByUserID(ctx *gin.Context) {
 	userId := ctx.Param("userId")
 
-	rooms, err := rc.roomUserUsecase.GetAllRoomsByUserID(ctx, userId)
+	rooms, err := rc.roomUserUsecase.GetAllRoomsByUserID(ctx.Request.Context(), userId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -40,11 +42,12 @@ func (rc *RoomUserController) GetUsersByRoomID(ctx *gin.Context) {
 
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.NewValidationErr("Query", "limit: "+limit))
+		return
 	}
-	users, nextKey, err := rc.roomUserUsecase.GetUsersByRoomID(ctx, roomId, lastEvaluatedKey, limitInt)
+	users, nextKey, err := rc.roomUserUsecase.GetUsersByRoomID(ctx.Request.Context(), roomId, lastEvaluatedKey, limitInt)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -55,8 +58,8 @@ func (rc *RoomUserController) RemoveUserFromRoom(ctx *gin.Context) {
 	roomId := ctx.Param("roomId")
 	userId := ctx.Param("userId")
 
-	if err := rc.roomUserUsecase.RemoveUserFromRoom(ctx, roomId, userId); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := rc.roomUserUsecase.RemoveUserFromRoom(ctx.Request.Context(), roomId, userId); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -70,15 +73,62 @@ func (rc *RoomUserController) AddUsersToRoom(ctx *gin.Context) {
 		UserIDs []string `json:"userIds" validate:"required"`
 	}
 
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := rc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := rc.roomUserUsecase.AddUsersToRoom(ctx, roomId, req.UserIDs); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := rc.roomUserUsecase.AddUsersToRoom(ctx.Request.Context(), roomId, req.UserIDs); err != nil {
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{"result": "success to add the users to the room"})
 }
+
+func (rc *RoomUserController) UpdateUserRole(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+	userId := ctx.Param("userId")
+
+	var req struct {
+		Role string `json:"role" validate:"required,oneof=admin member"`
+	}
+
+	if err := rc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	role, err := model.ParseRoomRole(req.Role)
+	if err != nil {
+		ctx.Error(apperror.NewValidationErr("RoomUser", "Role: "+req.Role))
+		return
+	}
+
+	if err := rc.roomUserUsecase.UpdateUserRole(ctx.Request.Context(), roomId, userId, role); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"result": "success to update the role of the user"})
+}
+
+func (rc *RoomUserController) TransferOwnership(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+
+	var req struct {
+		UserID string `json:"userId" validate:"required"`
+	}
+
+	if err := rc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := rc.roomUserUsecase.TransferOwnership(ctx.Request.Context(), roomId, req.UserID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"result": "success to transfer the ownership of the room"})
+}
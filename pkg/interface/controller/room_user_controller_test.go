@@ -9,35 +9,42 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/goccy/go-json"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAllRoomsByUserID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	validator := validator.New()
+	validator := newTestValidator()
 
-	mockRooms := []*model.Room{
+	mockRooms := []*model.UserRoom{
 		{
-			RoomID:   "1",
-			Name:     "room-1",
-			RoomType: model.Public,
+			Room: model.Room{
+				RoomID:   "1",
+				Name:     "room-1",
+				RoomType: model.Public,
+			},
+			UnreadCount: 2,
 		},
 		{
-			RoomID:   "2",
-			Name:     "room-2",
-			RoomType: model.Private,
+			Room: model.Room{
+				RoomID:   "2",
+				Name:     "room-2",
+				RoomType: model.Private,
+			},
+			LastReadMessageID: "3",
 		},
 	}
 
 	testCases := []struct {
 		name         string
 		userId       string
-		mockReturn   []*model.Room
+		mockReturn   []*model.UserRoom
 		expectedErr  error
 		expectedCode int
 	}{
@@ -67,7 +74,7 @@ func TestGetAllRoomsByUserID(t *testing.T) {
 
 			_, ctx, response := prepareRequestAndContext(http.MethodGet, "users/"+tc.userId+"/rooms", gin.Params{{Key: "userId", Value: tc.userId}}, nil)
 
-			uc.GetAllRoomsByUserID(ctx)
+			serve(ctx, uc.GetAllRoomsByUserID)
 
 			checkResponseRooms(t, tc.expectedErr, tc.expectedCode, response, tc.mockReturn)
 		})
@@ -77,7 +84,7 @@ func TestGetAllRoomsByUserID(t *testing.T) {
 func TestRemoveUserFromRoom(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(mocks.RoomUserUsecase)
-	validator := validator.New()
+	validator := newTestValidator()
 
 	uc := NewRoomUserController(mockUsecase, validator)
 
@@ -117,7 +124,7 @@ func TestRemoveUserFromRoom(t *testing.T) {
 
 			_, ctx, response := prepareRequestAndContext(http.MethodDelete, "rooms/"+tc.roomId+"/users/"+tc.userId, gin.Params{{Key: "roomId", Value: tc.roomId}, {Key: "userId", Value: tc.userId}}, nil)
 
-			uc.RemoveUserFromRoom(ctx)
+			serve(ctx, uc.RemoveUserFromRoom)
 
 			checkResponseMessage(t, tc.expectedErr, tc.expectedCode, response, "success to remove the user from the room")
 		})
@@ -126,7 +133,7 @@ func TestRemoveUserFromRoom(t *testing.T) {
 
 func TestAddUsersToRoom(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	validator := validator.New()
+	validator := newTestValidator()
 
 	testCases := []struct {
 		name         string
@@ -149,6 +156,16 @@ func TestAddUsersToRoom(t *testing.T) {
 			expectedErr:  errors.New("some error"),
 			expectedCode: http.StatusInternalServerError,
 		},
+		{
+			name:   "Missing UserIDs",
+			roomId: "1",
+			expectedErr: apperror.NewValidationErr("Request", "Fields: userIds", apperror.FieldError{
+				Field:   "userIds",
+				Rule:    "required",
+				Message: "userIds is a required field",
+			}),
+			expectedCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -169,13 +186,112 @@ func TestAddUsersToRoom(t *testing.T) {
 
 			_, ctx, response := prepareRequestAndContext(http.MethodPost, "rooms/"+tc.roomId+"/users", gin.Params{{Key: "roomId", Value: tc.roomId}}, bytes.NewBuffer(reqBody))
 
-			uc.AddUsersToRoom(ctx)
+			serve(ctx, uc.AddUsersToRoom)
 
 			checkResponseMessage(t, tc.expectedErr, tc.expectedCode, response, "success to add the users to the room")
 		})
 	}
 }
 
+func TestUpdateUserRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := newTestValidator()
+
+	testCases := []struct {
+		name         string
+		role         string
+		mockReturn   error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			role:         "admin",
+			mockReturn:   nil,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid Role",
+			role:         "owner",
+			mockReturn:   nil,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Not Owner",
+			role:         "member",
+			mockReturn:   apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.RoomUserUsecase)
+			mockUsecase.On("UpdateUserRole", mock.Anything, "1", "2", model.RoomRole(tc.role)).Return(tc.mockReturn)
+
+			uc := NewRoomUserController(mockUsecase, validator)
+
+			reqBody, _ := json.Marshal(map[string]string{"role": tc.role})
+
+			_, ctx, response := prepareRequestAndContext(http.MethodPut, "rooms/1/users/2/role", gin.Params{{Key: "roomId", Value: "1"}, {Key: "userId", Value: "2"}}, bytes.NewBuffer(reqBody))
+
+			serve(ctx, uc.UpdateUserRole)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			if tc.expectedCode == http.StatusOK {
+				mockUsecase.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := newTestValidator()
+
+	testCases := []struct {
+		name         string
+		reqBody      map[string]string
+		mockReturn   error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			reqBody:      map[string]string{"userId": "2"},
+			mockReturn:   nil,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Missing userId",
+			reqBody:      map[string]string{},
+			mockReturn:   nil,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Not Owner",
+			reqBody:      map[string]string{"userId": "2"},
+			mockReturn:   apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.RoomUserUsecase)
+			mockUsecase.On("TransferOwnership", mock.Anything, "1", tc.reqBody["userId"]).Return(tc.mockReturn)
+
+			uc := NewRoomUserController(mockUsecase, validator)
+
+			reqBody, _ := json.Marshal(tc.reqBody)
+
+			_, ctx, response := prepareRequestAndContext(http.MethodPut, "rooms/1/owner", gin.Params{{Key: "roomId", Value: "1"}}, bytes.NewBuffer(reqBody))
+
+			serve(ctx, uc.TransferOwnership)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+		})
+	}
+}
+
 func prepareRequestAndContext(method, url string, params gin.Params, body io.Reader) (*http.Request, *gin.Context, *httptest.ResponseRecorder) {
 	request, _ := http.NewRequest(method, url, body)
 	response := httptest.NewRecorder()
@@ -185,6 +301,12 @@ func prepareRequestAndContext(method, url string, params gin.Params, body io.Rea
 	return request, ctx, response
 }
 
+// serve runs the handler the way the router does, behind the error middleware.
+func serve(ctx *gin.Context, handler gin.HandlerFunc) {
+	handler(ctx)
+	middleware.RenderErrors(ctx)
+}
+
 func checkResponseMessage(t *testing.T, expectedErr error, expectedCode int, response *httptest.ResponseRecorder, expectedMessage string) {
 	assert.Equal(t, expectedCode, response.Code)
 
@@ -197,21 +319,36 @@ func checkResponseMessage(t *testing.T, expectedErr error, expectedCode int, res
 		}
 		assert.Equal(t, expectedMessage, result.Result)
 	} else {
-		var result struct {
-			Error string `json:"error"`
-		}
-		if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
-			t.Fatal(err)
-		}
-		assert.Equal(t, expectedErr.Error(), result.Error)
+		checkErrorResponse(t, expectedErr, response)
 	}
 }
 
-func checkResponseRooms(t *testing.T, expectedErr error, expectedCode int, response *httptest.ResponseRecorder, expectedRooms []*model.Room) {
+// checkErrorResponse asserts the response is the envelope of the error, whose message is hidden unless it has a code.
+func checkErrorResponse(t *testing.T, expectedErr error, response *httptest.ResponseRecorder) {
+	var result middleware.ErrorResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	code := apperror.CodeOf(expectedErr)
+	assert.Equal(t, code, result.Error.Code)
+	if code == apperror.CodeInternal {
+		assert.Equal(t, "internal server error", result.Error.Message)
+	} else {
+		assert.Equal(t, expectedErr.Error(), result.Error.Message)
+	}
+
+	var validationErr *apperror.ValidationErr
+	if errors.As(expectedErr, &validationErr) {
+		assert.Equal(t, validationErr.Fields, result.Error.Fields)
+	}
+}
+
+func checkResponseRooms(t *testing.T, expectedErr error, expectedCode int, response *httptest.ResponseRecorder, expectedRooms []*model.UserRoom) {
 	if expectedErr == nil {
 		assert.Equal(t, expectedCode, response.Code)
 		var result struct {
-			Result []*model.Room `json:"result"`
+			Result []*model.UserRoom `json:"result"`
 		}
 		if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
 			t.Fatal(err)
@@ -219,12 +356,6 @@ func checkResponseRooms(t *testing.T, expectedErr error, expectedCode int, respo
 		assert.Equal(t, expectedRooms, result.Result)
 	} else {
 		assert.Equal(t, expectedCode, response.Code)
-		var result struct {
-			Error string `json:"error"`
-		}
-		if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
-			t.Fatal(err)
-		}
-		assert.Equal(t, expectedErr.Error(), result.Error)
+		checkErrorResponse(t, expectedErr, response)
 	}
 }
@@ -5,51 +5,53 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/validation"
 )
 
 type UserController struct {
 	userUsecase usecase.UserUsecase
-	validator   *validator.Validate
+	validator   *validation.Validator
 }
 
-func NewUserController(userUsecase usecase.UserUsecase, validator *validator.Validate) *UserController {
+func NewUserController(userUsecase usecase.UserUsecase, validator *validation.Validator) *UserController {
 	return &UserController{
 		userUsecase,
 		validator,
 	}
 }
 
+// CreateUser creates the profile of the caller, under the UID their ID token was verified for.
 func (uc *UserController) CreateUser(ctx *gin.Context) {
+	userId, ok := authctx.UserID(ctx.Request.Context())
+	if !ok {
+		ctx.Error(apperror.NewUnauthenticatedErr("User", "is not verified"))
+		return
+	}
+
 	var req struct {
-		UserID   string `json:"userId" validate:"required"`
 		Name     string `json:"name" validate:"required,min=1,max=30"`
 		Email    string `json:"email" validate:"required,email"`
 		ImageURL string `json:"imageUrl" validate:"required,url"`
-		IDToken  string `json:"idToken" validate:"required"`
 	}
 
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if err := uc.validator.Struct(req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := uc.validator.BindJSON(ctx, &req); err != nil {
+		ctx.Error(err)
 		return
 	}
 
 	user := &model.User{
-		UserID:   req.UserID,
+		UserID:   userId,
 		Username: req.Name,
 		Email:    req.Email,
 		ImageURL: req.ImageURL,
 	}
 
-	if err := uc.userUsecase.CreateUser(ctx.Request.Context(), user, req.IDToken); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := uc.userUsecase.CreateUser(ctx.Request.Context(), user); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -61,7 +63,7 @@ func (uc *UserController) GetUserByID(ctx *gin.Context) {
 
 	result, err := uc.userUsecase.GetUserByID(ctx.Request.Context(), userId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -74,11 +76,12 @@ func (uc *UserController) GetMultipleUsers(ctx *gin.Context) {
 
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.NewValidationErr("Query", "limit: "+limit))
+		return
 	}
-	users, nextKey, err := uc.userUsecase.GetMultipleUsers(ctx, lastEvaluatedKey, limitInt)
+	users, nextKey, err := uc.userUsecase.GetMultipleUsers(ctx.Request.Context(), lastEvaluatedKey, limitInt)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -89,13 +92,13 @@ func (uc *UserController) BatchGetUsers(ctx *gin.Context) {
 	userIds := ctx.QueryArray("userIds")
 
 	if len(userIds) == 0 {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "userIds is required"})
+		ctx.Error(apperror.NewValidationErr("Query", "userIds is required"))
 		return
 	}
 
 	users, err := uc.userUsecase.BatchGetUsers(ctx.Request.Context(), userIds)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
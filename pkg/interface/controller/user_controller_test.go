@@ -8,7 +8,6 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/goccy/go-json"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
@@ -19,7 +18,7 @@ import (
 func TestGetUserByID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(mocks.UserUsecase)
-	validator := validator.New()
+	validator := newTestValidator()
 
 	uc := NewUserController(mockUsecase, validator)
 
@@ -62,7 +61,7 @@ func TestGetUserByID(t *testing.T) {
 			ctx.Params = gin.Params{{Key: "userId", Value: tc.userId}}
 			ctx.Request = request
 
-			uc.GetUserByID(ctx)
+			serve(ctx, uc.GetUserByID)
 
 			if tc.isErrorCase {
 				assert.Equal(t, http.StatusInternalServerError, response.Code)
@@ -89,51 +88,68 @@ func TestGetUserByID(t *testing.T) {
 
 func TestCreateUser(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	validator := validator.New()
+	validator := newTestValidator()
 
 	testCases := []struct {
 		name         string
+		userId       string
 		reqBody      map[string]string
 		expectedCode int
 	}{
 		{
-			name: "Success",
+			name:   "Success",
+			userId: "1",
 			reqBody: map[string]string{
-				"userId":   "1",
 				"name":     "user-1",
 				"email":    "user-1@example.com",
-				"idToken":  "test_id_token",
 				"imageUrl": "https://example.com/image.png",
 			},
 			expectedCode: http.StatusOK,
 		},
 		{
-			name: "Missing name",
+			name:   "UserID in the Body is Ignored",
+			userId: "1",
 			reqBody: map[string]string{
 				"userId":   "2",
+				"name":     "user-1",
+				"email":    "user-1@example.com",
+				"imageUrl": "https://example.com/image.png",
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Unauthenticated",
+			reqBody: map[string]string{
+				"name":     "user-1",
+				"email":    "user-1@example.com",
+				"imageUrl": "https://example.com/image.png",
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "Missing name",
+			userId: "2",
+			reqBody: map[string]string{
 				"email":    "user-2@example.com",
-				"idToken":  "test_id_token",
 				"imageUrl": "https://example.com/image.png",
 			},
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Missing email",
+			name:   "Missing email",
+			userId: "3",
 			reqBody: map[string]string{
-				"userId":   "3",
 				"name":     "user-3",
-				"idToken":  "test_id_token",
 				"imageUrl": "https://example.com/image.png",
 			},
 			expectedCode: http.StatusBadRequest,
 		},
 		{
-			name: "Invalid email",
+			name:   "Invalid email",
+			userId: "4",
 			reqBody: map[string]string{
-				"userId":   "4",
 				"name":     "user-4",
 				"email":    "invalid_email",
-				"idToken":  "test_id_token",
 				"imageUrl": "https://example.com/image.png",
 			},
 			expectedCode: http.StatusBadRequest,
@@ -145,7 +161,9 @@ func TestCreateUser(t *testing.T) {
 			mockUsecase := new(mocks.UserUsecase)
 
 			if tc.expectedCode == http.StatusOK {
-				mockUsecase.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				mockUsecase.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.UserID == tc.userId
+				})).Return(nil)
 			}
 
 			uc := NewUserController(mockUsecase, validator)
@@ -154,9 +172,9 @@ func TestCreateUser(t *testing.T) {
 			request, _ := http.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(reqBody))
 			response := httptest.NewRecorder()
 			ctx, _ := gin.CreateTestContext(response)
-			ctx.Request = request
+			ctx.Request = withUserID(request, tc.userId)
 
-			uc.CreateUser(ctx)
+			serve(ctx, uc.CreateUser)
 
 			assert.Equal(t, tc.expectedCode, response.Code)
 
@@ -169,7 +187,7 @@ func TestCreateUser(t *testing.T) {
 
 				result, _ := responseBody["result"].(map[string]interface{})
 
-				t.Log(result)
+				assert.Equal(t, tc.userId, result["userId"])
 				assert.Equal(t, tc.reqBody["name"], result["userName"])
 				assert.Equal(t, tc.reqBody["email"], result["email"])
 			}
@@ -180,7 +198,7 @@ func TestCreateUser(t *testing.T) {
 func TestBatchGetUsers(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(mocks.UserUsecase)
-	validator := validator.New()
+	validator := newTestValidator()
 
 	userIds := []string{"1", "2", "3"}
 	var mockUsers []*model.User
@@ -206,7 +224,7 @@ func TestBatchGetUsers(t *testing.T) {
 
 	uc := NewUserController(mockUsecase, validator)
 
-	uc.BatchGetUsers(ctx)
+	serve(ctx, uc.BatchGetUsers)
 
 	assert.Equal(t, http.StatusOK, response.Code)
 
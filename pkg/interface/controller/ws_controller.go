@@ -1,14 +1,23 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
 )
 
+// maxReplayMessages caps the messages replayed to a reconnecting client.
+const maxReplayMessages = 200
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true
@@ -16,53 +25,142 @@ var upgrader = websocket.Upgrader{
 }
 
 type WSController struct {
-	HubManager *model.RoomHubManager
+	HubManager           *model.RoomHubManager
+	globalHub            model.Hub
+	authorizationUsecase usecase.AuthorizationUsecase
+	messageUsecase       usecase.MessageUsecase
+	presenceUsecase      usecase.PresenceUsecase
+	clientConfig         model.ClientConfig
+
+	// connections tracks the clients whose writers are still running, so Shutdown can wait for their close frames.
+	connections sync.WaitGroup
+	draining    bool
+	drainMu     sync.RWMutex
 }
 
-func NewWSController(hubManager *model.RoomHubManager) *WSController {
+func NewWSController(hubManager *model.RoomHubManager, globalHub model.Hub, authorizationUsecase usecase.AuthorizationUsecase, messageUsecase usecase.MessageUsecase, presenceUsecase usecase.PresenceUsecase, clientConfig model.ClientConfig) *WSController {
 	return &WSController{
-		HubManager: hubManager,
+		HubManager:           hubManager,
+		globalHub:            globalHub,
+		authorizationUsecase: authorizationUsecase,
+		messageUsecase:       messageUsecase,
+		presenceUsecase:      presenceUsecase,
+		clientConfig:         clientConfig,
 	}
 }
 
+// Shutdown rejects new connections, closes every connected client with a going away frame
+// and waits until the close frames are written or ctx is done.
+func (wc *WSController) Shutdown(ctx context.Context) error {
+	wc.drainMu.Lock()
+	wc.draining = true
+	wc.drainMu.Unlock()
+
+	wc.HubManager.Stop()
+	wc.globalHub.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		wc.connections.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// register must be called with drainMu read-locked, so that Shutdown waits until the client is registered.
+// The client reports its disconnection to the presence usecase itself.
+func (wc *WSController) register(hub model.Hub, client *model.Client) {
+	wc.connections.Add(1)
+	hub.RegisterClient(client)
+	wc.presenceUsecase.Connect(client)
+}
+
+func (wc *WSController) start(client *model.Client) {
+	go func() {
+		defer wc.connections.Done()
+		client.Write()
+	}()
+	go client.Read()
+}
+
 func (wc *WSController) HandleRoomConnection(ctx *gin.Context) {
+	roomId := ctx.Param("roomId")
+	if roomId == "" {
+		ctx.Error(apperror.NewValidationErr("Room", "roomId is required"))
+		return
+	}
+
+	userId, _ := authctx.UserID(ctx.Request.Context())
+	if _, err := wc.authorizationUsecase.AuthorizeRoomMember(ctx.Request.Context(), roomId, userId); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	wc.drainMu.RLock()
+	defer wc.drainMu.RUnlock()
+	if wc.draining {
+		ctx.Error(apperror.NewUnavailableErr("Server", "is shutting down"))
+		return
+	}
+
 	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
 	if err != nil {
 		log.Printf("Failed to set webscoket upgrade: %+v", err)
 		return
 	}
 
-	roomId := ctx.Param("roomId")
-	if roomId == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"message": "roomId is required"})
-		return
-	}
+	hub := wc.HubManager.GetOrCreateRoomHub(roomId)
+	client := model.NewClient(conn, hub, roomId, userId, wc.messageUsecase, wc.presenceUsecase, wc.clientConfig)
 
-	hub, exists := wc.HubManager.GetRoomHub(roomId)
-	if !exists {
-		hub = wc.HubManager.CreateRoomHub(roomId)
+	// The client is registered before the history is read, so that messages sent in between are
+	// queued as live events rather than lost; the client skips the ones that were replayed as well.
+	wc.register(hub, client)
+	if since := ctx.Query("since"); since != "" {
+		wc.replayHistory(ctx.Request.Context(), client, roomId, since)
 	}
-	client := model.NewClient(conn, hub)
+	wc.start(client)
+}
 
-	hub.RegisterClient(client)
+func (wc *WSController) replayHistory(ctx context.Context, client *model.Client, roomId, since string) {
+	messages, truncated, err := wc.messageUsecase.GetMessagesSince(ctx, roomId, since, maxReplayMessages)
+	if err != nil {
+		log.Printf("Failed to replay messages of room %s since %s: %v", roomId, since, err)
 
-	go client.Write()
-	go client.Read()
+		var notFoundErr *apperror.NotFoundErr
+		if errors.As(err, &notFoundErr) {
+			client.Close(websocket.ClosePolicyViolation, "unknown since cursor")
+			return
+		}
+		client.Close(websocket.CloseInternalServerErr, "failed to replay messages")
+		return
+	}
+
+	client.SetHistory(messages, truncated)
 }
 
 func (wc *WSController) HandleGlobalConnection(ctx *gin.Context) {
+	wc.drainMu.RLock()
+	defer wc.drainMu.RUnlock()
+	if wc.draining {
+		ctx.Error(apperror.NewUnavailableErr("Server", "is shutting down"))
+		return
+	}
+
 	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
 	if err != nil {
 		log.Printf("Failed to set webscoket upgrade: %+v", err)
 		return
 	}
 
-	globalHub := model.GetGlobalHubInstance()
-
-	client := model.NewClient(conn, globalHub)
+	userId, _ := authctx.UserID(ctx.Request.Context())
+	client := model.NewClient(conn, wc.globalHub, "", userId, nil, wc.presenceUsecase, wc.clientConfig)
 
-	globalHub.RegisterClient(client)
-
-	go client.Write()
-	go client.Read()
+	wc.register(wc.globalHub, client)
+	wc.start(client)
 }
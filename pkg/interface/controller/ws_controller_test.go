@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newPresenceUsecaseMock tracks connections without asserting on them.
+func newPresenceUsecaseMock() *mocks.PresenceUsecase {
+	presenceUsecase := new(mocks.PresenceUsecase)
+	presenceUsecase.On("Connect", mock.Anything).Maybe()
+	presenceUsecase.On("Disconnect", mock.Anything).Maybe()
+	return presenceUsecase
+}
+
+func TestHandleRoomConnection_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		mockErr      error
+		expectedCode int
+	}{
+		{
+			name:         "Not Member Of Public Room",
+			mockErr:      apperror.NewForbiddenErr("Room", "RoomID: 1"),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Not Member Of Private Room",
+			mockErr:      apperror.NewNotFoundErr("Room", "RoomID: 1"),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(mocks.AuthorizationUsecase)
+			mockUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "2").Return(nil, tc.mockErr)
+
+			wc := NewWSController(model.NewRoomHubManager(model.NewLocalBroadcaster(), 0), model.NewGlobalHub(model.NewLocalBroadcaster()), mockUsecase, nil, newPresenceUsecaseMock(), model.DefaultClientConfig())
+
+			request, ctx, response := prepareRequestAndContext(http.MethodGet, "/ws/1", gin.Params{{Key: "roomId", Value: "1"}}, nil)
+			ctx.Request = withUserID(request, "2")
+
+			serve(ctx, wc.HandleRoomConnection)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			_, exists := wc.HubManager.GetRoomHub("1")
+			assert.False(t, exists)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleRoomConnection_SendMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name              string
+		content           string
+		createErr         error
+		expectedType      model.EventType
+		expectedBroadcast bool
+	}{
+		{
+			name:              "Success",
+			content:           "Hello",
+			expectedType:      model.MessageAck,
+			expectedBroadcast: true,
+		},
+		{
+			name:              "Empty Content",
+			content:           "",
+			expectedType:      model.MessageError,
+			expectedBroadcast: false,
+		},
+		{
+			name:              "Create Failure",
+			content:           "Hello",
+			createErr:         errors.New("failed to create message"),
+			expectedType:      model.MessageError,
+			expectedBroadcast: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockAuthorizationUsecase := new(mocks.AuthorizationUsecase)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "2").Return(&model.RoomUser{RoomID: "1", UserID: "2"}, nil)
+
+			hubManager := model.NewRoomHubManager(model.NewLocalBroadcaster(), 0)
+			mockMessageUsecase := new(mocks.MessageUsecase)
+			if tc.content != "" {
+				mockMessageUsecase.On("CreateMessage", mock.Anything, mock.MatchedBy(func(message *model.Message) bool {
+					return message.RoomID == "1" && message.UserID == "2" && message.Content == tc.content
+				})).Run(func(args mock.Arguments) {
+					userId, _ := authctx.UserID(args.Get(0).(context.Context))
+					assert.Equal(t, "2", userId)
+					message := args.Get(1).(*model.Message)
+					message.MessageID = "generated"
+					if tc.createErr == nil {
+						hubManager.PublishToRoom(message.RoomID, message)
+					}
+				}).Return(tc.createErr)
+			}
+
+			wc := NewWSController(hubManager, model.NewGlobalHub(model.NewLocalBroadcaster()), mockAuthorizationUsecase, mockMessageUsecase, newPresenceUsecaseMock(), model.DefaultClientConfig())
+
+			router := gin.New()
+			router.Use(middleware.NewErrorMiddleware().HandleErrors)
+			router.GET("/ws/:roomId", func(ctx *gin.Context) {
+				ctx.Request = withUserID(ctx.Request, "2")
+			}, wc.HandleRoomConnection)
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/1", nil)
+			assert.NoError(t, err)
+			defer conn.Close()
+
+			data, _ := json.Marshal(&model.Message{MessageID: "spoofed", UserID: "3", Content: tc.content})
+			err = conn.WriteJSON(&model.RawEvent{Type: model.MessageSent, Data: data, CorrelationID: "c1"})
+			assert.NoError(t, err)
+
+			expectedFrames := 1
+			if tc.expectedBroadcast {
+				expectedFrames = 2
+			}
+			received := map[model.EventType]model.RawEvent{}
+			for i := 0; i < expectedFrames; i++ {
+				var rawEvent model.RawEvent
+				conn.SetReadDeadline(time.Now().Add(time.Second))
+				assert.NoError(t, conn.ReadJSON(&rawEvent))
+				received[rawEvent.Type] = rawEvent
+			}
+
+			reply, ok := received[tc.expectedType]
+			assert.True(t, ok)
+			assert.Equal(t, "c1", reply.CorrelationID)
+
+			if tc.expectedBroadcast {
+				var message model.Message
+				assert.NoError(t, json.Unmarshal(received[model.MessageSent].Data, &message))
+				assert.Equal(t, "generated", message.MessageID)
+				assert.Equal(t, "2", message.UserID)
+				assert.Equal(t, "1", message.RoomID)
+			}
+			mockMessageUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuthorizationUsecase := new(mocks.AuthorizationUsecase)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "2").Return(&model.RoomUser{RoomID: "1", UserID: "2"}, nil)
+
+	globalHub := model.NewGlobalHub(model.NewLocalBroadcaster())
+	go globalHub.Run()
+	wc := NewWSController(model.NewRoomHubManager(model.NewLocalBroadcaster(), 0), globalHub, mockAuthorizationUsecase, nil, newPresenceUsecaseMock(), model.DefaultClientConfig())
+
+	router := gin.New()
+	router.Use(middleware.NewErrorMiddleware().HandleErrors)
+	router.Use(func(ctx *gin.Context) {
+		ctx.Request = withUserID(ctx.Request, "2")
+	})
+	router.GET("/ws/:roomId", wc.HandleRoomConnection)
+	router.GET("/ws", wc.HandleGlobalConnection)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	var conns []*websocket.Conn
+	for _, path := range []string{"/ws/1", "/ws"} {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+path, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, wc.Shutdown(ctx))
+
+	for _, conn := range conns {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err := conn.ReadMessage()
+		closeErr, ok := err.(*websocket.CloseError)
+		assert.True(t, ok, "unexpected error: %v", err)
+		if ok {
+			assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+			assert.Equal(t, model.ShutdownCloseReason, closeErr.Text)
+		}
+	}
+
+	_, response, err := websocket.DefaultDialer.Dial(wsURL+"/ws/1", nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+}
+
+func TestHandleRoomConnection_ReplayHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	missed := []*model.Message{
+		{MessageID: "2", RoomID: "1", UserID: "3", Content: "missed"},
+		{MessageID: "3", RoomID: "1", UserID: "3", Content: "sent while replaying"},
+	}
+	live := &model.Message{MessageID: "4", RoomID: "1", UserID: "3", Content: "live"}
+
+	mockAuthorizationUsecase := new(mocks.AuthorizationUsecase)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "2").Return(&model.RoomUser{RoomID: "1", UserID: "2"}, nil)
+
+	hubManager := model.NewRoomHubManager(model.NewLocalBroadcaster(), 0)
+	mockMessageUsecase := new(mocks.MessageUsecase)
+	mockMessageUsecase.On("GetMessagesSince", mock.Anything, "1", "1", maxReplayMessages).Run(func(args mock.Arguments) {
+		// A message stored and broadcast while the history is read reaches the client both ways.
+		hubManager.PublishToRoom("1", missed[1])
+		hubManager.PublishToRoom("1", live)
+	}).Return(missed, false, nil)
+
+	wc := NewWSController(hubManager, model.NewGlobalHub(model.NewLocalBroadcaster()), mockAuthorizationUsecase, mockMessageUsecase, newPresenceUsecaseMock(), model.DefaultClientConfig())
+
+	router := gin.New()
+	router.Use(middleware.NewErrorMiddleware().HandleErrors)
+	router.GET("/ws/:roomId", func(ctx *gin.Context) {
+		ctx.Request = withUserID(ctx.Request, "2")
+	}, wc.HandleRoomConnection)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/1?since=1", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	var received []model.Event
+	for i := 0; i < 4; i++ {
+		var rawEvent model.RawEvent
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		assert.NoError(t, conn.ReadJSON(&rawEvent))
+		event, err := model.DecodeEvent(&rawEvent)
+		assert.NoError(t, err)
+		received = append(received, event)
+	}
+
+	assert.Equal(t, []model.Event{
+		missed[0],
+		missed[1],
+		&model.HistoryReplayedDetails{RoomID: "1", Count: 2, Truncated: false},
+		live,
+	}, received)
+	mockMessageUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedValue replaces the values of query parameters that must not be written to the access log.
+const redactedValue = "REDACTED"
+
+// AccessLogger logs requests in the format of gin's default logger, with the ID token a WebSocket upgrade
+// sends as a query parameter redacted, so that the log never holds a live credential.
+func AccessLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(formatAccessLog)
+}
+
+func formatAccessLog(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		redactPath(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactPath replaces the value of the token query parameter of a path. A query that cannot be parsed is
+// dropped altogether, as it cannot be told whether it holds a token.
+func redactPath(path string) string {
+	path, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return path
+	}
+	if _, ok := query[tokenQueryParam]; ok {
+		query.Set(tokenQueryParam, redactedValue)
+	}
+	return path + "?" + query.Encode()
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "No Query",
+			path:     "/rooms/1/messages",
+			expected: "/rooms/1/messages",
+		},
+		{
+			name:     "No Token",
+			path:     "/rooms/1/messages?limit=10",
+			expected: "/rooms/1/messages?limit=10",
+		},
+		{
+			name:     "Token",
+			path:     "/ws/1?token=eyJhbGciOiJSUzI1NiJ9.secret&since=2",
+			expected: "/ws/1?since=2&token=REDACTED",
+		},
+		{
+			name:     "Malformed Query",
+			path:     "/ws/1?token=eyJ%zz",
+			expected: "/ws/1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, redactPath(tc.path))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/auth"
+)
+
+// UserIDKey is the gin context key under which the verified user ID is stored.
+const UserIDKey = "userId"
+
+// tokenQueryParam is the query parameter the ID token is accepted through on WebSocket upgrades.
+const tokenQueryParam = "token"
+
+type AuthMiddleware struct {
+	firebaseAuth auth.FirebaseAuthenticator
+}
+
+func NewAuthMiddleware(firebaseAuth auth.FirebaseAuthenticator) *AuthMiddleware {
+	return &AuthMiddleware{
+		firebaseAuth,
+	}
+}
+
+// Authenticate verifies the Firebase ID token sent with the request and stores
+// the UID of its owner in both the gin context and the request context.
+// Browsers cannot set headers on a WebSocket upgrade, so the token is also
+// accepted through the "token" query parameter.
+func (am *AuthMiddleware) Authenticate(ctx *gin.Context) {
+	idToken := extractIDToken(ctx)
+	if idToken == "" {
+		_ = ctx.Error(apperror.NewUnauthenticatedErr("IDToken", "is required"))
+		ctx.Abort()
+		return
+	}
+
+	token, err := am.firebaseAuth.GetFirebaseUser(ctx.Request.Context(), idToken)
+	if err != nil || token == nil || token.UID == "" {
+		_ = ctx.Error(apperror.NewUnauthenticatedErr("IDToken", "is invalid"))
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set(UserIDKey, token.UID)
+	ctx.Request = ctx.Request.WithContext(authctx.WithUserID(ctx.Request.Context(), token.UID))
+
+	ctx.Next()
+}
+
+func extractIDToken(ctx *gin.Context) string {
+	header := ctx.GetHeader("Authorization")
+	if header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	return ctx.Query(tokenQueryParam)
+}
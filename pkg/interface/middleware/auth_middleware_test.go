@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"firebase.google.com/go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/auth/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stubVerifier struct {
+	tokens map[string]string
+}
+
+func (sv *stubVerifier) GetFirebaseUser(ctx context.Context, idToken string) (*auth.Token, error) {
+	uid, ok := sv.tokens[idToken]
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+	return &auth.Token{UID: uid}, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name           string
+		header         string
+		query          string
+		mockToken      string
+		mockReturn     *auth.Token
+		mockErr        error
+		expectedCode   int
+		expectedUserID string
+	}{
+		{
+			name:           "Bearer Token",
+			header:         "Bearer valid_token",
+			mockToken:      "valid_token",
+			mockReturn:     &auth.Token{UID: "1"},
+			expectedCode:   http.StatusOK,
+			expectedUserID: "1",
+		},
+		{
+			name:           "Query Token",
+			query:          "?token=valid_token",
+			mockToken:      "valid_token",
+			mockReturn:     &auth.Token{UID: "1"},
+			expectedCode:   http.StatusOK,
+			expectedUserID: "1",
+		},
+		{
+			name:         "Missing Token",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Invalid Scheme",
+			header:       "Basic valid_token",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Verification Failed",
+			header:       "Bearer invalid_token",
+			mockToken:    "invalid_token",
+			mockErr:      errors.New("some error"),
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockAuth := new(mocks.FirebaseAuthenticator)
+			if tc.mockToken != "" {
+				mockAuth.On("GetFirebaseUser", mock.Anything, tc.mockToken).Return(tc.mockReturn, tc.mockErr)
+			}
+
+			var gotUserID, gotCtxUserID string
+			router := gin.New()
+			router.Use(NewErrorMiddleware().HandleErrors)
+			router.Use(NewAuthMiddleware(mockAuth).Authenticate)
+			router.GET("/test", func(ctx *gin.Context) {
+				gotUserID = ctx.GetString(UserIDKey)
+				gotCtxUserID, _ = authctx.UserID(ctx.Request.Context())
+				ctx.Status(http.StatusOK)
+			})
+
+			request, _ := http.NewRequest(http.MethodGet, "/test"+tc.query, nil)
+			if tc.header != "" {
+				request.Header.Set("Authorization", tc.header)
+			}
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			assert.Equal(t, tc.expectedUserID, gotUserID)
+			assert.Equal(t, tc.expectedUserID, gotCtxUserID)
+			mockAuth.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthenticateWithStubVerifier(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	verifier := &stubVerifier{tokens: map[string]string{"token-1": "user-1"}}
+
+	router := gin.New()
+	router.Use(NewErrorMiddleware().HandleErrors)
+	router.Use(NewAuthMiddleware(verifier).Authenticate)
+	router.GET("/test", func(ctx *gin.Context) {
+		userId, _ := authctx.UserID(ctx.Request.Context())
+		ctx.String(http.StatusOK, userId)
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	request.Header.Set("Authorization", "Bearer token-1")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "user-1", response.Body.String())
+
+	request, _ = http.NewRequest(http.MethodGet, "/test", nil)
+	request.Header.Set("Authorization", "Bearer token-2")
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+}
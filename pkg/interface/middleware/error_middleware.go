@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+)
+
+// ErrorResponse is the envelope every error is answered with.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody is the error of the envelope. Fields is set for validation errors only.
+type ErrorBody struct {
+	Code    apperror.Code         `json:"code"`
+	Message string                `json:"message"`
+	Fields  []apperror.FieldError `json:"fields,omitempty"`
+}
+
+// internalErrorMessage replaces the message of errors outside the taxonomy, which may tell about the internals.
+const internalErrorMessage = "internal server error"
+
+type ErrorMiddleware struct{}
+
+func NewErrorMiddleware() *ErrorMiddleware {
+	return &ErrorMiddleware{}
+}
+
+// HandleErrors answers a request whose handlers attached an error to the context with ctx.Error instead of
+// writing a response, so that every error is answered with the same envelope and status.
+func (em *ErrorMiddleware) HandleErrors(ctx *gin.Context) {
+	ctx.Next()
+	RenderErrors(ctx)
+}
+
+// RenderErrors writes the last error attached to the context, unless a response has been written already.
+func RenderErrors(ctx *gin.Context) {
+	last := ctx.Errors.Last()
+	if last == nil || ctx.Writer.Written() {
+		return
+	}
+
+	code := apperror.CodeOf(last.Err)
+	message := last.Err.Error()
+	if code == apperror.CodeInternal {
+		log.Printf("Failed to handle %s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, last.Err)
+		message = internalErrorMessage
+	}
+
+	var rateLimitedErr *apperror.RateLimitedErr
+	if errors.As(last.Err, &rateLimitedErr) && rateLimitedErr.RetryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitedErr.RetryAfter.Seconds()))))
+	}
+
+	body := ErrorBody{Code: code, Message: message}
+	var validationErr *apperror.ValidationErr
+	if errors.As(last.Err, &validationErr) {
+		body.Fields = validationErr.Fields
+	}
+
+	ctx.AbortWithStatusJSON(StatusCode(code), ErrorResponse{Error: body})
+}
+
+// StatusCode returns the HTTP status an error of the code is answered with.
+func StatusCode(code apperror.Code) int {
+	switch code {
+	case apperror.CodeNotFound:
+		return http.StatusNotFound
+	case apperror.CodeConflict:
+		return http.StatusConflict
+	case apperror.CodeForbidden:
+		return http.StatusForbidden
+	case apperror.CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case apperror.CodeBadRequest, apperror.CodeValidation:
+		return http.StatusBadRequest
+	case apperror.CodeRateLimited:
+		return http.StatusTooManyRequests
+	case apperror.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name               string
+		err                error
+		expectedCode       int
+		expectedErrCode    apperror.Code
+		expectedMessage    string
+		expectedFields     []apperror.FieldError
+		expectedRetryAfter string
+	}{
+		{
+			name:            "Not Found",
+			err:             apperror.NewNotFoundErr("Room", "RoomID: 1"),
+			expectedCode:    http.StatusNotFound,
+			expectedErrCode: apperror.CodeNotFound,
+			expectedMessage: "Room RoomID: 1: not found",
+		},
+		{
+			name:            "Already Exists",
+			err:             apperror.NewAlreadyExistsErr("Room", "RoomName: lobby"),
+			expectedCode:    http.StatusConflict,
+			expectedErrCode: apperror.CodeConflict,
+			expectedMessage: "Room RoomName: lobby: already exists",
+		},
+		{
+			name:            "Forbidden",
+			err:             apperror.NewForbiddenErr("Room", "RoomID: 1"),
+			expectedCode:    http.StatusForbidden,
+			expectedErrCode: apperror.CodeForbidden,
+			expectedMessage: "Room RoomID: 1: forbidden",
+		},
+		{
+			name:            "Unauthenticated",
+			err:             apperror.NewUnauthenticatedErr("IDToken", "is required"),
+			expectedCode:    http.StatusUnauthorized,
+			expectedErrCode: apperror.CodeUnauthenticated,
+			expectedMessage: "IDToken is required: unauthenticated",
+		},
+		{
+			name:            "Validation",
+			err:             apperror.NewValidationErr("Query", "limit: many"),
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: apperror.CodeValidation,
+			expectedMessage: "Query limit: many: validation failed",
+		},
+		{
+			name:            "Validation of Fields",
+			err:             apperror.NewValidationErr("Request", "Fields: name", apperror.FieldError{Field: "name", Rule: "required", Message: "name is a required field"}),
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: apperror.CodeValidation,
+			expectedMessage: "Request Fields: name: validation failed",
+			expectedFields:  []apperror.FieldError{{Field: "name", Rule: "required", Message: "name is a required field"}},
+		},
+		{
+			name:               "Rate Limited",
+			err:                apperror.NewRateLimitedErr("Message", "UserID: 1", 1500*time.Millisecond),
+			expectedCode:       http.StatusTooManyRequests,
+			expectedErrCode:    apperror.CodeRateLimited,
+			expectedMessage:    "Message UserID: 1: rate limited",
+			expectedRetryAfter: "2",
+		},
+		{
+			name:            "Wrapped",
+			err:             fmt.Errorf("failed to fetch the room: %w", apperror.NewNotFoundErr("Room", "RoomID: 1")),
+			expectedCode:    http.StatusNotFound,
+			expectedErrCode: apperror.CodeNotFound,
+			expectedMessage: "failed to fetch the room: Room RoomID: 1: not found",
+		},
+		{
+			name:            "Internal",
+			err:             errors.New("dial tcp 10.0.0.1:8000: connection refused"),
+			expectedCode:    http.StatusInternalServerError,
+			expectedErrCode: apperror.CodeInternal,
+			expectedMessage: "internal server error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(NewErrorMiddleware().HandleErrors)
+			router.GET("/test", func(ctx *gin.Context) {
+				ctx.Error(tc.err)
+			})
+
+			request, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tc.expectedCode, response.Code)
+			assert.Equal(t, tc.expectedRetryAfter, response.Header().Get("Retry-After"))
+
+			var result ErrorResponse
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+			assert.Equal(t, tc.expectedErrCode, result.Error.Code)
+			assert.Equal(t, tc.expectedMessage, result.Error.Message)
+			assert.Equal(t, tc.expectedFields, result.Error.Fields)
+		})
+	}
+}
+
+func TestHandleErrors_ResponseWritten(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(NewErrorMiddleware().HandleErrors)
+	router.GET("/test", func(ctx *gin.Context) {
+		ctx.String(http.StatusAccepted, "accepted")
+		ctx.Error(errors.New("failed after responding"))
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.Equal(t, "accepted", response.Body.String())
+}
@@ -0,0 +1,6 @@
+package middleware
+
+type Middlewares struct {
+	AuthMiddleware  *AuthMiddleware
+	ErrorMiddleware *ErrorMiddleware
+}
@@ -3,10 +3,13 @@ package route
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/shunsukenagashima/chat-api/pkg/interface/controller"
+	"github.com/shunsukenagashima/chat-api/pkg/interface/middleware"
 )
 
-func RegisterRoutes(router *gin.Engine, controllers *controller.Controllers) {
-	apiGroup := router.Group("/api")
+func RegisterRoutes(router *gin.Engine, controllers *controller.Controllers, middlewares *middleware.Middlewares) {
+	router.Use(middlewares.ErrorMiddleware.HandleErrors)
+
+	apiGroup := router.Group("/api", middlewares.AuthMiddleware.Authenticate)
 	{
 		apiGroup.GET("/hello", controllers.HelloController.SayHello)
 		apiGroup.GET("/rooms/:roomId", controllers.RoomController.GetRoomByID)
@@ -22,12 +25,26 @@ func RegisterRoutes(router *gin.Engine, controllers *controller.Controllers) {
 		apiGroup.GET("/rooms/:roomId/users", controllers.RoomUserController.GetUsersByRoomID)
 		apiGroup.DELETE("/rooms/:roomId/users/:userId", controllers.RoomUserController.RemoveUserFromRoom)
 		apiGroup.POST("/rooms/:roomId/users", controllers.RoomUserController.AddUsersToRoom)
+		apiGroup.PUT("/rooms/:roomId/users/:userId/role", controllers.RoomUserController.UpdateUserRole)
+		apiGroup.PUT("/rooms/:roomId/owner", controllers.RoomUserController.TransferOwnership)
 		apiGroup.GET("/rooms/:roomId/messages", controllers.MessageController.GetMessagesByRoomID)
 		apiGroup.POST("/rooms/:roomId/messages", controllers.MessageController.CreateMessage)
+		apiGroup.GET("/rooms/:roomId/messages/:messageId/replies", controllers.MessageController.GetReplies)
 		apiGroup.PUT("/rooms/:roomId/messages/:messageId", controllers.MessageController.UpdateMessage)
 		apiGroup.DELETE("/rooms/:roomId/messages/:messageId", controllers.MessageController.DeleteMessage)
+		apiGroup.POST("/rooms/:roomId/messages/:messageId/reactions", controllers.ReactionController.AddReaction)
+		apiGroup.DELETE("/rooms/:roomId/messages/:messageId/reactions/:emoji", controllers.ReactionController.RemoveReaction)
+		apiGroup.POST("/rooms/:roomId/attachments", controllers.AttachmentController.CreateUpload)
+		apiGroup.GET("/rooms/:roomId/attachments/:attachmentId", controllers.AttachmentController.GetDownload)
+		apiGroup.PUT("/rooms/:roomId/read", controllers.MessageController.MarkAsRead)
+		apiGroup.GET("/rooms/:roomId/presence", controllers.PresenceController.GetRoomPresence)
+		apiGroup.GET("/users/:userId/presence", controllers.PresenceController.GetUserPresence)
+		apiGroup.GET("/search/messages", controllers.MessageController.SearchMessages)
 	}
 
-	router.GET("/ws/:roomId", controllers.WSController.HandleRoomConnection)
-	router.GET("/ws", controllers.WSController.HandleGlobalConnection)
+	wsGroup := router.Group("/ws", middlewares.AuthMiddleware.Authenticate)
+	{
+		wsGroup.GET("/:roomId", controllers.WSController.HandleRoomConnection)
+		wsGroup.GET("", controllers.WSController.HandleGlobalConnection)
+	}
 }
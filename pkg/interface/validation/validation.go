@@ -0,0 +1,186 @@
+package validation
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ja"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	ja_translations "github.com/go-playground/validator/v10/translations/ja"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+)
+
+var alnumDashRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// customTranslations are the messages of the rules the app registers itself, by locale.
+var customTranslations = map[string]map[string]string{
+	"en": {
+		"alnumdash": "{0} can only contain letters, numbers, underscores and hyphens",
+	},
+	"ja": {
+		"alnumdash": "{0}には英数字、アンダースコア、ハイフンのみ使用できます",
+	},
+}
+
+// Validator validates the requests the controllers bind, and reports the fields that fail in the language
+// the client accepts. English is the fallback.
+type Validator struct {
+	validate   *validator.Validate
+	translator *ut.UniversalTranslator
+}
+
+func NewValidator() (*Validator, error) {
+	validate := validator.New()
+
+	// fields are reported by the names the client sends them by
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	if err := validate.RegisterValidation("alnumdash", isAlnumOrDash); err != nil {
+		return nil, err
+	}
+
+	english := en.New()
+	translator := ut.New(english, english, ja.New())
+
+	enTrans, _ := translator.GetTranslator("en")
+	if err := en_translations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return nil, err
+	}
+	jaTrans, _ := translator.GetTranslator("ja")
+	if err := ja_translations.RegisterDefaultTranslations(validate, jaTrans); err != nil {
+		return nil, err
+	}
+
+	for locale, translations := range customTranslations {
+		trans, _ := translator.GetTranslator(locale)
+		for tag, text := range translations {
+			if err := validate.RegisterTranslation(tag, trans, registerTranslation(tag, text), translateField); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &Validator{
+		validate:   validate,
+		translator: translator,
+	}, nil
+}
+
+// BindJSON decodes the body of the request into req and validates it. A body that cannot be decoded and fields
+// that fail validation are both returned as an *apperror.ValidationErr, the latter with the fields listed in the
+// language of the Accept-Language header.
+func (v *Validator) BindJSON(ctx *gin.Context, req interface{}) error {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		return apperror.NewValidationErr("Request", "Body: "+err.Error())
+	}
+
+	return v.Struct(req, ctx.GetHeader("Accept-Language"))
+}
+
+// Struct validates s, and lists the fields that fail in the first language of acceptLanguage that is supported.
+func (v *Validator) Struct(s interface{}, acceptLanguage string) error {
+	err := v.validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	trans, _ := v.translator.FindTranslator(parseAcceptLanguage(acceptLanguage)...)
+
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	names := make([]string, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		field := fieldPath(fe)
+		fields = append(fields, apperror.FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		})
+		names = append(names, field)
+	}
+
+	return apperror.NewValidationErr("Request", "Fields: "+strings.Join(names, ", "), fields...)
+}
+
+// fieldPath is the path of the field in the JSON of the request, such as "attachmentIds[0]". The namespace
+// starts with the name of the struct validated, which is not part of the JSON.
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+// parseAcceptLanguage returns the locales of an Accept-Language header in order of preference. A locale with
+// a region is followed by its language, so that "ja-JP" finds the translator of "ja".
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		locale string
+		q      float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		languages = append(languages, language{locale: locale, q: q})
+	}
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].q > languages[j].q })
+
+	var locales []string
+	for _, language := range languages {
+		locale := strings.ReplaceAll(language.locale, "-", "_")
+		locales = append(locales, locale)
+		if base, _, found := strings.Cut(locale, "_"); found {
+			locales = append(locales, base)
+		}
+	}
+	return locales
+}
+
+func isAlnumOrDash(fl validator.FieldLevel) bool {
+	return alnumDashRegexp.MatchString(fl.Field().String())
+}
+
+func registerTranslation(tag, text string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, text, false)
+	}
+}
+
+func translateField(trans ut.Translator, fe validator.FieldError) string {
+	message, err := trans.T(fe.Tag(), fe.Field())
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	Name          string   `json:"name" validate:"required,max=30,alnumdash"`
+	RoomType      string   `json:"roomType" validate:"required,oneof=public private"`
+	AttachmentIDs []string `json:"attachmentIds" validate:"dive,required"`
+}
+
+func TestStruct(t *testing.T) {
+	v, err := NewValidator()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		req            testRequest
+		acceptLanguage string
+		expectedErr    error
+	}{
+		{
+			name: "Valid",
+			req:  testRequest{Name: "lobby", RoomType: "public"},
+		},
+		{
+			name:           "English",
+			req:            testRequest{Name: "the lobby", RoomType: "secret"},
+			acceptLanguage: "en-US",
+			expectedErr: apperror.NewValidationErr("Request", "Fields: name, roomType",
+				apperror.FieldError{Field: "name", Rule: "alnumdash", Message: "name can only contain letters, numbers, underscores and hyphens"},
+				apperror.FieldError{Field: "roomType", Rule: "oneof", Param: "public private", Message: "roomType must be one of [public private]"},
+			),
+		},
+		{
+			name:           "Japanese",
+			req:            testRequest{Name: "the lobby", RoomType: "secret"},
+			acceptLanguage: "ja-JP,ja;q=0.9,en;q=0.8",
+			expectedErr: apperror.NewValidationErr("Request", "Fields: name, roomType",
+				apperror.FieldError{Field: "name", Rule: "alnumdash", Message: "nameには英数字、アンダースコア、ハイフンのみ使用できます"},
+				apperror.FieldError{Field: "roomType", Rule: "oneof", Param: "public private", Message: "roomTypeは[public private]のうちのいずれかでなければなりません"},
+			),
+		},
+		{
+			name:           "Unsupported Language Falls Back to English",
+			req:            testRequest{RoomType: "public"},
+			acceptLanguage: "fr-CH, fr;q=0.9",
+			expectedErr: apperror.NewValidationErr("Request", "Fields: name",
+				apperror.FieldError{Field: "name", Rule: "required", Message: "name is a required field"},
+			),
+		},
+		{
+			name: "Element of a List",
+			req:  testRequest{Name: "lobby", RoomType: "public", AttachmentIDs: []string{"1", ""}},
+			expectedErr: apperror.NewValidationErr("Request", "Fields: attachmentIds[1]",
+				apperror.FieldError{Field: "attachmentIds[1]", Rule: "required", Message: "attachmentIds[1] is a required field"},
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := v.Struct(tc.req, tc.acceptLanguage)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
+
+func TestBindJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	v, err := NewValidator()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		body           string
+		expectedFields []apperror.FieldError
+		expectedErr    bool
+	}{
+		{
+			name: "Valid",
+			body: `{"name": "lobby", "roomType": "private"}`,
+		},
+		{
+			name:        "Malformed Body",
+			body:        `{"name": `,
+			expectedErr: true,
+		},
+		{
+			name: "Invalid Field",
+			body: `{"name": "lobby"}`,
+			expectedFields: []apperror.FieldError{
+				{Field: "roomType", Rule: "required", Message: "roomTypeは必須フィールドです"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodPost, "/rooms", bytes.NewBufferString(tc.body))
+			request.Header.Set("Accept-Language", "ja")
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = request
+
+			var req testRequest
+			err := v.BindJSON(ctx, &req)
+			if !tc.expectedErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *apperror.ValidationErr
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.expectedFields, validationErr.Fields)
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected []string
+	}{
+		{header: "", expected: nil},
+		{header: "ja", expected: []string{"ja"}},
+		{header: "en;q=0.5, ja-JP", expected: []string{"ja_JP", "ja", "en"}},
+		{header: "*, fr;q=0.9", expected: []string{"fr"}},
+		{header: "en;q=high, ja", expected: []string{"ja"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseAcceptLanguage(tc.header))
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+)
+
+const (
+	maxAttachmentsPerMessage = 10
+	orphanCleanupBatchSize   = 100
+)
+
+type AttachmentUsecaseImpl struct {
+	attachmentRepo       repository.AttachmentRepository
+	attachmentStorage    repository.AttachmentStorage
+	authorizationUsecase usecase.AuthorizationUsecase
+	config               model.AttachmentConfig
+	clocker              clock.Clocker
+}
+
+func NewAttachmentUsecase(attachmentRepo repository.AttachmentRepository, attachmentStorage repository.AttachmentStorage, authorizationUsecase usecase.AuthorizationUsecase, config model.AttachmentConfig, clocker clock.Clocker) usecase.AttachmentUsecase {
+	return &AttachmentUsecaseImpl{
+		attachmentRepo:       attachmentRepo,
+		attachmentStorage:    attachmentStorage,
+		authorizationUsecase: authorizationUsecase,
+		config:               config,
+		clocker:              clocker,
+	}
+}
+
+func (au *AttachmentUsecaseImpl) CreateUpload(ctx context.Context, roomId, name, contentType string, size int64) (*model.AttachmentUpload, error) {
+	roomUser, err := authorizeCaller(ctx, au.authorizationUsecase, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if fileName == "." || fileName == "/" {
+		return nil, apperror.NewBadRequestErr("Attachment", "Name: "+name)
+	}
+	if size <= 0 || size > au.config.MaxSize {
+		return nil, apperror.NewBadRequestErr("Attachment", fmt.Sprintf("Size: %d exceeds the limit of %d bytes", size, au.config.MaxSize))
+	}
+	if !au.config.AllowsContentType(contentType) {
+		return nil, apperror.NewBadRequestErr("Attachment", "ContentType: "+contentType+" is not allowed")
+	}
+
+	attachmentId := uuid.New().String()
+	attachment := &model.Attachment{
+		AttachmentID: attachmentId,
+		RoomID:       roomId,
+		UserID:       roomUser.UserID,
+		Name:         fileName,
+		Size:         size,
+		ContentType:  contentType,
+		ObjectKey:    "rooms/" + roomId + "/" + attachmentId + "/" + fileName,
+		Status:       model.Pending,
+		CreatedAt:    au.clocker.Now(),
+	}
+
+	if err := au.attachmentRepo.Create(ctx, attachment); err != nil {
+		return nil, err
+	}
+
+	uploadURL, err := au.attachmentStorage.PresignUpload(ctx, attachment.ObjectKey, contentType, size, au.config.UploadExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AttachmentUpload{
+		Attachment: attachment,
+		UploadURL:  uploadURL,
+		ExpiresAt:  attachment.CreatedAt.Add(au.config.UploadExpiry),
+	}, nil
+}
+
+// GetDownload presigns a download of the attachment for a member of the room it was uploaded to.
+func (au *AttachmentUsecaseImpl) GetDownload(ctx context.Context, roomId, attachmentId string) (*model.AttachmentDownload, error) {
+	caller, err := authorizeCaller(ctx, au.authorizationUsecase, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := au.attachmentRepo.GetByID(ctx, attachmentId)
+	if err != nil {
+		return nil, err
+	}
+	// attachments of other rooms, and uploads of other members not attached to a message yet,
+	// are reported as missing so their IDs cannot be probed
+	if attachment.RoomID != roomId || (attachment.Status != model.Attached && attachment.UserID != caller.UserID) {
+		return nil, apperror.NewNotFoundErr("Attachment", "AttachmentID: "+attachmentId)
+	}
+
+	downloadURL, err := au.attachmentStorage.PresignDownload(ctx, attachment.ObjectKey, attachment.Name, au.config.DownloadExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AttachmentDownload{
+		DownloadURL: downloadURL,
+		ExpiresAt:   au.clocker.Now().Add(au.config.DownloadExpiry),
+	}, nil
+}
+
+// PrepareAttachments checks every attachment before the message is stored, so a rejected message claims no uploads.
+// The uploaded files are checked as well as the records, since clients could upload something else than they declared.
+func (au *AttachmentUsecaseImpl) PrepareAttachments(ctx context.Context, message *model.Message) error {
+	if len(message.Attachments) == 0 {
+		return nil
+	}
+	if len(message.Attachments) > maxAttachmentsPerMessage {
+		return apperror.NewBadRequestErr("Message", fmt.Sprintf("Attachments: more than %d", maxAttachmentsPerMessage))
+	}
+
+	attachments := make([]*model.Attachment, 0, len(message.Attachments))
+	seen := make(map[string]bool)
+	for _, requested := range message.Attachments {
+		if seen[requested.AttachmentID] {
+			continue
+		}
+		seen[requested.AttachmentID] = true
+
+		attachment, err := au.pendingAttachment(ctx, message, requested.AttachmentID)
+		if err != nil {
+			return err
+		}
+		attachment.MessageID = message.MessageID
+		attachment.Status = model.Attached
+		attachments = append(attachments, attachment)
+	}
+
+	message.Attachments = attachments
+	return nil
+}
+
+// AttachToMessage fails when an attachment was claimed by another message since it was prepared, in which case
+// the attachments linked before it stay linked to this message.
+func (au *AttachmentUsecaseImpl) AttachToMessage(ctx context.Context, message *model.Message) error {
+	for _, attachment := range message.Attachments {
+		if err := au.attachmentRepo.MarkAttached(ctx, attachment.AttachmentID, message.MessageID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteForMessage leaves out the attachments of the message that are gone already or linked to another message,
+// so that it can be retried and can roll back a message whose attachments were claimed by another.
+func (au *AttachmentUsecaseImpl) DeleteForMessage(ctx context.Context, message *model.Message) error {
+	for _, requested := range message.Attachments {
+		attachment, err := au.attachmentRepo.GetByID(ctx, requested.AttachmentID)
+		if err != nil {
+			var notFoundErr *apperror.NotFoundErr
+			if errors.As(err, &notFoundErr) {
+				continue
+			}
+			return err
+		}
+		if attachment.Status != model.Attached || attachment.MessageID != message.MessageID {
+			continue
+		}
+
+		if err := au.attachmentStorage.Delete(ctx, attachment.ObjectKey); err != nil {
+			return err
+		}
+		if err := au.attachmentRepo.Delete(ctx, attachment.AttachmentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (au *AttachmentUsecaseImpl) pendingAttachment(ctx context.Context, message *model.Message, attachmentId string) (*model.Attachment, error) {
+	attachment, err := au.attachmentRepo.GetByID(ctx, attachmentId)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.RoomID != message.RoomID || attachment.UserID != message.UserID {
+		return nil, apperror.NewNotFoundErr("Attachment", "AttachmentID: "+attachmentId)
+	}
+	if attachment.Status != model.Pending {
+		return nil, apperror.NewBadRequestErr("Attachment", "AttachmentID: "+attachmentId+" is already attached")
+	}
+
+	object, err := au.attachmentStorage.Stat(ctx, attachment.ObjectKey)
+	if err != nil {
+		var notFoundErr *apperror.NotFoundErr
+		if errors.As(err, &notFoundErr) {
+			return nil, apperror.NewBadRequestErr("Attachment", "AttachmentID: "+attachmentId+" was not uploaded")
+		}
+		return nil, err
+	}
+	if object.Size != attachment.Size || object.Size > au.config.MaxSize || object.ContentType != attachment.ContentType {
+		return nil, apperror.NewBadRequestErr("Attachment", "AttachmentID: "+attachmentId+" does not match its upload")
+	}
+
+	return attachment, nil
+}
+
+// CleanupOrphaned deletes pending attachments older than the orphan TTL along with their uploads.
+// The TTL is far longer than the upload URLs are valid, so nothing can be uploaded to an attachment once it is deleted.
+func (au *AttachmentUsecaseImpl) CleanupOrphaned(ctx context.Context) (int, error) {
+	before := au.clocker.Now().Add(-au.config.OrphanTTL)
+
+	deleted := 0
+	for {
+		attachments, err := au.attachmentRepo.GetPendingBefore(ctx, before, orphanCleanupBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, attachment := range attachments {
+			if err := au.attachmentStorage.Delete(ctx, attachment.ObjectKey); err != nil {
+				return deleted, err
+			}
+			if err := au.attachmentRepo.Delete(ctx, attachment.AttachmentID); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+
+		if len(attachments) < orphanCleanupBatchSize {
+			return deleted, nil
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository/mocks"
+	usecaseMocks "github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateUpload(t *testing.T) {
+	clock := clock.FixedClocker{}
+	config := model.DefaultAttachmentConfig()
+
+	testCases := []struct {
+		name         string
+		fileName     string
+		contentType  string
+		size         int64
+		expectedName string
+		expectedErr  error
+	}{
+		{
+			name:         "Success",
+			fileName:     "cat.png",
+			contentType:  "image/png",
+			size:         1024,
+			expectedName: "cat.png",
+		},
+		{
+			name:         "Name With Path",
+			fileName:     "../../etc/cat.png",
+			contentType:  "image/png",
+			size:         1024,
+			expectedName: "cat.png",
+		},
+		{
+			name:        "Too Large",
+			fileName:    "cat.png",
+			contentType: "image/png",
+			size:        config.MaxSize + 1,
+			expectedErr: apperror.NewBadRequestErr("Attachment", "Size: 10485761 exceeds the limit of 10485760 bytes"),
+		},
+		{
+			name:        "Content Type Not Allowed",
+			fileName:    "cat.exe",
+			contentType: "application/x-msdownload",
+			size:        1024,
+			expectedErr: apperror.NewBadRequestErr("Attachment", "ContentType: application/x-msdownload is not allowed"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockAttachmentRepo := new(mocks.AttachmentRepository)
+			mockStorage := new(mocks.AttachmentStorage)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "2").Return(&model.RoomUser{RoomID: "1", UserID: "2"}, nil)
+			mockAttachmentRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Attachment")).Return(nil)
+			mockStorage.On("PresignUpload", mock.Anything, mock.Anything, tc.contentType, tc.size, config.UploadExpiry).Return("https://upload", nil)
+			attachmentUsecase := NewAttachmentUsecase(mockAttachmentRepo, mockStorage, mockAuthorizationUsecase, config, clock)
+
+			ctx := authctx.WithUserID(context.Background(), "2")
+			upload, err := attachmentUsecase.CreateUpload(ctx, "1", tc.fileName, tc.contentType, tc.size)
+
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr != nil {
+				mockAttachmentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			attachment := upload.Attachment
+			assert.Equal(t, "https://upload", upload.UploadURL)
+			assert.Equal(t, clock.Now().Add(config.UploadExpiry), upload.ExpiresAt)
+			assert.Equal(t, tc.expectedName, attachment.Name)
+			assert.Equal(t, "2", attachment.UserID)
+			assert.Equal(t, model.Pending, attachment.Status)
+			assert.Equal(t, "rooms/1/"+attachment.AttachmentID+"/"+tc.expectedName, attachment.ObjectKey)
+			mockStorage.AssertCalled(t, "PresignUpload", mock.Anything, attachment.ObjectKey, tc.contentType, tc.size, config.UploadExpiry)
+		})
+	}
+}
+
+func TestGetDownload(t *testing.T) {
+	clock := clock.FixedClocker{}
+	config := model.DefaultAttachmentConfig()
+
+	testCases := []struct {
+		name        string
+		callerId    string
+		roomId      string
+		uploaderId  string
+		status      model.AttachmentStatus
+		expectedErr error
+	}{
+		{
+			name:       "Success",
+			callerId:   "1",
+			roomId:     "1",
+			uploaderId: "2",
+			status:     model.Attached,
+		},
+		{
+			name:       "Pending Upload Of The Caller",
+			callerId:   "1",
+			roomId:     "1",
+			uploaderId: "1",
+			status:     model.Pending,
+		},
+		{
+			name:        "Pending Upload Of Another Member",
+			callerId:    "1",
+			roomId:      "1",
+			uploaderId:  "2",
+			status:      model.Pending,
+			expectedErr: apperror.NewNotFoundErr("Attachment", "AttachmentID: 1"),
+		},
+		{
+			name:        "Not Member",
+			callerId:    "3",
+			roomId:      "1",
+			uploaderId:  "3",
+			status:      model.Attached,
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1"),
+		},
+		{
+			name:        "Attachment Of Another Room",
+			callerId:    "1",
+			roomId:      "2",
+			uploaderId:  "2",
+			status:      model.Attached,
+			expectedErr: apperror.NewNotFoundErr("Attachment", "AttachmentID: 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockAttachmentRepo := new(mocks.AttachmentRepository)
+			mockStorage := new(mocks.AttachmentStorage)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, tc.roomId, "1").Return(&model.RoomUser{RoomID: tc.roomId, UserID: "1"}, nil)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "3").Return(nil, apperror.NewForbiddenErr("Room", "RoomID: 1"))
+			mockAttachmentRepo.On("GetByID", mock.Anything, "1").Return(&model.Attachment{AttachmentID: "1", RoomID: "1", UserID: tc.uploaderId, Name: "cat.png", ObjectKey: "rooms/1/1/cat.png", Status: tc.status}, nil)
+			mockStorage.On("PresignDownload", mock.Anything, "rooms/1/1/cat.png", "cat.png", config.DownloadExpiry).Return("https://download", nil)
+			attachmentUsecase := NewAttachmentUsecase(mockAttachmentRepo, mockStorage, mockAuthorizationUsecase, config, clock)
+
+			ctx := authctx.WithUserID(context.Background(), tc.callerId)
+			download, err := attachmentUsecase.GetDownload(ctx, tc.roomId, "1")
+
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr != nil {
+				mockStorage.AssertNotCalled(t, "PresignDownload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, &model.AttachmentDownload{DownloadURL: "https://download", ExpiresAt: clock.Now().Add(config.DownloadExpiry)}, download)
+		})
+	}
+}
+
+func TestPrepareAttachments(t *testing.T) {
+	config := model.DefaultAttachmentConfig()
+	pending := func() *model.Attachment {
+		return &model.Attachment{
+			AttachmentID: "1",
+			RoomID:       "1",
+			UserID:       "2",
+			Name:         "cat.png",
+			Size:         1024,
+			ContentType:  "image/png",
+			ObjectKey:    "rooms/1/1/cat.png",
+			Status:       model.Pending,
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		attachment  func() *model.Attachment
+		object      *model.ObjectInfo
+		statErr     error
+		expectedErr error
+	}{
+		{
+			name:       "Success",
+			attachment: pending,
+			object:     &model.ObjectInfo{Size: 1024, ContentType: "image/png"},
+		},
+		{
+			name: "Uploaded By Someone Else",
+			attachment: func() *model.Attachment {
+				attachment := pending()
+				attachment.UserID = "3"
+				return attachment
+			},
+			expectedErr: apperror.NewNotFoundErr("Attachment", "AttachmentID: 1"),
+		},
+		{
+			name: "Already Attached",
+			attachment: func() *model.Attachment {
+				attachment := pending()
+				attachment.Status = model.Attached
+				return attachment
+			},
+			expectedErr: apperror.NewBadRequestErr("Attachment", "AttachmentID: 1 is already attached"),
+		},
+		{
+			name:        "Not Uploaded",
+			attachment:  pending,
+			statErr:     apperror.NewNotFoundErr("Object", "Key: rooms/1/1/cat.png"),
+			expectedErr: apperror.NewBadRequestErr("Attachment", "AttachmentID: 1 was not uploaded"),
+		},
+		{
+			name:        "Upload Larger Than Declared",
+			attachment:  pending,
+			object:      &model.ObjectInfo{Size: 4096, ContentType: "image/png"},
+			expectedErr: apperror.NewBadRequestErr("Attachment", "AttachmentID: 1 does not match its upload"),
+		},
+		{
+			name:        "Upload Of Another Type",
+			attachment:  pending,
+			object:      &model.ObjectInfo{Size: 1024, ContentType: "text/html"},
+			expectedErr: apperror.NewBadRequestErr("Attachment", "AttachmentID: 1 does not match its upload"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockAttachmentRepo := new(mocks.AttachmentRepository)
+			mockStorage := new(mocks.AttachmentStorage)
+			mockAttachmentRepo.On("GetByID", mock.Anything, "1").Return(tc.attachment(), nil)
+			mockStorage.On("Stat", mock.Anything, "rooms/1/1/cat.png").Return(tc.object, tc.statErr)
+			attachmentUsecase := NewAttachmentUsecase(mockAttachmentRepo, mockStorage, new(usecaseMocks.AuthorizationUsecase), config, clock.FixedClocker{})
+
+			message := &model.Message{
+				MessageID:   "10",
+				RoomID:      "1",
+				UserID:      "2",
+				Attachments: []*model.Attachment{{AttachmentID: "1"}, {AttachmentID: "1"}},
+			}
+			err := attachmentUsecase.PrepareAttachments(context.Background(), message)
+
+			assert.Equal(t, tc.expectedErr, err)
+			mockAttachmentRepo.AssertNotCalled(t, "MarkAttached", mock.Anything, mock.Anything, mock.Anything)
+			if tc.expectedErr != nil {
+				return
+			}
+			expected := pending()
+			expected.MessageID = "10"
+			expected.Status = model.Attached
+			assert.Equal(t, []*model.Attachment{expected}, message.Attachments)
+		})
+	}
+}
+
+func TestAttachToMessage(t *testing.T) {
+	mockAttachmentRepo := new(mocks.AttachmentRepository)
+	mockAttachmentRepo.On("MarkAttached", mock.Anything, "1", "10").Return(nil)
+	mockAttachmentRepo.On("MarkAttached", mock.Anything, "2", "10").Return(apperror.NewNotFoundErr("Attachment", "AttachmentID: 2"))
+	attachmentUsecase := NewAttachmentUsecase(mockAttachmentRepo, new(mocks.AttachmentStorage), new(usecaseMocks.AuthorizationUsecase), model.DefaultAttachmentConfig(), clock.FixedClocker{})
+
+	message := &model.Message{
+		MessageID:   "10",
+		Attachments: []*model.Attachment{{AttachmentID: "1"}, {AttachmentID: "2"}, {AttachmentID: "3"}},
+	}
+	err := attachmentUsecase.AttachToMessage(context.Background(), message)
+
+	assert.Equal(t, apperror.NewNotFoundErr("Attachment", "AttachmentID: 2"), err)
+	mockAttachmentRepo.AssertNotCalled(t, "MarkAttached", mock.Anything, "3", mock.Anything)
+}
+
+func TestDeleteForMessage(t *testing.T) {
+	mockAttachmentRepo := new(mocks.AttachmentRepository)
+	mockStorage := new(mocks.AttachmentStorage)
+	mockAttachmentRepo.On("GetByID", mock.Anything, "1").Return(&model.Attachment{AttachmentID: "1", MessageID: "10", ObjectKey: "rooms/1/1/a.png", Status: model.Attached}, nil)
+	mockAttachmentRepo.On("GetByID", mock.Anything, "2").Return(&model.Attachment{AttachmentID: "2", MessageID: "11", ObjectKey: "rooms/1/2/b.png", Status: model.Attached}, nil)
+	mockAttachmentRepo.On("GetByID", mock.Anything, "3").Return(&model.Attachment{AttachmentID: "3", ObjectKey: "rooms/1/3/c.png", Status: model.Pending}, nil)
+	mockAttachmentRepo.On("GetByID", mock.Anything, "4").Return(nil, apperror.NewNotFoundErr("Attachment", "AttachmentID: 4"))
+	mockAttachmentRepo.On("Delete", mock.Anything, "1").Return(nil)
+	mockStorage.On("Delete", mock.Anything, "rooms/1/1/a.png").Return(nil)
+	attachmentUsecase := NewAttachmentUsecase(mockAttachmentRepo, mockStorage, new(usecaseMocks.AuthorizationUsecase), model.DefaultAttachmentConfig(), clock.FixedClocker{})
+
+	message := &model.Message{
+		MessageID:   "10",
+		Attachments: []*model.Attachment{{AttachmentID: "1"}, {AttachmentID: "2"}, {AttachmentID: "3"}, {AttachmentID: "4"}},
+	}
+	err := attachmentUsecase.DeleteForMessage(context.Background(), message)
+
+	assert.NoError(t, err)
+	mockAttachmentRepo.AssertNumberOfCalls(t, "Delete", 1)
+	mockStorage.AssertNumberOfCalls(t, "Delete", 1)
+	mockAttachmentRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestCleanupOrphaned(t *testing.T) {
+	clock := clock.FixedClocker{}
+	config := model.DefaultAttachmentConfig()
+	before := clock.Now().Add(-config.OrphanTTL)
+
+	fullBatch := make([]*model.Attachment, orphanCleanupBatchSize)
+	for i := range fullBatch {
+		fullBatch[i] = &model.Attachment{AttachmentID: "full", ObjectKey: "rooms/1/full/a.png"}
+	}
+
+	mockAttachmentRepo := new(mocks.AttachmentRepository)
+	mockStorage := new(mocks.AttachmentStorage)
+	mockAttachmentRepo.On("GetPendingBefore", mock.Anything, before, orphanCleanupBatchSize).Return(fullBatch, nil).Once()
+	mockAttachmentRepo.On("GetPendingBefore", mock.Anything, before, orphanCleanupBatchSize).Return([]*model.Attachment{
+		{AttachmentID: "last", ObjectKey: "rooms/1/last/b.png"},
+	}, nil).Once()
+	mockAttachmentRepo.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	mockStorage.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	attachmentUsecase := NewAttachmentUsecase(mockAttachmentRepo, mockStorage, new(usecaseMocks.AuthorizationUsecase), config, clock)
+
+	deleted, err := attachmentUsecase.CleanupOrphaned(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, orphanCleanupBatchSize+1, deleted)
+	mockStorage.AssertCalled(t, "Delete", mock.Anything, "rooms/1/last/b.png")
+	mockAttachmentRepo.AssertCalled(t, "Delete", mock.Anything, "last")
+	mockAttachmentRepo.AssertExpectations(t)
+}
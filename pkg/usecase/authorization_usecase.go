@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+)
+
+type AuthorizationUsecaseImpl struct {
+	roomRepo     repository.RoomRepository
+	roomUserRepo repository.RoomUserRepository
+}
+
+func NewAuthorizationUsecase(roomRepo repository.RoomRepository, roomUserRepo repository.RoomUserRepository) usecase.AuthorizationUsecase {
+	return &AuthorizationUsecaseImpl{
+		roomRepo,
+		roomUserRepo,
+	}
+}
+
+// AuthorizeRoomMember returns the membership of the user in the room.
+// Non-members get a ForbiddenErr for public rooms and a NotFoundErr for
+// private rooms, so that private rooms are not revealed to outsiders.
+func (au *AuthorizationUsecaseImpl) AuthorizeRoomMember(ctx context.Context, roomId, userId string) (*model.RoomUser, error) {
+	if userId == "" {
+		return nil, apperror.NewForbiddenErr("Room", "RoomID: "+roomId)
+	}
+
+	roomUser, err := au.roomUserRepo.GetByRoomIDAndUserID(ctx, roomId, userId)
+	if err == nil {
+		return roomUser, nil
+	}
+
+	var notFoundErr *apperror.NotFoundErr
+	if !errors.As(err, &notFoundErr) {
+		return nil, err
+	}
+
+	room, err := au.roomRepo.GetByID(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+	if room.RoomType == model.Private {
+		return nil, apperror.NewNotFoundErr("Room", "RoomID: "+roomId)
+	}
+
+	return nil, apperror.NewForbiddenErr("Room", "RoomID: "+roomId)
+}
+
+// AuthorizeRoomRole returns the membership of the user in the room if the user
+// holds one of the given roles, and a ForbiddenErr if the user is a member without them.
+func (au *AuthorizationUsecaseImpl) AuthorizeRoomRole(ctx context.Context, roomId, userId string, roles ...model.RoomRole) (*model.RoomUser, error) {
+	roomUser, err := au.AuthorizeRoomMember(ctx, roomId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		if roomUser.EffectiveRole() == role {
+			return roomUser, nil
+		}
+	}
+
+	return nil, apperror.NewForbiddenErr("Room", "RoomID: "+roomId+", Role: "+string(roomUser.EffectiveRole()))
+}
+
+// authorizeCaller authorizes the user authenticated on ctx as a member of the room.
+func authorizeCaller(ctx context.Context, authorizationUsecase usecase.AuthorizationUsecase, roomId string) (*model.RoomUser, error) {
+	userId, _ := authctx.UserID(ctx)
+	return authorizationUsecase.AuthorizeRoomMember(ctx, roomId, userId)
+}
+
+// authorizeCallerRole authorizes the user authenticated on ctx as a member of the room holding one of the roles.
+func authorizeCallerRole(ctx context.Context, authorizationUsecase usecase.AuthorizationUsecase, roomId string, roles ...model.RoomRole) (*model.RoomUser, error) {
+	userId, _ := authctx.UserID(ctx)
+	return authorizationUsecase.AuthorizeRoomRole(ctx, roomId, userId, roles...)
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthorizeRoomMember(t *testing.T) {
+	roomUser := &model.RoomUser{
+		RoomID: "1",
+		UserID: "1",
+	}
+
+	testCases := []struct {
+		name               string
+		userId             string
+		mockRoomUserReturn *model.RoomUser
+		mockRoomUserErr    error
+		mockRoom           *model.Room
+		mockRoomErr        error
+		expectedReturn     *model.RoomUser
+		expectedErr        error
+	}{
+		{
+			name:               "Member",
+			userId:             "1",
+			mockRoomUserReturn: roomUser,
+			expectedReturn:     roomUser,
+		},
+		{
+			name:            "Not Member Of Public Room",
+			userId:          "2",
+			mockRoomUserErr: apperror.NewNotFoundErr("RoomUser", "RoomID: 1, UserID: 2"),
+			mockRoom:        &model.Room{RoomID: "1", Name: "room-1", RoomType: model.Public},
+			expectedErr:     apperror.NewForbiddenErr("Room", "RoomID: 1"),
+		},
+		{
+			name:            "Not Member Of Private Room",
+			userId:          "2",
+			mockRoomUserErr: apperror.NewNotFoundErr("RoomUser", "RoomID: 1, UserID: 2"),
+			mockRoom:        &model.Room{RoomID: "1", Name: "room-1", RoomType: model.Private},
+			expectedErr:     apperror.NewNotFoundErr("Room", "RoomID: 1"),
+		},
+		{
+			name:            "Room Not Found",
+			userId:          "2",
+			mockRoomUserErr: apperror.NewNotFoundErr("RoomUser", "RoomID: 1, UserID: 2"),
+			mockRoomErr:     apperror.NewNotFoundErr("Room", "RoomID: 1"),
+			expectedErr:     apperror.NewNotFoundErr("Room", "RoomID: 1"),
+		},
+		{
+			name:            "Repository Error",
+			userId:          "1",
+			mockRoomUserErr: errors.New("some error"),
+			expectedErr:     errors.New("some error"),
+		},
+		{
+			name:        "Unauthenticated",
+			userId:      "",
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomRepo := new(mocks.RoomRepository)
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+
+			mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, "1", tc.userId).Return(tc.mockRoomUserReturn, tc.mockRoomUserErr)
+			mockRoomRepo.On("GetByID", mock.Anything, "1").Return(tc.mockRoom, tc.mockRoomErr)
+
+			authorizationUsecase := NewAuthorizationUsecase(mockRoomRepo, mockRoomUserRepo)
+
+			result, err := authorizationUsecase.AuthorizeRoomMember(context.Background(), "1", tc.userId)
+
+			if tc.expectedErr != nil {
+				assert.Nil(t, result)
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedReturn, result)
+			}
+		})
+	}
+}
+
+func TestAuthorizeRoomRole(t *testing.T) {
+	testCases := []struct {
+		name        string
+		role        model.RoomRole
+		roles       []model.RoomRole
+		expectedErr error
+	}{
+		{
+			name:  "Owner",
+			role:  model.Owner,
+			roles: []model.RoomRole{model.Owner},
+		},
+		{
+			name:  "Admin Allowed",
+			role:  model.Admin,
+			roles: []model.RoomRole{model.Owner, model.Admin},
+		},
+		{
+			name:        "Admin Not Allowed",
+			role:        model.Admin,
+			roles:       []model.RoomRole{model.Owner},
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+		},
+		{
+			name:        "Legacy Row Without Role",
+			role:        "",
+			roles:       []model.RoomRole{model.Owner, model.Admin},
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1, Role: member"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomRepo := new(mocks.RoomRepository)
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			roomUser := &model.RoomUser{RoomID: "1", UserID: "1", Role: tc.role}
+
+			mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, "1", "1").Return(roomUser, nil)
+
+			authorizationUsecase := NewAuthorizationUsecase(mockRoomRepo, mockRoomUserRepo)
+
+			result, err := authorizationUsecase.AuthorizeRoomRole(context.Background(), "1", "1", tc.roles...)
+
+			if tc.expectedErr != nil {
+				assert.Nil(t, result)
+				assert.Equal(t, tc.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, roomUser, result)
+			}
+		})
+	}
+}
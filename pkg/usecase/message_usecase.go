@@ -2,51 +2,376 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/clock"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
 )
 
+const (
+	maxSearchLimit = 100
+	// maxSearchWindow is the default index.max_result_window of Elasticsearch, the deepest a search can page.
+	maxSearchWindow = 10000
+	// maxCreateAttempts is how many creation times a message is tried at when others of the room were created
+	// at the same time.
+	maxCreateAttempts = 3
+)
+
 type MessageUsecaseImpl struct {
-	messageRepo repository.MessageRepository
+	messageRepo          repository.MessageRepository
+	roomUserRepo         repository.RoomUserRepository
+	reactionRepo         repository.ReactionRepository
+	messageSearchRepo    repository.MessageSearchRepository
+	attachmentUsecase    usecase.AttachmentUsecase
+	authorizationUsecase usecase.AuthorizationUsecase
+	roomEventPublisher   model.RoomEventPublisher
+	clocker              clock.Clocker
 }
 
-func NewMessageUsecase(messageRepo repository.MessageRepository) usecase.MessageUsecase {
+func NewMessageUsecase(messageRepo repository.MessageRepository, roomUserRepo repository.RoomUserRepository, reactionRepo repository.ReactionRepository, messageSearchRepo repository.MessageSearchRepository, attachmentUsecase usecase.AttachmentUsecase, authorizationUsecase usecase.AuthorizationUsecase, roomEventPublisher model.RoomEventPublisher, clocker clock.Clocker) usecase.MessageUsecase {
 	return &MessageUsecaseImpl{
-		messageRepo: messageRepo,
+		messageRepo:          messageRepo,
+		roomUserRepo:         roomUserRepo,
+		reactionRepo:         reactionRepo,
+		messageSearchRepo:    messageSearchRepo,
+		attachmentUsecase:    attachmentUsecase,
+		authorizationUsecase: authorizationUsecase,
+		roomEventPublisher:   roomEventPublisher,
+		clocker:              clocker,
 	}
 }
 
 func (mu *MessageUsecaseImpl) GetMessagesByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
-	return mu.messageRepo.GetMessagesByRoomID(ctx, roomId, lastEvaluatedKey, limit)
+	if _, err := authorizeCaller(ctx, mu.authorizationUsecase, roomId); err != nil {
+		return nil, "", err
+	}
+
+	messages, nextKey, err := mu.messageRepo.GetMessagesByRoomID(ctx, roomId, lastEvaluatedKey, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := mu.attachReactions(ctx, messages); err != nil {
+		return nil, "", err
+	}
+
+	return messages, nextKey, nil
+}
+
+func (mu *MessageUsecaseImpl) GetReplies(ctx context.Context, roomId, messageId, lastEvaluatedKey string, limit int) ([]*model.Message, string, error) {
+	if _, err := authorizeCaller(ctx, mu.authorizationUsecase, roomId); err != nil {
+		return nil, "", err
+	}
+
+	if _, err := mu.messageRepo.GetByID(ctx, roomId, messageId); err != nil {
+		return nil, "", err
+	}
+
+	replies, nextKey, err := mu.messageRepo.GetReplies(ctx, roomId, messageId, lastEvaluatedKey, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := mu.attachReactions(ctx, replies); err != nil {
+		return nil, "", err
+	}
+
+	return replies, nextKey, nil
+}
+
+func (mu *MessageUsecaseImpl) attachReactions(ctx context.Context, messages []*model.Message) error {
+	messageIds := make([]string, len(messages))
+	for i, message := range messages {
+		messageIds[i] = message.MessageID
+	}
+
+	reactions, err := mu.reactionRepo.GetCounts(ctx, messageIds)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		message.Reactions = reactions[message.MessageID]
+	}
+	return nil
+}
+
+func (mu *MessageUsecaseImpl) GetMessagesSince(ctx context.Context, roomId, since string, limit int) ([]*model.Message, bool, error) {
+	if _, err := authorizeCaller(ctx, mu.authorizationUsecase, roomId); err != nil {
+		return nil, false, err
+	}
+
+	sinceTime, err := time.Parse(time.RFC3339Nano, since)
+	if err != nil {
+		message, err := mu.messageRepo.GetByID(ctx, roomId, since)
+		if err != nil {
+			return nil, false, err
+		}
+		sinceTime = message.CreatedAt
+	}
+
+	// One extra message tells whether the result had to be truncated.
+	messages, err := mu.messageRepo.GetMessagesSince(ctx, roomId, sinceTime, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(messages) > limit {
+		return messages[len(messages)-limit:], true, nil
+	}
+	return messages, false, nil
 }
 
 func (mu *MessageUsecaseImpl) CreateMessage(ctx context.Context, message *model.Message) error {
-	clock := clock.RealClocker{}
+	roomUser, err := authorizeCaller(ctx, mu.authorizationUsecase, message.RoomID)
+	if err != nil {
+		return err
+	}
+
+	var parent *model.Message
+	if message.ParentMessageID != "" {
+		parent, err = mu.threadParent(ctx, message.RoomID, message.ParentMessageID)
+		if err != nil {
+			return err
+		}
+		message.ParentMessageID = parent.MessageID
+	}
 
 	message.MessageID = uuid.New().String()
-	message.CreatedAt = clock.Now()
+	message.UserID = roomUser.UserID
+	message.CreatedAt = mu.clocker.Now()
+
+	if err := mu.attachmentUsecase.PrepareAttachments(ctx, message); err != nil {
+		return err
+	}
+
+	if err := mu.createMessage(ctx, message); err != nil {
+		return err
+	}
+	if err := mu.attachmentUsecase.AttachToMessage(ctx, message); err != nil {
+		mu.discardMessage(ctx, message)
+		return err
+	}
+	mu.indexMessage(ctx, message)
+
+	if parent == nil {
+		mu.roomEventPublisher.PublishToRoom(message.RoomID, message)
+		return nil
+	}
 
-	return mu.messageRepo.Create(ctx, message)
+	// the reply is stored already, so failing here would make the client send it again; the summary of the
+	// thread is only a count, which the event carries as it would have been
+	replyCount := parent.ReplyCount + 1
+	if updatedParent, err := mu.messageRepo.AddReply(ctx, parent, message.CreatedAt); err != nil {
+		log.Printf("Failed to update the thread summary of message %s: %v", parent.MessageID, err)
+	} else {
+		replyCount = updatedParent.ReplyCount
+	}
+
+	mu.roomEventPublisher.PublishToRoom(message.RoomID, &model.ThreadRepliedDetails{
+		RoomID:          message.RoomID,
+		ParentMessageID: parent.MessageID,
+		Reply:           message,
+		ReplyCount:      replyCount,
+		LastReplyAt:     message.CreatedAt,
+	})
+	return nil
 }
 
+// createMessage stores the message, moving it a microsecond later each time another message of the room has its
+// creation time. The creation time identifies a message in the room and is the cursor of GetMessagesSince, so it is
+// kept unique rather than overwriting or hiding the other message.
+func (mu *MessageUsecaseImpl) createMessage(ctx context.Context, message *model.Message) error {
+	for attempt := 1; ; attempt++ {
+		err := mu.messageRepo.Create(ctx, message)
+		var alreadyExistsErr *apperror.AlreadyExistsErr
+		if !errors.As(err, &alreadyExistsErr) || attempt == maxCreateAttempts {
+			return err
+		}
+		message.CreatedAt = message.CreatedAt.Add(time.Microsecond)
+	}
+}
+
+// discardMessage rolls back a message whose attachments could not all be linked to it. Nothing was published about
+// it yet, so a failure is only logged; attachments left linked to the message are then never downloaded again.
+func (mu *MessageUsecaseImpl) discardMessage(ctx context.Context, message *model.Message) {
+	if err := mu.messageRepo.Delete(ctx, message.RoomID, message.MessageID); err != nil {
+		log.Printf("Failed to discard message %s: %v", message.MessageID, err)
+		return
+	}
+	if err := mu.attachmentUsecase.DeleteForMessage(ctx, message); err != nil {
+		log.Printf("Failed to delete the attachments of discarded message %s: %v", message.MessageID, err)
+	}
+}
+
+// threadParent returns the message starting the thread a reply to messageId belongs to.
+// Threads are one level deep, so replying to a reply adds to the thread of its parent.
+func (mu *MessageUsecaseImpl) threadParent(ctx context.Context, roomId, messageId string) (*model.Message, error) {
+	parent, err := mu.messageRepo.GetByID(ctx, roomId, messageId)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentMessageID == "" {
+		return parent, nil
+	}
+	return mu.messageRepo.GetByID(ctx, roomId, parent.ParentMessageID)
+}
+
+// indexMessage makes the message searchable. The Messages table is the source of truth, so a failure is only logged;
+// the message is indexed again by the next edit or a reindex.
+func (mu *MessageUsecaseImpl) indexMessage(ctx context.Context, message *model.Message) {
+	if err := mu.messageSearchRepo.Index(ctx, message); err != nil {
+		log.Printf("Failed to index message %s: %v", message.MessageID, err)
+	}
+}
+
+// UpdateMessage only lets the author edit the message.
 func (mu *MessageUsecaseImpl) UpdateMessage(ctx context.Context, roomId, messageId, newContent string) error {
-	_, err := mu.messageRepo.GetByID(ctx, roomId, messageId)
+	roomUser, err := authorizeCaller(ctx, mu.authorizationUsecase, roomId)
 	if err != nil {
 		return err
 	}
 
-	return mu.messageRepo.Update(ctx, roomId, messageId, newContent)
+	message, err := mu.messageRepo.GetByID(ctx, roomId, messageId)
+	if err != nil {
+		return err
+	}
+	if message.UserID != roomUser.UserID {
+		return apperror.NewForbiddenErr("Message", "MessageID: "+messageId)
+	}
+
+	if err := mu.messageRepo.Update(ctx, roomId, messageId, newContent); err != nil {
+		return err
+	}
+	message.Content = newContent
+	mu.indexMessage(ctx, message)
+
+	mu.roomEventPublisher.PublishToRoom(roomId, &model.MessageEditedDetails{
+		RoomID:    roomId,
+		MessageID: messageId,
+		UserID:    message.UserID,
+		Content:   newContent,
+	})
+	return nil
 }
 
+// DeleteMessage lets the author delete the message, and the owner and admins of the room moderate it.
+// The reactions and attachments of the message are deleted before the message itself, so that a failure
+// leaves the message in place for the deletion to be retried rather than leaving them behind.
 func (mu *MessageUsecaseImpl) DeleteMessage(ctx context.Context, roomId, messageId string) error {
-	_, err := mu.messageRepo.GetByID(ctx, roomId, messageId)
+	roomUser, err := authorizeCaller(ctx, mu.authorizationUsecase, roomId)
+	if err != nil {
+		return err
+	}
+
+	message, err := mu.messageRepo.GetByID(ctx, roomId, messageId)
+	if err != nil {
+		return err
+	}
+	if message.UserID != roomUser.UserID && !roomUser.EffectiveRole().Outranks(model.Member) {
+		return apperror.NewForbiddenErr("Message", "MessageID: "+messageId)
+	}
+
+	if err := mu.reactionRepo.DeleteByMessageID(ctx, messageId); err != nil {
+		return err
+	}
+	if err := mu.attachmentUsecase.DeleteForMessage(ctx, message); err != nil {
+		return err
+	}
+	if err := mu.messageRepo.Delete(ctx, roomId, messageId); err != nil {
+		return err
+	}
+	if err := mu.messageSearchRepo.Delete(ctx, messageId); err != nil {
+		log.Printf("Failed to remove message %s from the search index: %v", messageId, err)
+	}
+
+	if message.ParentMessageID != "" {
+		if err := mu.removeReply(ctx, roomId, message.ParentMessageID); err != nil {
+			return err
+		}
+	}
+
+	mu.roomEventPublisher.PublishToRoom(roomId, &model.MessageDeletedDetails{
+		RoomID:    roomId,
+		MessageID: messageId,
+	})
+	return nil
+}
+
+func (mu *MessageUsecaseImpl) removeReply(ctx context.Context, roomId, parentMessageId string) error {
+	parent, err := mu.messageRepo.GetByID(ctx, roomId, parentMessageId)
+	if err != nil {
+		var notFoundErr *apperror.NotFoundErr
+		if errors.As(err, &notFoundErr) {
+			return nil
+		}
+		return err
+	}
+	return mu.messageRepo.RemoveReply(ctx, parent)
+}
+
+// MarkAsRead moves the caller's read marker in the room forward to the message and tells the room about it.
+// Marking an older message than the current marker has no effect.
+func (mu *MessageUsecaseImpl) MarkAsRead(ctx context.Context, roomId, messageId string) error {
+	roomUser, err := authorizeCaller(ctx, mu.authorizationUsecase, roomId)
+	if err != nil {
+		return err
+	}
+
+	message, err := mu.messageRepo.GetByID(ctx, roomId, messageId)
+	if err != nil {
+		return err
+	}
+
+	advanced, err := mu.roomUserRepo.UpdateLastRead(ctx, roomId, roomUser.UserID, messageId, message.CreatedAt)
 	if err != nil {
 		return err
 	}
+	if !advanced {
+		return nil
+	}
+
+	mu.roomEventPublisher.PublishToRoom(roomId, &model.MessageReadDetails{
+		RoomID:    roomId,
+		UserID:    roomUser.UserID,
+		MessageID: messageId,
+		ReadAt:    message.CreatedAt,
+	})
+	return nil
+}
+
+// SearchMessages only searches rooms the caller is a member of, so hits never reveal messages the caller could not read.
+func (mu *MessageUsecaseImpl) SearchMessages(ctx context.Context, query, roomId string, offset, limit int) ([]*model.MessageSearchHit, int, error) {
+	if limit <= 0 || limit > maxSearchLimit {
+		return nil, 0, apperror.NewBadRequestErr("Search", fmt.Sprintf("Limit: %d is not between 1 and %d", limit, maxSearchLimit))
+	}
+	if offset < 0 || offset+limit > maxSearchWindow {
+		return nil, 0, apperror.NewBadRequestErr("Search", fmt.Sprintf("Offset: %d goes beyond the first %d hits", offset, maxSearchWindow))
+	}
+
+	var roomIds []string
+	if roomId != "" {
+		if _, err := authorizeCaller(ctx, mu.authorizationUsecase, roomId); err != nil {
+			return nil, 0, err
+		}
+		roomIds = []string{roomId}
+	} else {
+		userId, _ := authctx.UserID(ctx)
+		roomUsers, err := mu.roomUserRepo.GetAllRoomsByUserID(ctx, userId)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, roomUser := range roomUsers {
+			roomIds = append(roomIds, roomUser.RoomID)
+		}
+	}
 
-	return mu.messageRepo.Delete(ctx, roomId, messageId)
+	return mu.messageSearchRepo.Search(ctx, query, roomIds, offset, limit)
 }
@@ -2,12 +2,17 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/clock"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	modelMocks "github.com/shunsukenagashima/chat-api/pkg/domain/model/mocks"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository/mocks"
+	usecaseMocks "github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -39,10 +44,18 @@ func TestGetAllMessagesByRoomID(t *testing.T) {
 		},
 	}
 
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
 	mockMessageRepo.On("GetMessagesByRoomID", mock.Anything, mockMessages[0].RoomID, mock.Anything, mock.Anything).Return(mockMessages, "4", nil)
-	messageUsecase := NewMessageUsecase(mockMessageRepo)
+	mockReactionRepo := new(mocks.ReactionRepository)
+	mockReactionRepo.On("GetCounts", mock.Anything, []string{"1", "2", "3"}).Return(map[string][]*model.ReactionCount{
+		"2": {{Emoji: "👍", Count: 2}},
+	}, nil)
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), mockReactionRepo, newMessageSearchRepositoryMock(), new(usecaseMocks.AttachmentUsecase), mockAuthorizationUsecase, mockPublisher, clock)
 
-	messages, _, err := messageUsecase.GetMessagesByRoomID(context.Background(), "1", "0", 10)
+	ctx := authctx.WithUserID(context.Background(), "1")
+	messages, _, err := messageUsecase.GetMessagesByRoomID(ctx, "1", "0", 10)
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, messages)
@@ -54,24 +67,308 @@ func TestGetAllMessagesByRoomID(t *testing.T) {
 		assert.Equal(t, mockMessages[i].Content, message.Content)
 		assert.Equal(t, mockMessages[i].CreatedAt, message.CreatedAt)
 	}
+	assert.Nil(t, messages[0].Reactions)
+	assert.Equal(t, []*model.ReactionCount{{Emoji: "👍", Count: 2}}, messages[1].Reactions)
 	mockMessageRepo.AssertExpectations(t)
+	mockAuthorizationUsecase.AssertExpectations(t)
+	mockReactionRepo.AssertExpectations(t)
+}
+
+func TestGetAllMessagesByRoomID_NotMember(t *testing.T) {
+	mockMessageRepo := new(mocks.MessageRepository)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	forbiddenErr := apperror.NewForbiddenErr("Room", "RoomID: 1")
+
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "2").Return(nil, forbiddenErr)
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), new(usecaseMocks.AttachmentUsecase), mockAuthorizationUsecase, mockPublisher, clock.FixedClocker{})
+
+	ctx := authctx.WithUserID(context.Background(), "2")
+	messages, _, err := messageUsecase.GetMessagesByRoomID(ctx, "1", "", 10)
+
+	assert.Nil(t, messages)
+	assert.Equal(t, forbiddenErr, err)
+	mockMessageRepo.AssertNotCalled(t, "GetMessagesByRoomID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestCreateMessage(t *testing.T) {
 	mockMessageRepo := new(mocks.MessageRepository)
 	mockMessage := &model.Message{
 		RoomID:  "1",
-		UserID:  "1",
+		UserID:  "2",
 		Content: "Hello",
 	}
 
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
 	mockMessageRepo.On("Create", mock.Anything, mockMessage).Return(nil)
-	messageUsecase := NewMessageUsecase(mockMessageRepo)
+	mockPublisher.On("PublishToRoom", "1", mockMessage).Return()
+	mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+	mockAttachmentUsecase.On("PrepareAttachments", mock.Anything, mockMessage).Return(nil)
+	mockAttachmentUsecase.On("AttachToMessage", mock.Anything, mockMessage).Return(nil)
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock.FixedClocker{})
 
-	err := messageUsecase.CreateMessage(context.Background(), mockMessage)
+	ctx := authctx.WithUserID(context.Background(), "1")
+	err := messageUsecase.CreateMessage(ctx, mockMessage)
 
 	assert.NoError(t, err)
+	assert.NotEmpty(t, mockMessage.MessageID)
+	assert.Equal(t, "1", mockMessage.UserID)
+	assert.Equal(t, clock.FixedClocker{}.Now(), mockMessage.CreatedAt)
 	mockMessageRepo.AssertExpectations(t)
+	mockAuthorizationUsecase.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestCreateMessage_AttachmentRejected(t *testing.T) {
+	mockMessageRepo := new(mocks.MessageRepository)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+	mockAttachmentUsecase.On("PrepareAttachments", mock.Anything, mock.Anything).Return(apperror.NewBadRequestErr("Attachment", "AttachmentID: 1 was not uploaded"))
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock.FixedClocker{})
+
+	message := &model.Message{RoomID: "1", Attachments: []*model.Attachment{{AttachmentID: "1"}}}
+	ctx := authctx.WithUserID(context.Background(), "1")
+	err := messageUsecase.CreateMessage(ctx, message)
+
+	assert.Equal(t, apperror.NewBadRequestErr("Attachment", "AttachmentID: 1 was not uploaded"), err)
+	mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockAttachmentUsecase.AssertNotCalled(t, "AttachToMessage", mock.Anything, mock.Anything)
+	mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_CreateFailed(t *testing.T) {
+	mockMessageRepo := new(mocks.MessageRepository)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+	mockAttachmentUsecase.On("PrepareAttachments", mock.Anything, mock.Anything).Return(nil)
+	mockMessageRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock.FixedClocker{})
+
+	message := &model.Message{RoomID: "1", Attachments: []*model.Attachment{{AttachmentID: "1"}}}
+	ctx := authctx.WithUserID(context.Background(), "1")
+	err := messageUsecase.CreateMessage(ctx, message)
+
+	assert.EqualError(t, err, "connection refused")
+	mockAttachmentUsecase.AssertNotCalled(t, "AttachToMessage", mock.Anything, mock.Anything)
+	mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_AttachmentClaimed(t *testing.T) {
+	mockMessageRepo := new(mocks.MessageRepository)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+	mockAttachmentUsecase.On("PrepareAttachments", mock.Anything, mock.Anything).Return(nil)
+	mockMessageRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockAttachmentUsecase.On("AttachToMessage", mock.Anything, mock.Anything).Return(apperror.NewNotFoundErr("Attachment", "AttachmentID: 1"))
+	mockMessageRepo.On("Delete", mock.Anything, "1", mock.Anything).Return(nil)
+	mockAttachmentUsecase.On("DeleteForMessage", mock.Anything, mock.Anything).Return(nil)
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock.FixedClocker{})
+
+	message := &model.Message{RoomID: "1", Attachments: []*model.Attachment{{AttachmentID: "1"}}}
+	ctx := authctx.WithUserID(context.Background(), "1")
+	err := messageUsecase.CreateMessage(ctx, message)
+
+	assert.Equal(t, apperror.NewNotFoundErr("Attachment", "AttachmentID: 1"), err)
+	mockMessageRepo.AssertCalled(t, "Delete", mock.Anything, "1", message.MessageID)
+	mockAttachmentUsecase.AssertExpectations(t)
+	mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_SameTime(t *testing.T) {
+	clock := clock.FixedClocker{}
+
+	testCases := []struct {
+		name              string
+		conflicts         int
+		expectedCreatedAt time.Time
+		expectedErr       error
+	}{
+		{
+			name:              "Moved After The Other Message",
+			conflicts:         1,
+			expectedCreatedAt: clock.Now().Add(time.Microsecond),
+		},
+		{
+			name:        "Gives Up",
+			conflicts:   maxCreateAttempts,
+			expectedErr: apperror.NewAlreadyExistsErr("Message", "RoomID: 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockMessageRepo := new(mocks.MessageRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+			mockAttachmentUsecase.On("PrepareAttachments", mock.Anything, mock.Anything).Return(nil)
+			mockAttachmentUsecase.On("AttachToMessage", mock.Anything, mock.Anything).Return(nil)
+			mockMessageRepo.On("Create", mock.Anything, mock.Anything).Return(apperror.NewAlreadyExistsErr("Message", "RoomID: 1")).Times(tc.conflicts)
+			mockMessageRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+			mockPublisher.On("PublishToRoom", "1", mock.Anything).Return()
+			messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock)
+
+			message := &model.Message{RoomID: "1", Content: "Hello"}
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := messageUsecase.CreateMessage(ctx, message)
+
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+				mockMessageRepo.AssertNumberOfCalls(t, "Create", maxCreateAttempts)
+				mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCreatedAt, message.CreatedAt)
+			mockPublisher.AssertCalled(t, "PublishToRoom", "1", message)
+		})
+	}
+}
+
+func TestCreateMessage_Reply(t *testing.T) {
+	clock := clock.FixedClocker{}
+	parent := &model.Message{MessageID: "1", RoomID: "1", UserID: "2", CreatedAt: clock.Now().Add(-time.Hour)}
+	reply := &model.Message{MessageID: "2", RoomID: "1", UserID: "2", ParentMessageID: "1", CreatedAt: clock.Now().Add(-time.Minute)}
+
+	testCases := []struct {
+		name            string
+		parentMessageId string
+	}{
+		{
+			name:            "Reply To Message",
+			parentMessageId: "1",
+		},
+		{
+			name:            "Reply To Reply",
+			parentMessageId: "2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockMessageRepo := new(mocks.MessageRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+			mockMessageRepo.On("GetByID", mock.Anything, "1", "1").Return(parent, nil)
+			mockMessageRepo.On("GetByID", mock.Anything, "1", "2").Return(reply, nil).Maybe()
+			mockMessageRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+			mockMessageRepo.On("AddReply", mock.Anything, parent, clock.Now()).Return(&model.Message{MessageID: "1", RoomID: "1", ReplyCount: 2}, nil)
+
+			var published *model.ThreadRepliedDetails
+			mockPublisher.On("PublishToRoom", "1", mock.AnythingOfType("*model.ThreadRepliedDetails")).Run(func(args mock.Arguments) {
+				published = args.Get(1).(*model.ThreadRepliedDetails)
+			}).Return()
+			mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+			mockAttachmentUsecase.On("PrepareAttachments", mock.Anything, mock.Anything).Return(nil)
+			mockAttachmentUsecase.On("AttachToMessage", mock.Anything, mock.Anything).Return(nil)
+			messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock)
+
+			message := &model.Message{RoomID: "1", Content: "Hello", ParentMessageID: tc.parentMessageId}
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := messageUsecase.CreateMessage(ctx, message)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "1", message.ParentMessageID)
+			assert.Equal(t, &model.ThreadRepliedDetails{
+				RoomID:          "1",
+				ParentMessageID: "1",
+				Reply:           message,
+				ReplyCount:      2,
+				LastReplyAt:     clock.Now(),
+			}, published)
+			mockMessageRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateMessage_ReplySummaryFailed(t *testing.T) {
+	clock := clock.FixedClocker{}
+	parent := &model.Message{MessageID: "1", RoomID: "1", UserID: "2", ReplyCount: 1}
+
+	mockMessageRepo := new(mocks.MessageRepository)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+	mockAttachmentUsecase.On("PrepareAttachments", mock.Anything, mock.Anything).Return(nil)
+	mockAttachmentUsecase.On("AttachToMessage", mock.Anything, mock.Anything).Return(nil)
+	mockMessageRepo.On("GetByID", mock.Anything, "1", "1").Return(parent, nil)
+	mockMessageRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockMessageRepo.On("AddReply", mock.Anything, parent, clock.Now()).Return(nil, errors.New("throttled"))
+
+	var published *model.ThreadRepliedDetails
+	mockPublisher.On("PublishToRoom", "1", mock.AnythingOfType("*model.ThreadRepliedDetails")).Run(func(args mock.Arguments) {
+		published = args.Get(1).(*model.ThreadRepliedDetails)
+	}).Return()
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock)
+
+	message := &model.Message{RoomID: "1", Content: "Hello", ParentMessageID: "1"}
+	ctx := authctx.WithUserID(context.Background(), "1")
+	err := messageUsecase.CreateMessage(ctx, message)
+
+	assert.NoError(t, err)
+	mockMessageRepo.AssertCalled(t, "Create", mock.Anything, message)
+	mockMessageRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, &model.ThreadRepliedDetails{
+		RoomID:          "1",
+		ParentMessageID: "1",
+		Reply:           message,
+		ReplyCount:      2,
+		LastReplyAt:     clock.Now(),
+	}, published)
+}
+
+func TestGetReplies(t *testing.T) {
+	replies := []*model.Message{
+		{MessageID: "2", RoomID: "1", ParentMessageID: "1", Content: "a"},
+		{MessageID: "3", RoomID: "1", ParentMessageID: "1", Content: "b"},
+	}
+
+	testCases := []struct {
+		name            string
+		getByIdErr      error
+		expectedReplies []*model.Message
+		expectedErr     error
+	}{
+		{
+			name:            "Success",
+			expectedReplies: replies,
+		},
+		{
+			name:        "Unknown Parent",
+			getByIdErr:  apperror.NewNotFoundErr("Message", "MessageID: 1"),
+			expectedErr: apperror.NewNotFoundErr("Message", "MessageID: 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockMessageRepo := new(mocks.MessageRepository)
+			mockReactionRepo := new(mocks.ReactionRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+			mockMessageRepo.On("GetByID", mock.Anything, "1", "1").Return(&model.Message{MessageID: "1", RoomID: "1"}, tc.getByIdErr)
+			mockMessageRepo.On("GetReplies", mock.Anything, "1", "1", "", 20).Return(replies, "", nil)
+			mockReactionRepo.On("GetCounts", mock.Anything, []string{"2", "3"}).Return(map[string][]*model.ReactionCount{}, nil)
+			messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), mockReactionRepo, newMessageSearchRepositoryMock(), new(usecaseMocks.AttachmentUsecase), mockAuthorizationUsecase, new(modelMocks.RoomEventPublisher), clock.FixedClocker{})
+
+			ctx := authctx.WithUserID(context.Background(), "1")
+			result, _, err := messageUsecase.GetReplies(ctx, "1", "1", "", 20)
+
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedReplies, result)
+		})
+	}
 }
 
 func TestUpdateMessage(t *testing.T) {
@@ -84,12 +381,22 @@ func TestUpdateMessage(t *testing.T) {
 		CreatedAt: clock.Now(),
 	}
 
+	othersMessage := &model.Message{
+		MessageID: "3",
+		RoomID:    "1",
+		UserID:    "2",
+		Content:   "Hi",
+		CreatedAt: clock.Now(),
+	}
+
 	testCases := []struct {
 		name          string
 		roomId        string
 		messageId     string
 		newContent    string
+		callerRole    model.RoomRole
 		getByIdReturn *model.Message
+		getByIdErr    error
 		expectedErr   error
 	}{
 		{
@@ -106,26 +413,61 @@ func TestUpdateMessage(t *testing.T) {
 			messageId:     "2",
 			newContent:    "Hello World",
 			getByIdReturn: nil,
+			getByIdErr:    apperror.NewNotFoundErr("Message", "MessageID: 2"),
 			expectedErr:   apperror.NewNotFoundErr("Message", "MessageID: 2"),
 		},
+		{
+			name:          "Message Of Another Member",
+			roomId:        othersMessage.RoomID,
+			messageId:     othersMessage.MessageID,
+			newContent:    "Hello World",
+			getByIdReturn: othersMessage,
+			expectedErr:   apperror.NewForbiddenErr("Message", "MessageID: 3"),
+		},
+		{
+			name:          "Owner Cannot Edit Message Of Another Member",
+			roomId:        othersMessage.RoomID,
+			messageId:     othersMessage.MessageID,
+			newContent:    "Hello World",
+			callerRole:    model.Owner,
+			getByIdReturn: othersMessage,
+			expectedErr:   apperror.NewForbiddenErr("Message", "MessageID: 3"),
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockMessageRepo := new(mocks.MessageRepository)
-			mockMessageRepo.On("GetByID", mock.Anything, tc.roomId, tc.messageId).Return(tc.getByIdReturn, tc.expectedErr)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, tc.roomId, "1").Return(&model.RoomUser{RoomID: tc.roomId, UserID: "1", Role: tc.callerRole}, nil)
+			mockMessageRepo.On("GetByID", mock.Anything, tc.roomId, tc.messageId).Return(tc.getByIdReturn, tc.getByIdErr)
 
 			mockMessageRepo.On("Update", mock.Anything, tc.roomId, tc.messageId, tc.newContent).Return(nil)
-			messageUsecase := NewMessageUsecase(mockMessageRepo)
+			mockPublisher.On("PublishToRoom", tc.roomId, &model.MessageEditedDetails{
+				RoomID:    tc.roomId,
+				MessageID: tc.messageId,
+				UserID:    mockMessage.UserID,
+				Content:   tc.newContent,
+			}).Return()
+			mockMessageSearchRepo := newMessageSearchRepositoryMock()
+			messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), mockMessageSearchRepo, new(usecaseMocks.AttachmentUsecase), mockAuthorizationUsecase, mockPublisher, clock)
 
-			err := messageUsecase.UpdateMessage(context.Background(), tc.roomId, tc.messageId, tc.newContent)
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := messageUsecase.UpdateMessage(ctx, tc.roomId, tc.messageId, tc.newContent)
 
 			if tc.expectedErr != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tc.expectedErr, err)
+				mockMessageRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
 			} else {
 				assert.NoError(t, err)
 				mockMessageRepo.AssertExpectations(t)
+				mockPublisher.AssertExpectations(t)
+				mockMessageSearchRepo.AssertCalled(t, "Index", mock.Anything, mock.MatchedBy(func(message *model.Message) bool {
+					return message.MessageID == tc.messageId && message.Content == tc.newContent
+				}))
 			}
 		})
 	}
@@ -141,11 +483,21 @@ func TestDeleteMessage(t *testing.T) {
 		CreatedAt: clock.Now(),
 	}
 
+	othersMessage := &model.Message{
+		MessageID: "3",
+		RoomID:    "1",
+		UserID:    "2",
+		Content:   "Hi",
+		CreatedAt: clock.Now(),
+	}
+
 	testCases := []struct {
 		name          string
 		roomId        string
 		messageId     string
+		callerRole    model.RoomRole
 		getByIdReturn *model.Message
+		getByIdErr    error
 		expectedErr   error
 	}{
 		{
@@ -160,27 +512,340 @@ func TestDeleteMessage(t *testing.T) {
 			roomId:        mockMessage.RoomID,
 			messageId:     "2",
 			getByIdReturn: nil,
+			getByIdErr:    apperror.NewNotFoundErr("Message", "MessageID: 2"),
 			expectedErr:   apperror.NewNotFoundErr("Message", "MessageID: 2"),
 		},
+		{
+			name:          "Message Of Another Member",
+			roomId:        othersMessage.RoomID,
+			messageId:     othersMessage.MessageID,
+			callerRole:    model.Member,
+			getByIdReturn: othersMessage,
+			expectedErr:   apperror.NewForbiddenErr("Message", "MessageID: 3"),
+		},
+		{
+			name:          "Admin Deletes Message Of Another Member",
+			roomId:        othersMessage.RoomID,
+			messageId:     othersMessage.MessageID,
+			callerRole:    model.Admin,
+			getByIdReturn: othersMessage,
+		},
+		{
+			name:          "Owner Deletes Message Of Another Member",
+			roomId:        othersMessage.RoomID,
+			messageId:     othersMessage.MessageID,
+			callerRole:    model.Owner,
+			getByIdReturn: othersMessage,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockMessageRepo := new(mocks.MessageRepository)
-			mockMessageRepo.On("GetByID", mock.Anything, tc.roomId, tc.messageId).Return(tc.getByIdReturn, tc.expectedErr)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, tc.roomId, "1").Return(&model.RoomUser{RoomID: tc.roomId, UserID: "1", Role: tc.callerRole}, nil)
+			mockMessageRepo.On("GetByID", mock.Anything, tc.roomId, tc.messageId).Return(tc.getByIdReturn, tc.getByIdErr)
 
+			mockReactionRepo := new(mocks.ReactionRepository)
+			mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+			mockReactionRepo.On("DeleteByMessageID", mock.Anything, tc.messageId).Return(nil)
+			mockAttachmentUsecase.On("DeleteForMessage", mock.Anything, tc.getByIdReturn).Return(nil)
 			mockMessageRepo.On("Delete", mock.Anything, tc.roomId, tc.messageId).Return(nil)
-			messageUsecase := NewMessageUsecase(mockMessageRepo)
+			mockPublisher.On("PublishToRoom", tc.roomId, &model.MessageDeletedDetails{
+				RoomID:    tc.roomId,
+				MessageID: tc.messageId,
+			}).Return()
+			messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), mockReactionRepo, newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock)
 
-			err := messageUsecase.DeleteMessage(context.Background(), tc.roomId, tc.messageId)
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := messageUsecase.DeleteMessage(ctx, tc.roomId, tc.messageId)
 
 			if tc.expectedErr != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tc.expectedErr, err)
+				mockMessageRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+				mockReactionRepo.AssertNotCalled(t, "DeleteByMessageID", mock.Anything, mock.Anything)
+				mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
 			} else {
 				assert.NoError(t, err)
 				mockMessageRepo.AssertExpectations(t)
+				mockReactionRepo.AssertExpectations(t)
+				mockAttachmentUsecase.AssertExpectations(t)
+				mockPublisher.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestDeleteMessage_CleanupFailed(t *testing.T) {
+	message := &model.Message{MessageID: "1", RoomID: "1", UserID: "1", Attachments: []*model.Attachment{{AttachmentID: "1"}}}
+
+	mockMessageRepo := new(mocks.MessageRepository)
+	mockReactionRepo := new(mocks.ReactionRepository)
+	mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+	mockMessageRepo.On("GetByID", mock.Anything, "1", "1").Return(message, nil)
+	mockReactionRepo.On("DeleteByMessageID", mock.Anything, "1").Return(nil)
+	mockAttachmentUsecase.On("DeleteForMessage", mock.Anything, message).Return(errors.New("connection refused"))
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), mockReactionRepo, newMessageSearchRepositoryMock(), mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock.FixedClocker{})
+
+	ctx := authctx.WithUserID(context.Background(), "1")
+	err := messageUsecase.DeleteMessage(ctx, "1", "1")
+
+	assert.EqualError(t, err, "connection refused")
+	mockMessageRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+}
+
+func TestGetMessagesSince(t *testing.T) {
+	clock := clock.FixedClocker{}
+	since := clock.Now().Add(-time.Minute)
+	messages := []*model.Message{
+		{MessageID: "2", RoomID: "1", Content: "a", CreatedAt: clock.Now().Add(-30 * time.Second)},
+		{MessageID: "3", RoomID: "1", Content: "b", CreatedAt: clock.Now().Add(-20 * time.Second)},
+		{MessageID: "4", RoomID: "1", Content: "c", CreatedAt: clock.Now().Add(-10 * time.Second)},
+	}
+
+	testCases := []struct {
+		name              string
+		since             string
+		limit             int
+		getByIdReturn     *model.Message
+		getByIdErr        error
+		repoMessages      []*model.Message
+		expectedMessages  []*model.Message
+		expectedTruncated bool
+		expectedErr       error
+	}{
+		{
+			name:              "Timestamp Cursor",
+			since:             since.Format(time.RFC3339Nano),
+			limit:             5,
+			repoMessages:      messages,
+			expectedMessages:  messages,
+			expectedTruncated: false,
+		},
+		{
+			name:              "Message ID Cursor",
+			since:             "1",
+			limit:             5,
+			getByIdReturn:     &model.Message{MessageID: "1", RoomID: "1", CreatedAt: since},
+			repoMessages:      messages,
+			expectedMessages:  messages,
+			expectedTruncated: false,
+		},
+		{
+			name:              "Truncated",
+			since:             since.Format(time.RFC3339Nano),
+			limit:             2,
+			repoMessages:      messages,
+			expectedMessages:  messages[1:],
+			expectedTruncated: true,
+		},
+		{
+			name:        "Unknown Cursor",
+			since:       "unknown",
+			limit:       5,
+			getByIdErr:  apperror.NewNotFoundErr("Message", "Message'ID: unknown"),
+			expectedErr: apperror.NewNotFoundErr("Message", "Message'ID: unknown"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockMessageRepo := new(mocks.MessageRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+			if tc.getByIdReturn != nil || tc.getByIdErr != nil {
+				mockMessageRepo.On("GetByID", mock.Anything, "1", tc.since).Return(tc.getByIdReturn, tc.getByIdErr)
+			}
+			mockMessageRepo.On("GetMessagesSince", mock.Anything, "1", since, tc.limit+1).Return(tc.repoMessages, nil)
+			messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), new(usecaseMocks.AttachmentUsecase), mockAuthorizationUsecase, mockPublisher, clock)
+
+			ctx := authctx.WithUserID(context.Background(), "1")
+			result, truncated, err := messageUsecase.GetMessagesSince(ctx, "1", tc.since, tc.limit)
+
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+				mockMessageRepo.AssertNotCalled(t, "GetMessagesSince", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
 			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedMessages, result)
+			assert.Equal(t, tc.expectedTruncated, truncated)
 		})
 	}
 }
+
+func TestMarkAsRead(t *testing.T) {
+	clock := clock.FixedClocker{}
+	mockMessage := &model.Message{
+		MessageID: "1",
+		RoomID:    "1",
+		UserID:    "2",
+		Content:   "Hello",
+		CreatedAt: clock.Now(),
+	}
+
+	testCases := []struct {
+		name            string
+		messageId       string
+		getByIdReturn   *model.Message
+		getByIdErr      error
+		advanced        bool
+		expectedErr     error
+		expectedPublish bool
+	}{
+		{
+			name:            "Success",
+			messageId:       "1",
+			getByIdReturn:   mockMessage,
+			advanced:        true,
+			expectedPublish: true,
+		},
+		{
+			name:          "Older Than Read Marker",
+			messageId:     "1",
+			getByIdReturn: mockMessage,
+			advanced:      false,
+		},
+		{
+			name:        "Invalid MessageID",
+			messageId:   "2",
+			getByIdErr:  apperror.NewNotFoundErr("Message", "MessageID: 2"),
+			expectedErr: apperror.NewNotFoundErr("Message", "MessageID: 2"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockMessageRepo := new(mocks.MessageRepository)
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+			mockMessageRepo.On("GetByID", mock.Anything, "1", tc.messageId).Return(tc.getByIdReturn, tc.getByIdErr)
+			mockRoomUserRepo.On("UpdateLastRead", mock.Anything, "1", "1", tc.messageId, clock.Now()).Return(tc.advanced, nil)
+			mockPublisher.On("PublishToRoom", "1", &model.MessageReadDetails{
+				RoomID:    "1",
+				UserID:    "1",
+				MessageID: tc.messageId,
+				ReadAt:    clock.Now(),
+			}).Return()
+			messageUsecase := NewMessageUsecase(mockMessageRepo, mockRoomUserRepo, new(mocks.ReactionRepository), newMessageSearchRepositoryMock(), new(usecaseMocks.AttachmentUsecase), mockAuthorizationUsecase, mockPublisher, clock)
+
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := messageUsecase.MarkAsRead(ctx, "1", tc.messageId)
+
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedPublish {
+				mockPublisher.AssertExpectations(t)
+			} else {
+				mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCreateMessage_IndexFailed(t *testing.T) {
+	mockMessageRepo := new(mocks.MessageRepository)
+	mockMessageSearchRepo := new(mocks.MessageSearchRepository)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockAttachmentUsecase := new(usecaseMocks.AttachmentUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+	mockAttachmentUsecase.On("PrepareAttachments", mock.Anything, mock.Anything).Return(nil)
+	mockAttachmentUsecase.On("AttachToMessage", mock.Anything, mock.Anything).Return(nil)
+	mockMessageRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockMessageSearchRepo.On("Index", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	mockPublisher.On("PublishToRoom", "1", mock.Anything).Return()
+	messageUsecase := NewMessageUsecase(mockMessageRepo, new(mocks.RoomUserRepository), new(mocks.ReactionRepository), mockMessageSearchRepo, mockAttachmentUsecase, mockAuthorizationUsecase, mockPublisher, clock.FixedClocker{})
+
+	ctx := authctx.WithUserID(context.Background(), "1")
+	err := messageUsecase.CreateMessage(ctx, &model.Message{RoomID: "1", Content: "Hello"})
+
+	assert.NoError(t, err)
+	mockMessageSearchRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestSearchMessages(t *testing.T) {
+	hits := []*model.MessageSearchHit{
+		{Message: &model.Message{MessageID: "1", RoomID: "1", Content: "Hello"}, Highlights: []string{"<em>Hello</em>"}},
+	}
+
+	testCases := []struct {
+		name            string
+		callerId        string
+		roomId          string
+		limit           int
+		expectedRoomIds []string
+		expectedErr     error
+	}{
+		{
+			name:            "Every Room Of Caller",
+			callerId:        "1",
+			limit:           20,
+			expectedRoomIds: []string{"1", "2"},
+		},
+		{
+			name:            "One Room",
+			callerId:        "1",
+			roomId:          "2",
+			limit:           20,
+			expectedRoomIds: []string{"2"},
+		},
+		{
+			name:        "Room Of Others",
+			callerId:    "1",
+			roomId:      "3",
+			limit:       20,
+			expectedErr: apperror.NewNotFoundErr("Room", "RoomID: 3"),
+		},
+		{
+			name:        "Limit Too Large",
+			callerId:    "1",
+			limit:       maxSearchLimit + 1,
+			expectedErr: apperror.NewBadRequestErr("Search", "Limit: 101 is not between 1 and 100"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			mockMessageSearchRepo := new(mocks.MessageSearchRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockRoomUserRepo.On("GetAllRoomsByUserID", mock.Anything, "1").Return([]*model.RoomUser{
+				{RoomID: "1", UserID: "1"},
+				{RoomID: "2", UserID: "1"},
+			}, nil)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "2", "1").Return(&model.RoomUser{RoomID: "2", UserID: "1"}, nil)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "3", "1").Return(nil, apperror.NewNotFoundErr("Room", "RoomID: 3"))
+			mockMessageSearchRepo.On("Search", mock.Anything, "hello", tc.expectedRoomIds, 0, tc.limit).Return(hits, 1, nil)
+			messageUsecase := NewMessageUsecase(new(mocks.MessageRepository), mockRoomUserRepo, new(mocks.ReactionRepository), mockMessageSearchRepo, new(usecaseMocks.AttachmentUsecase), mockAuthorizationUsecase, new(modelMocks.RoomEventPublisher), clock.FixedClocker{})
+
+			ctx := authctx.WithUserID(context.Background(), tc.callerId)
+			result, total, err := messageUsecase.SearchMessages(ctx, "hello", tc.roomId, 0, tc.limit)
+
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr != nil {
+				mockMessageSearchRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, hits, result)
+			assert.Equal(t, 1, total)
+		})
+	}
+}
+
+// newMessageSearchRepositoryMock returns a search index that accepts every change, for tests that do not look at it.
+func newMessageSearchRepositoryMock() *mocks.MessageSearchRepository {
+	mockMessageSearchRepo := new(mocks.MessageSearchRepository)
+	mockMessageSearchRepo.On("Index", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockMessageSearchRepo.On("Delete", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return mockMessageSearchRepo
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+)
+
+// roomPresencePageSize is the number of members read at a time when listing the presence of a room.
+const roomPresencePageSize = 100
+
+// PresenceUsecaseImpl keeps the connections of the users connected to this server instance in memory. Changes of
+// presence are published to the rooms, which reach the clients of every instance through the broadcaster, but the
+// presence it reports only knows about the connections to this instance: with several instances, a user connected
+// to another one is reported offline. A user is forgotten once offline, after their last seen time is recorded on
+// their user, which is where the presence of users without connections comes from.
+type PresenceUsecaseImpl struct {
+	roomUserRepo         repository.RoomUserRepository
+	userRepo             repository.UserRepository
+	authorizationUsecase usecase.AuthorizationUsecase
+	roomEventPublisher   model.RoomEventPublisher
+	clocker              clock.Clocker
+
+	users map[string]*userPresence
+	mu    sync.Mutex
+}
+
+type userPresence struct {
+	connections map[*model.Client]model.PresenceStatus
+	lastSeenAt  time.Time
+
+	// published is the status last pushed to the rooms of the user. Publishing is serialized per user
+	// so that the rooms always end up with the latest status.
+	published model.PresenceStatus
+	publishMu sync.Mutex
+}
+
+func NewPresenceUsecase(roomUserRepo repository.RoomUserRepository, userRepo repository.UserRepository, authorizationUsecase usecase.AuthorizationUsecase, roomEventPublisher model.RoomEventPublisher, clocker clock.Clocker) usecase.PresenceUsecase {
+	return &PresenceUsecaseImpl{
+		roomUserRepo:         roomUserRepo,
+		userRepo:             userRepo,
+		authorizationUsecase: authorizationUsecase,
+		roomEventPublisher:   roomEventPublisher,
+		clocker:              clocker,
+		users:                make(map[string]*userPresence),
+	}
+}
+
+func (pu *PresenceUsecaseImpl) Connect(client *model.Client) {
+	pu.update(client.UserID, true, func(user *userPresence) {
+		user.connections[client] = model.Online
+	})
+}
+
+func (pu *PresenceUsecaseImpl) SetStatus(client *model.Client, status model.PresenceStatus) {
+	pu.update(client.UserID, false, func(user *userPresence) {
+		if _, ok := user.connections[client]; ok {
+			user.connections[client] = status
+		}
+	})
+}
+
+func (pu *PresenceUsecaseImpl) Disconnect(client *model.Client) {
+	pu.update(client.UserID, false, func(user *userPresence) {
+		delete(user.connections, client)
+	})
+}
+
+func (pu *PresenceUsecaseImpl) GetRoomPresence(ctx context.Context, roomId string) ([]*model.Presence, error) {
+	if _, err := authorizeCaller(ctx, pu.authorizationUsecase, roomId); err != nil {
+		return nil, err
+	}
+
+	presences := []*model.Presence{}
+	lastEvaluatedKey := ""
+	for {
+		roomUsers, nextKey, err := pu.roomUserRepo.GetUsersByRoomID(ctx, roomId, lastEvaluatedKey, roomPresencePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users by room ID: %w", err)
+		}
+		page := make([]*model.Presence, len(roomUsers))
+		for i, roomUser := range roomUsers {
+			page[i] = pu.presence(roomUser.UserID)
+		}
+		if err := pu.addLastSeen(ctx, page); err != nil {
+			return nil, err
+		}
+		presences = append(presences, page...)
+
+		if nextKey == "" {
+			return presences, nil
+		}
+		lastEvaluatedKey = nextKey
+	}
+}
+
+func (pu *PresenceUsecaseImpl) GetUserPresence(ctx context.Context, userId string) (*model.Presence, error) {
+	user, err := pu.userRepo.GetByID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	presence := pu.presence(userId)
+	setLastSeen(presence, user)
+	return presence, nil
+}
+
+// addLastSeen fills in the last seen time of the offline users among the presences from their users.
+func (pu *PresenceUsecaseImpl) addLastSeen(ctx context.Context, presences []*model.Presence) error {
+	var offline []string
+	for _, presence := range presences {
+		if presence.Status == model.Offline {
+			offline = append(offline, presence.UserID)
+		}
+	}
+	if len(offline) == 0 {
+		return nil
+	}
+
+	users, err := pu.userRepo.BatchGetUsers(ctx, offline)
+	if err != nil {
+		return fmt.Errorf("failed to get the users of offline members: %w", err)
+	}
+	usersById := make(map[string]*model.User, len(users))
+	for _, user := range users {
+		usersById[user.UserID] = user
+	}
+
+	for _, presence := range presences {
+		if user, ok := usersById[presence.UserID]; ok {
+			setLastSeen(presence, user)
+		}
+	}
+	return nil
+}
+
+// setLastSeen takes the last seen time of an offline user from the user when it is later than the one in memory,
+// which is missing once the user is forgotten.
+func setLastSeen(presence *model.Presence, user *model.User) {
+	if presence.Status != model.Offline || user.LastSeenAt == nil {
+		return
+	}
+	if user.LastSeenAt.After(presence.LastSeenAt) {
+		presence.LastSeenAt = *user.LastSeenAt
+	}
+}
+
+// update changes the connections of a user and pushes the new presence to their rooms if it changed. Users that
+// are not connected are only added by a new connection.
+func (pu *PresenceUsecaseImpl) update(userId string, connect bool, change func(*userPresence)) {
+	pu.mu.Lock()
+	user, ok := pu.users[userId]
+	if !ok {
+		if !connect {
+			pu.mu.Unlock()
+			return
+		}
+		user = &userPresence{
+			connections: make(map[*model.Client]model.PresenceStatus),
+			published:   model.Offline,
+		}
+		pu.users[userId] = user
+	}
+	change(user)
+	user.lastSeenAt = pu.clocker.Now()
+	pu.mu.Unlock()
+
+	pu.publish(userId, user)
+}
+
+func (pu *PresenceUsecaseImpl) publish(userId string, user *userPresence) {
+	user.publishMu.Lock()
+	defer user.publishMu.Unlock()
+
+	// The presence is read again after taking publishMu, so a publish that waited for an earlier one sends the latest status.
+	presence := pu.presence(userId)
+	if presence.Status != user.published {
+		roomUsers, err := pu.roomUserRepo.GetAllRoomsByUserID(context.Background(), userId)
+		if err != nil {
+			log.Printf("Failed to get the rooms of user %s to publish their presence: %v", userId, err)
+			return
+		}
+		for _, roomUser := range roomUsers {
+			pu.roomEventPublisher.PublishToRoom(roomUser.RoomID, presence)
+		}
+		user.published = presence.Status
+	}
+
+	if presence.Status == model.Offline {
+		pu.forget(userId, user, presence.LastSeenAt)
+	}
+}
+
+// forget records when the offline user was last seen and drops them from memory, unless they connected again
+// meanwhile. A user that fails to be recorded is kept until their next change; a user without a profile has
+// nowhere to record it and is dropped all the same.
+func (pu *PresenceUsecaseImpl) forget(userId string, user *userPresence, lastSeenAt time.Time) {
+	if err := pu.userRepo.UpdateLastSeen(context.Background(), userId, lastSeenAt); err != nil {
+		var notFoundErr *apperror.NotFoundErr
+		if !errors.As(err, &notFoundErr) {
+			log.Printf("Failed to record when user %s was last seen: %v", userId, err)
+			return
+		}
+	}
+
+	pu.mu.Lock()
+	defer pu.mu.Unlock()
+	if pu.users[userId] == user && len(user.connections) == 0 {
+		delete(pu.users, userId)
+	}
+}
+
+func (pu *PresenceUsecaseImpl) presence(userId string) *model.Presence {
+	pu.mu.Lock()
+	defer pu.mu.Unlock()
+
+	presence := &model.Presence{
+		UserID: userId,
+		Status: model.Offline,
+	}
+	user, ok := pu.users[userId]
+	if !ok {
+		return presence
+	}
+
+	presence.LastSeenAt = user.lastSeenAt
+	for _, status := range user.connections {
+		if status == model.Online {
+			presence.Status = model.Online
+			break
+		}
+		presence.Status = model.Away
+	}
+	return presence
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	modelMocks "github.com/shunsukenagashima/chat-api/pkg/domain/model/mocks"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository/mocks"
+	usecaseMocks "github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPresence(t *testing.T) {
+	clock := clock.FixedClocker{}
+	first := &model.Client{UserID: "1"}
+	second := &model.Client{UserID: "1"}
+
+	testCases := []struct {
+		name            string
+		change          func(model.PresenceTracker)
+		expectedStatus  model.PresenceStatus
+		expectedPublish []model.PresenceStatus
+	}{
+		{
+			name:            "Connected",
+			change:          func(pt model.PresenceTracker) { pt.Connect(first) },
+			expectedStatus:  model.Online,
+			expectedPublish: []model.PresenceStatus{model.Online},
+		},
+		{
+			name: "One Of Two Connections Away",
+			change: func(pt model.PresenceTracker) {
+				pt.Connect(first)
+				pt.Connect(second)
+				pt.SetStatus(first, model.Away)
+			},
+			expectedStatus:  model.Online,
+			expectedPublish: []model.PresenceStatus{model.Online},
+		},
+		{
+			name: "Every Connection Away",
+			change: func(pt model.PresenceTracker) {
+				pt.Connect(first)
+				pt.Connect(second)
+				pt.SetStatus(first, model.Away)
+				pt.SetStatus(second, model.Away)
+			},
+			expectedStatus:  model.Away,
+			expectedPublish: []model.PresenceStatus{model.Online, model.Away},
+		},
+		{
+			name: "Online Connection Left",
+			change: func(pt model.PresenceTracker) {
+				pt.Connect(first)
+				pt.Connect(second)
+				pt.SetStatus(first, model.Away)
+				pt.Disconnect(second)
+			},
+			expectedStatus:  model.Away,
+			expectedPublish: []model.PresenceStatus{model.Online, model.Away},
+		},
+		{
+			name: "Disconnected Twice",
+			change: func(pt model.PresenceTracker) {
+				pt.Connect(first)
+				pt.Disconnect(first)
+				pt.Disconnect(first)
+			},
+			expectedStatus:  model.Offline,
+			expectedPublish: []model.PresenceStatus{model.Online, model.Offline},
+		},
+		{
+			name: "Status Of Unknown Connection",
+			change: func(pt model.PresenceTracker) {
+				pt.SetStatus(first, model.Away)
+			},
+			expectedStatus: model.Offline,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			mockUserRepo := new(mocks.UserRepository)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockRoomUserRepo.On("GetAllRoomsByUserID", mock.Anything, "1").Return([]*model.RoomUser{
+				{RoomID: "1", UserID: "1"},
+				{RoomID: "2", UserID: "1"},
+			}, nil).Maybe()
+			mockUserRepo.On("GetByID", mock.Anything, "1").Return(&model.User{UserID: "1"}, nil)
+			mockUserRepo.On("UpdateLastSeen", mock.Anything, "1", clock.Now()).Return(nil).Maybe()
+
+			var published []model.PresenceStatus
+			mockPublisher.On("PublishToRoom", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				if args.String(0) == "1" {
+					published = append(published, args.Get(1).(*model.Presence).Status)
+				}
+			}).Maybe()
+
+			presenceUsecase := NewPresenceUsecase(mockRoomUserRepo, mockUserRepo, new(usecaseMocks.AuthorizationUsecase), mockPublisher, clock)
+			tc.change(presenceUsecase)
+
+			presence, err := presenceUsecase.GetUserPresence(context.Background(), "1")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, presence.Status)
+			assert.Equal(t, tc.expectedPublish, published)
+			mockPublisher.AssertNumberOfCalls(t, "PublishToRoom", 2*len(tc.expectedPublish))
+		})
+	}
+}
+
+func TestGetRoomPresence(t *testing.T) {
+	mockRoomUserRepo := new(mocks.RoomUserRepository)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+	mockPublisher := new(modelMocks.RoomEventPublisher)
+	clock := clock.FixedClocker{}
+
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "1").Return(&model.RoomUser{RoomID: "1", UserID: "1"}, nil)
+	mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "3").Return(nil, apperror.NewForbiddenErr("Room", "RoomID: 1"))
+	mockRoomUserRepo.On("GetAllRoomsByUserID", mock.Anything, "1").Return([]*model.RoomUser{{RoomID: "1", UserID: "1"}}, nil)
+	mockRoomUserRepo.On("GetUsersByRoomID", mock.Anything, "1", "", roomPresencePageSize).Return([]*model.RoomUser{{RoomID: "1", UserID: "1"}}, "1", nil)
+	mockRoomUserRepo.On("GetUsersByRoomID", mock.Anything, "1", "1", roomPresencePageSize).Return([]*model.RoomUser{{RoomID: "1", UserID: "2"}}, "", nil)
+	mockPublisher.On("PublishToRoom", "1", mock.Anything)
+	lastSeenAt := clock.Now().Add(-time.Hour)
+	mockUserRepo := new(mocks.UserRepository)
+	mockUserRepo.On("BatchGetUsers", mock.Anything, []string{"2"}).Return([]*model.User{{UserID: "2", LastSeenAt: &lastSeenAt}}, nil)
+
+	presenceUsecase := NewPresenceUsecase(mockRoomUserRepo, mockUserRepo, mockAuthorizationUsecase, mockPublisher, clock)
+	presenceUsecase.Connect(&model.Client{UserID: "1"})
+
+	testCases := []struct {
+		name              string
+		callerId          string
+		expectedPresences []*model.Presence
+		expectedErr       error
+	}{
+		{
+			name:     "Success",
+			callerId: "1",
+			expectedPresences: []*model.Presence{
+				{UserID: "1", Status: model.Online, LastSeenAt: clock.Now()},
+				{UserID: "2", Status: model.Offline, LastSeenAt: lastSeenAt},
+			},
+		},
+		{
+			name:        "Not Member",
+			callerId:    "3",
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := authctx.WithUserID(context.Background(), tc.callerId)
+			presences, err := presenceUsecase.GetRoomPresence(ctx, "1")
+
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedPresences, presences)
+		})
+	}
+	mockRoomUserRepo.AssertExpectations(t)
+}
+
+func TestPresence_ForgetsOfflineUsers(t *testing.T) {
+	clock := clock.FixedClocker{}
+
+	testCases := []struct {
+		name              string
+		updateLastSeenErr error
+		expectedForgotten bool
+	}{
+		{
+			name:              "Last Seen Recorded",
+			expectedForgotten: true,
+		},
+		{
+			name:              "User Without Profile",
+			updateLastSeenErr: apperror.NewNotFoundErr("User", "UserID: 1"),
+			expectedForgotten: true,
+		},
+		{
+			name:              "Last Seen Not Recorded",
+			updateLastSeenErr: errors.New("connection refused"),
+			expectedForgotten: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			mockUserRepo := new(mocks.UserRepository)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockRoomUserRepo.On("GetAllRoomsByUserID", mock.Anything, "1").Return([]*model.RoomUser{{RoomID: "1", UserID: "1"}}, nil)
+			mockPublisher.On("PublishToRoom", "1", mock.Anything)
+			mockUserRepo.On("UpdateLastSeen", mock.Anything, "1", clock.Now()).Return(tc.updateLastSeenErr)
+
+			presenceUsecase := NewPresenceUsecase(mockRoomUserRepo, mockUserRepo, new(usecaseMocks.AuthorizationUsecase), mockPublisher, clock).(*PresenceUsecaseImpl)
+			client := &model.Client{UserID: "1"}
+			presenceUsecase.Connect(client)
+			presenceUsecase.Disconnect(client)
+
+			_, kept := presenceUsecase.users["1"]
+			assert.Equal(t, !tc.expectedForgotten, kept)
+			mockUserRepo.AssertExpectations(t)
+			mockPublisher.AssertNumberOfCalls(t, "PublishToRoom", 2)
+		})
+	}
+}
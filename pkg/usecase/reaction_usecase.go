@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
+)
+
+type ReactionUsecaseImpl struct {
+	reactionRepo         repository.ReactionRepository
+	messageRepo          repository.MessageRepository
+	authorizationUsecase usecase.AuthorizationUsecase
+	roomEventPublisher   model.RoomEventPublisher
+	clocker              clock.Clocker
+}
+
+func NewReactionUsecase(reactionRepo repository.ReactionRepository, messageRepo repository.MessageRepository, authorizationUsecase usecase.AuthorizationUsecase, roomEventPublisher model.RoomEventPublisher, clocker clock.Clocker) usecase.ReactionUsecase {
+	return &ReactionUsecaseImpl{
+		reactionRepo:         reactionRepo,
+		messageRepo:          messageRepo,
+		authorizationUsecase: authorizationUsecase,
+		roomEventPublisher:   roomEventPublisher,
+		clocker:              clocker,
+	}
+}
+
+// AddReaction reacts to the message as the caller. Reacting twice with the same emoji has no effect.
+func (ru *ReactionUsecaseImpl) AddReaction(ctx context.Context, roomId, messageId, emoji string) error {
+	roomUser, err := authorizeCaller(ctx, ru.authorizationUsecase, roomId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ru.messageRepo.GetByID(ctx, roomId, messageId); err != nil {
+		return err
+	}
+
+	count, added, err := ru.reactionRepo.Add(ctx, &model.Reaction{
+		MessageID: messageId,
+		UserID:    roomUser.UserID,
+		Emoji:     emoji,
+		CreatedAt: ru.clocker.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if !added {
+		return nil
+	}
+
+	ru.roomEventPublisher.PublishToRoom(roomId, &model.ReactionAddedDetails{
+		RoomID:    roomId,
+		MessageID: messageId,
+		UserID:    roomUser.UserID,
+		Emoji:     emoji,
+		Count:     count,
+	})
+	return nil
+}
+
+// RemoveReaction takes back the caller's reaction. Removing a reaction that does not exist has no effect.
+func (ru *ReactionUsecaseImpl) RemoveReaction(ctx context.Context, roomId, messageId, emoji string) error {
+	roomUser, err := authorizeCaller(ctx, ru.authorizationUsecase, roomId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ru.messageRepo.GetByID(ctx, roomId, messageId); err != nil {
+		return err
+	}
+
+	count, removed, err := ru.reactionRepo.Remove(ctx, messageId, roomUser.UserID, emoji)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return nil
+	}
+
+	ru.roomEventPublisher.PublishToRoom(roomId, &model.ReactionRemovedDetails{
+		RoomID:    roomId,
+		MessageID: messageId,
+		UserID:    roomUser.UserID,
+		Emoji:     emoji,
+		Count:     count,
+	})
+	return nil
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	modelMocks "github.com/shunsukenagashima/chat-api/pkg/domain/model/mocks"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/repository/mocks"
+	usecaseMocks "github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddReaction(t *testing.T) {
+	clock := clock.FixedClocker{}
+
+	testCases := []struct {
+		name            string
+		getByIdErr      error
+		added           bool
+		expectedErr     error
+		expectedPublish bool
+	}{
+		{
+			name:            "Success",
+			added:           true,
+			expectedPublish: true,
+		},
+		{
+			name:  "Already Reacted",
+			added: false,
+		},
+		{
+			name:        "Invalid MessageID",
+			getByIdErr:  apperror.NewNotFoundErr("Message", "MessageID: 1"),
+			expectedErr: apperror.NewNotFoundErr("Message", "MessageID: 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockReactionRepo := new(mocks.ReactionRepository)
+			mockMessageRepo := new(mocks.MessageRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "2").Return(&model.RoomUser{RoomID: "1", UserID: "2"}, nil)
+			mockMessageRepo.On("GetByID", mock.Anything, "1", "1").Return(&model.Message{MessageID: "1", RoomID: "1"}, tc.getByIdErr)
+			mockReactionRepo.On("Add", mock.Anything, &model.Reaction{
+				MessageID: "1",
+				UserID:    "2",
+				Emoji:     "👍",
+				CreatedAt: clock.Now(),
+			}).Return(3, tc.added, nil)
+			mockPublisher.On("PublishToRoom", "1", &model.ReactionAddedDetails{
+				RoomID:    "1",
+				MessageID: "1",
+				UserID:    "2",
+				Emoji:     "👍",
+				Count:     3,
+			}).Return()
+			reactionUsecase := NewReactionUsecase(mockReactionRepo, mockMessageRepo, mockAuthorizationUsecase, mockPublisher, clock)
+
+			ctx := authctx.WithUserID(context.Background(), "2")
+			err := reactionUsecase.AddReaction(ctx, "1", "1", "👍")
+
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedPublish {
+				mockPublisher.AssertExpectations(t)
+			} else {
+				mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRemoveReaction(t *testing.T) {
+	testCases := []struct {
+		name            string
+		removed         bool
+		expectedPublish bool
+	}{
+		{
+			name:            "Success",
+			removed:         true,
+			expectedPublish: true,
+		},
+		{
+			name:    "Not Reacted",
+			removed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockReactionRepo := new(mocks.ReactionRepository)
+			mockMessageRepo := new(mocks.MessageRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, "1", "2").Return(&model.RoomUser{RoomID: "1", UserID: "2"}, nil)
+			mockMessageRepo.On("GetByID", mock.Anything, "1", "1").Return(&model.Message{MessageID: "1", RoomID: "1"}, nil)
+			mockReactionRepo.On("Remove", mock.Anything, "1", "2", "👍").Return(0, tc.removed, nil)
+			mockPublisher.On("PublishToRoom", "1", &model.ReactionRemovedDetails{
+				RoomID:    "1",
+				MessageID: "1",
+				UserID:    "2",
+				Emoji:     "👍",
+				Count:     0,
+			}).Return()
+			reactionUsecase := NewReactionUsecase(mockReactionRepo, mockMessageRepo, mockAuthorizationUsecase, mockPublisher, clock.FixedClocker{})
+
+			ctx := authctx.WithUserID(context.Background(), "2")
+			err := reactionUsecase.RemoveReaction(ctx, "1", "1", "👍")
+
+			assert.NoError(t, err)
+			if tc.expectedPublish {
+				mockPublisher.AssertExpectations(t)
+			} else {
+				mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+			}
+			mockReactionRepo.AssertExpectations(t)
+		})
+	}
+}
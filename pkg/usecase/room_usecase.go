@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/shunsukenagashima/chat-api/pkg/apperror"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
@@ -11,19 +10,32 @@ import (
 )
 
 type RoomUsecaseImpl struct {
-	roomRepo repository.RoomRepository
-	userRepo repository.UserRepository
+	roomRepo             repository.RoomRepository
+	userRepo             repository.UserRepository
+	authorizationUsecase usecase.AuthorizationUsecase
 }
 
-func NewRoomUsecase(roomRepo repository.RoomRepository, userRepo repository.UserRepository) usecase.RoomUsecase {
+func NewRoomUsecase(roomRepo repository.RoomRepository, userRepo repository.UserRepository, authorizationUsecase usecase.AuthorizationUsecase) usecase.RoomUsecase {
 	return &RoomUsecaseImpl{
 		roomRepo,
 		userRepo,
+		authorizationUsecase,
 	}
 }
 
 func (ru *RoomUsecaseImpl) GetRoomByID(ctx context.Context, roomId string) (*model.Room, error) {
-	return ru.roomRepo.GetByID(ctx, roomId)
+	room, err := ru.roomRepo.GetByID(ctx, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	if room.RoomType == model.Private {
+		if _, err := authorizeCaller(ctx, ru.authorizationUsecase, roomId); err != nil {
+			return nil, err
+		}
+	}
+
+	return room, nil
 }
 
 func (ru *RoomUsecaseImpl) GetAllPublicRoom(ctx context.Context) ([]*model.Room, error) {
@@ -60,7 +72,11 @@ func (ru *RoomUsecaseImpl) DeleteRoom(ctx context.Context, roomId string) error
 		return err
 	}
 	if room == nil {
-		return fmt.Errorf("room with the ID '%s' couldn't be found", roomId)
+		return apperror.NewNotFoundErr("Room", "RoomID: "+roomId)
+	}
+
+	if _, err := authorizeCallerRole(ctx, ru.authorizationUsecase, roomId, model.Owner); err != nil {
+		return err
 	}
 
 	if err := ru.roomRepo.Delete(ctx, roomId); err != nil {
@@ -71,12 +87,16 @@ func (ru *RoomUsecaseImpl) DeleteRoom(ctx context.Context, roomId string) error
 }
 
 func (ru *RoomUsecaseImpl) UpdateRoom(ctx context.Context, room *model.Room) error {
+	if _, err := authorizeCallerRole(ctx, ru.authorizationUsecase, room.RoomID, model.Owner, model.Admin); err != nil {
+		return err
+	}
+
 	existingRoom, err := ru.roomRepo.GetByName(ctx, room.Name)
 	if err != nil {
 		return err
 	}
 	if existingRoom != nil && existingRoom.RoomID != room.RoomID {
-		return fmt.Errorf("name of chat room '%s' is duplicated", room.Name)
+		return apperror.NewAlreadyExistsErr("Room", "RoomName: "+room.Name)
 	}
 
 	if err := ru.roomRepo.Update(ctx, room); err != nil {
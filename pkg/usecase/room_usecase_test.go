@@ -2,12 +2,13 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"testing"
 
 	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository/mocks"
+	usecaseMocks "github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -22,7 +23,7 @@ func TestGetRoomByID(t *testing.T) {
 	}
 
 	mockRoomRepo.On("GetByID", mock.Anything, mockRoom.RoomID).Return(mockRoom, nil)
-	roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo)
+	roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo, new(usecaseMocks.AuthorizationUsecase))
 
 	room, err := roomUsecase.GetRoomByID(context.Background(), mockRoom.RoomID)
 
@@ -34,6 +35,62 @@ func TestGetRoomByID(t *testing.T) {
 	mockRoomRepo.AssertExpectations(t)
 }
 
+func TestGetRoomByID_PrivateRoom(t *testing.T) {
+	mockRoom := &model.Room{
+		RoomID:   "1",
+		Name:     "Room1",
+		RoomType: model.Private,
+	}
+
+	testCases := []struct {
+		name                string
+		userId              string
+		mockAuthorizeReturn *model.RoomUser
+		mockAuthorizeErr    error
+		expectedErr         error
+	}{
+		{
+			name:                "Member",
+			userId:              "1",
+			mockAuthorizeReturn: &model.RoomUser{RoomID: mockRoom.RoomID, UserID: "1"},
+			mockAuthorizeErr:    nil,
+			expectedErr:         nil,
+		},
+		{
+			name:                "Not Member",
+			userId:              "2",
+			mockAuthorizeReturn: nil,
+			mockAuthorizeErr:    apperror.NewNotFoundErr("Room", "RoomID: "+mockRoom.RoomID),
+			expectedErr:         apperror.NewNotFoundErr("Room", "RoomID: "+mockRoom.RoomID),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomRepo := new(mocks.RoomRepository)
+			mockUserRepo := new(mocks.UserRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+
+			mockRoomRepo.On("GetByID", mock.Anything, mockRoom.RoomID).Return(mockRoom, nil)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, mockRoom.RoomID, tc.userId).Return(tc.mockAuthorizeReturn, tc.mockAuthorizeErr)
+
+			roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo, mockAuthorizationUsecase)
+
+			ctx := authctx.WithUserID(context.Background(), tc.userId)
+			room, err := roomUsecase.GetRoomByID(ctx, mockRoom.RoomID)
+
+			if tc.expectedErr != nil {
+				assert.Nil(t, room)
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, mockRoom, room)
+			}
+			mockAuthorizationUsecase.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetAllPublicRoom(t *testing.T) {
 	mockRepo := new(mocks.RoomRepository)
 	mockUserRepo := new(mocks.UserRepository)
@@ -51,7 +108,7 @@ func TestGetAllPublicRoom(t *testing.T) {
 	}
 
 	mockRepo.On("GetAllPublic", mock.Anything).Return(mockRooms, nil)
-	roomUsecase := NewRoomUsecase(mockRepo, mockUserRepo)
+	roomUsecase := NewRoomUsecase(mockRepo, mockUserRepo, new(usecaseMocks.AuthorizationUsecase))
 
 	rooms, err := roomUsecase.GetAllPublicRoom(context.Background())
 
@@ -126,7 +183,7 @@ func TestCreateRoom(t *testing.T) {
 			mockUserRepo.On("GetByID", mock.Anything, tc.ownerId).Return(tc.mockUserRepoReturn, nil)
 			mockRoomRepo.On("CreateAndAddUser", mock.Anything, tc.room, tc.ownerId).Return(nil)
 
-			roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo)
+			roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo, new(usecaseMocks.AuthorizationUsecase))
 
 			err := roomUsecase.CreateRoom(context.Background(), tc.room, tc.ownerId)
 
@@ -155,6 +212,7 @@ func TestDeleteRoom(t *testing.T) {
 		name               string
 		roomId             string
 		mockRoomRepoReturn *model.Room
+		mockAuthorizeErr   error
 		expectedErr        error
 	}{
 		{
@@ -167,7 +225,14 @@ func TestDeleteRoom(t *testing.T) {
 			name:               "Invalid RoomID",
 			roomId:             "invalid_roomID",
 			mockRoomRepoReturn: nil,
-			expectedErr:        errors.New("room with the ID 'invalid_roomID' couldn't be found"),
+			expectedErr:        apperror.NewNotFoundErr("Room", "RoomID: invalid_roomID"),
+		},
+		{
+			name:               "Not Owner",
+			roomId:             roomId,
+			mockRoomRepoReturn: mockRoom,
+			mockAuthorizeErr:   apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+			expectedErr:        apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
 		},
 	}
 
@@ -176,16 +241,21 @@ func TestDeleteRoom(t *testing.T) {
 			mockRoomRepo := new(mocks.RoomRepository)
 			mockUserRepo := new(mocks.UserRepository)
 
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+
 			mockRoomRepo.On("GetByID", mock.Anything, tc.roomId).Return(tc.mockRoomRepoReturn, nil)
 			mockRoomRepo.On("Delete", mock.Anything, tc.roomId).Return(nil)
+			mockAuthorizationUsecase.On("AuthorizeRoomRole", mock.Anything, tc.roomId, "1", model.Owner).Return(&model.RoomUser{RoomID: tc.roomId, UserID: "1", Role: model.Owner}, tc.mockAuthorizeErr)
 
-			roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo)
+			roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo, mockAuthorizationUsecase)
 
-			err := roomUsecase.DeleteRoom(context.Background(), tc.roomId)
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := roomUsecase.DeleteRoom(ctx, tc.roomId)
 
 			if tc.expectedErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, tc.expectedErr.Error())
+				mockRoomRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
 			} else {
 				assert.NoError(t, err)
 				mockRoomRepo.AssertExpectations(t)
@@ -204,6 +274,7 @@ func TestUpdateRoom(t *testing.T) {
 	testCases := []struct {
 		name                string
 		room                *model.Room
+		mockAuthorizeErr    error
 		expectedErr         error
 		mockGetByNameReturn *model.Room
 	}{
@@ -224,9 +295,20 @@ func TestUpdateRoom(t *testing.T) {
 				Name:     mockRoom.Name,
 				RoomType: model.Private,
 			},
-			expectedErr:         errors.New("name of chat room 'Room1' is duplicated"),
+			expectedErr:         apperror.NewAlreadyExistsErr("Room", "RoomName: Room1"),
 			mockGetByNameReturn: mockRoom,
 		},
+		{
+			name: "Not Owner Or Admin",
+			room: &model.Room{
+				RoomID:   mockRoom.RoomID,
+				Name:     mockRoom.Name + "updated",
+				RoomType: model.Private,
+			},
+			mockAuthorizeErr:    apperror.NewForbiddenErr("Room", "RoomID: 1, Role: member"),
+			expectedErr:         apperror.NewForbiddenErr("Room", "RoomID: 1, Role: member"),
+			mockGetByNameReturn: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -234,12 +316,16 @@ func TestUpdateRoom(t *testing.T) {
 			mockRoomRepo := new(mocks.RoomRepository)
 			mockUserRepo := new(mocks.UserRepository)
 
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+
 			mockRoomRepo.On("GetByName", mock.Anything, tc.room.Name).Return(tc.mockGetByNameReturn, nil)
 			mockRoomRepo.On("Update", mock.Anything, tc.room).Return(nil)
+			mockAuthorizationUsecase.On("AuthorizeRoomRole", mock.Anything, tc.room.RoomID, "1", model.Owner, model.Admin).Return(&model.RoomUser{RoomID: tc.room.RoomID, UserID: "1", Role: model.Admin}, tc.mockAuthorizeErr)
 
-			roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo)
+			roomUsecase := NewRoomUsecase(mockRoomRepo, mockUserRepo, mockAuthorizationUsecase)
 
-			err := roomUsecase.UpdateRoom(context.Background(), tc.room)
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := roomUsecase.UpdateRoom(ctx, tc.room)
 
 			if tc.expectedErr != nil {
 				assert.Error(t, err)
@@ -2,46 +2,94 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
 )
 
 type RoomUserUsecaseImpl struct {
-	roomUserRepo repository.RoomUserRepository
-	userRepo     repository.UserRepository
-	roomRepo     repository.RoomRepository
+	roomUserRepo         repository.RoomUserRepository
+	userRepo             repository.UserRepository
+	roomRepo             repository.RoomRepository
+	messageRepo          repository.MessageRepository
+	authorizationUsecase usecase.AuthorizationUsecase
+	globalHub            model.Hub
+	roomEventPublisher   model.RoomEventPublisher
 }
 
-func NewRoomUserUsecase(roomUserRepo repository.RoomUserRepository, userRepo repository.UserRepository, roomRepo repository.RoomRepository) usecase.RoomUserUsecase {
+func NewRoomUserUsecase(roomUserRepo repository.RoomUserRepository, userRepo repository.UserRepository, roomRepo repository.RoomRepository, messageRepo repository.MessageRepository, authorizationUsecase usecase.AuthorizationUsecase, globalHub model.Hub, roomEventPublisher model.RoomEventPublisher) usecase.RoomUserUsecase {
 	return &RoomUserUsecaseImpl{
 		roomUserRepo,
 		userRepo,
 		roomRepo,
+		messageRepo,
+		authorizationUsecase,
+		globalHub,
+		roomEventPublisher,
 	}
 }
 
-func (ru *RoomUserUsecaseImpl) GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.Room, error) {
+// GetAllRoomsByUserID reads the rooms and their unread counts in one batch each. Memberships outlive their room,
+// so the rooms that no longer exist are left out.
+func (ru *RoomUserUsecaseImpl) GetAllRoomsByUserID(ctx context.Context, userId string) ([]*model.UserRoom, error) {
+	if callerId, _ := authctx.UserID(ctx); callerId != userId {
+		return nil, apperror.NewForbiddenErr("User", "UserID: "+userId)
+	}
+
 	roomUsers, err := ru.roomUserRepo.GetAllRoomsByUserID(ctx, userId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all rooms by user ID: %w", err)
 	}
 
-	rooms := []*model.Room{}
+	roomIds := make([]string, 0, len(roomUsers))
+	lastReadAt := make(map[string]time.Time, len(roomUsers))
 	for _, roomUser := range roomUsers {
-		room, err := ru.roomRepo.GetByID(ctx, roomUser.RoomID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get room by ID: %w", err)
+		roomIds = append(roomIds, roomUser.RoomID)
+		lastReadAt[roomUser.RoomID] = roomUser.LastReadAt
+	}
+
+	found, err := ru.roomRepo.BatchGetByIDs(ctx, roomIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rooms by ID: %w", err)
+	}
+	roomsById := make(map[string]*model.Room, len(found))
+	for _, room := range found {
+		roomsById[room.RoomID] = room
+	}
+
+	unreadCounts, err := ru.messageRepo.CountMessagesSince(ctx, userId, lastReadAt, model.MaxUnreadCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	rooms := []*model.UserRoom{}
+	for _, roomUser := range roomUsers {
+		room, ok := roomsById[roomUser.RoomID]
+		if !ok {
+			continue
 		}
-		rooms = append(rooms, room)
+
+		rooms = append(rooms, &model.UserRoom{
+			Room:              *room,
+			UnreadCount:       unreadCounts[roomUser.RoomID],
+			LastReadMessageID: roomUser.LastReadMessageID,
+		})
 	}
 
 	return rooms, nil
 }
 
 func (ru *RoomUserUsecaseImpl) GetUsersByRoomID(ctx context.Context, roomId, lastEvaluatedKey string, limit int) ([]*model.User, string, error) {
+	if _, err := authorizeCaller(ctx, ru.authorizationUsecase, roomId); err != nil {
+		return nil, "", err
+	}
+
 	roomUsersers, nextKey, err := ru.roomUserRepo.GetUsersByRoomID(ctx, roomId, lastEvaluatedKey, limit)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get users by room ID: %w", err)
@@ -59,10 +107,38 @@ func (ru *RoomUserUsecaseImpl) GetUsersByRoomID(ctx context.Context, roomId, las
 	return users, nextKey, nil
 }
 
+// RemoveUserFromRoom lets members leave the room and owners and admins kick
+// members ranked below them. The owner has to transfer the ownership before leaving.
+// The sockets the user has open in the room are closed.
 func (ru *RoomUserUsecaseImpl) RemoveUserFromRoom(ctx context.Context, roomId, userId string) error {
+	caller, err := authorizeCaller(ctx, ru.authorizationUsecase, roomId)
+	if err != nil {
+		return err
+	}
+
+	if caller.UserID == userId {
+		if caller.EffectiveRole() == model.Owner {
+			return apperror.NewForbiddenErr("Room", "RoomID: "+roomId+", the owner must transfer the ownership before leaving")
+		}
+	} else {
+		target, err := ru.roomUserRepo.GetByRoomIDAndUserID(ctx, roomId, userId)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the user with ID %s in the room: %w", userId, err)
+		}
+		if caller.EffectiveRole() == model.Member || !caller.EffectiveRole().Outranks(target.EffectiveRole()) {
+			return apperror.NewForbiddenErr("Room", "RoomID: "+roomId+", Role: "+string(caller.EffectiveRole()))
+		}
+	}
+
 	if err := ru.roomUserRepo.RemoveUserFromRoom(ctx, roomId, userId); err != nil {
 		return fmt.Errorf("failed to remove the user from the room: %w", err)
 	}
+
+	// membership is checked only when a socket connects, so the hubs close the ones the user still has open
+	ru.roomEventPublisher.PublishToRoom(roomId, &model.MemberRemovedDetails{
+		RoomID: roomId,
+		UserID: userId,
+	})
 	return nil
 }
 
@@ -79,12 +155,110 @@ func (ru *RoomUserUsecaseImpl) AddUsersToRoom(ctx context.Context, roomId string
 		return fmt.Errorf("failed to fetch the room with ID %s: %w", roomId, err)
 	}
 	if room == nil {
-		return fmt.Errorf("room with the ID %s couldn't be found", roomId)
+		return apperror.NewNotFoundErr("Room", "RoomID: "+roomId)
+	}
+
+	// anyone may join a public room by themselves; everything else is an invitation by an owner or admin
+	callerId, _ := authctx.UserID(ctx)
+	selfJoin := room.RoomType == model.Public && len(userIDs) == 1 && userIDs[0] == callerId
+	if !selfJoin {
+		if _, err := ru.authorizationUsecase.AuthorizeRoomRole(ctx, roomId, callerId, model.Owner, model.Admin); err != nil {
+			return err
+		}
 	}
 
-	if err := ru.roomUserRepo.AddUsersToRoom(ctx, roomId, userIDs); err != nil {
+	// re-adding an existing member would overwrite their role
+	var newUserIDs []string
+	for _, userId := range userIDs {
+		_, err := ru.roomUserRepo.GetByRoomIDAndUserID(ctx, roomId, userId)
+		if err == nil {
+			continue
+		}
+		var notFoundErr *apperror.NotFoundErr
+		if !errors.As(err, &notFoundErr) {
+			return fmt.Errorf("failed to fetch the user with ID %s in the room: %w", userId, err)
+		}
+		newUserIDs = append(newUserIDs, userId)
+	}
+	if len(newUserIDs) == 0 {
+		return nil
+	}
+
+	if err := ru.roomUserRepo.AddUsersToRoom(ctx, roomId, newUserIDs); err != nil {
 		return fmt.Errorf("failed to add the users to the room: %w", err)
 	}
 
 	return nil
 }
+
+// UpdateUserRole promotes a member to admin or demotes an admin to member.
+// Only the owner can change roles, and the owner role itself moves only through TransferOwnership.
+func (ru *RoomUserUsecaseImpl) UpdateUserRole(ctx context.Context, roomId, userId string, role model.RoomRole) error {
+	if role != model.Admin && role != model.Member {
+		return apperror.NewBadRequestErr("RoomUser", "Role: "+string(role)+" cannot be assigned, transfer the ownership instead")
+	}
+
+	caller, err := authorizeCallerRole(ctx, ru.authorizationUsecase, roomId, model.Owner)
+	if err != nil {
+		return err
+	}
+	if caller.UserID == userId {
+		return apperror.NewForbiddenErr("Room", "RoomID: "+roomId+", the owner cannot change their own role")
+	}
+
+	if _, err := ru.roomUserRepo.GetByRoomIDAndUserID(ctx, roomId, userId); err != nil {
+		return fmt.Errorf("failed to fetch the user with ID %s in the room: %w", userId, err)
+	}
+
+	if err := ru.roomUserRepo.UpdateRole(ctx, roomId, userId, role); err != nil {
+		return fmt.Errorf("failed to update the role of the user: %w", err)
+	}
+
+	ru.publishRoleChange(&model.RoomUserDetails{
+		RoomID: roomId,
+		UserID: userId,
+		Role:   role,
+	})
+
+	return nil
+}
+
+// TransferOwnership hands the room over to another member. The previous owner becomes an admin.
+func (ru *RoomUserUsecaseImpl) TransferOwnership(ctx context.Context, roomId, newOwnerId string) error {
+	caller, err := authorizeCallerRole(ctx, ru.authorizationUsecase, roomId, model.Owner)
+	if err != nil {
+		return err
+	}
+	if caller.UserID == newOwnerId {
+		return nil
+	}
+
+	if _, err := ru.roomUserRepo.GetByRoomIDAndUserID(ctx, roomId, newOwnerId); err != nil {
+		return fmt.Errorf("failed to fetch the user with ID %s in the room: %w", newOwnerId, err)
+	}
+
+	if err := ru.roomUserRepo.TransferOwnership(ctx, roomId, caller.UserID, newOwnerId); err != nil {
+		return fmt.Errorf("failed to transfer the ownership of the room: %w", err)
+	}
+
+	ru.publishRoleChange(&model.RoomUserDetails{
+		RoomID: roomId,
+		UserID: caller.UserID,
+		Role:   model.Admin,
+	})
+	ru.publishRoleChange(&model.RoomUserDetails{
+		RoomID: roomId,
+		UserID: newOwnerId,
+		Role:   model.Owner,
+	})
+
+	return nil
+}
+
+// publishRoleChange tells the members connected to the room about the new role, and the member whose role
+// changed wherever they are connected. The global hub delivers it to that member only, so that the members
+// of a private room are not disclosed to everyone.
+func (ru *RoomUserUsecaseImpl) publishRoleChange(change *model.RoomUserDetails) {
+	ru.roomEventPublisher.PublishToRoom(change.RoomID, change)
+	ru.globalHub.BroadcastEvent(change)
+}
@@ -4,9 +4,15 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/shunsukenagashima/chat-api/pkg/apperror"
+	"github.com/shunsukenagashima/chat-api/pkg/authctx"
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	modelMocks "github.com/shunsukenagashima/chat-api/pkg/domain/model/mocks"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository/mocks"
+	usecaseMocks "github.com/shunsukenagashima/chat-api/pkg/domain/usecase/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,6 +21,8 @@ func TestGetAllRoomsByUserID(t *testing.T) {
 	mockRoomUserRepo := new(mocks.RoomUserRepository)
 	mockUserRepo := new(mocks.UserRepository)
 	mockRoomRepo := new(mocks.RoomRepository)
+	mockMessageRepo := new(mocks.MessageRepository)
+	lastReadAt := clock.FixedClocker{}.Now()
 	mockRooms := []*model.Room{
 		{
 			RoomID:   "1",
@@ -34,46 +42,198 @@ func TestGetAllRoomsByUserID(t *testing.T) {
 			UserID: "1",
 		},
 		{
-			RoomID: "2",
+			RoomID:            "2",
+			UserID:            "1",
+			LastReadMessageID: "10",
+			LastReadAt:        lastReadAt,
+		},
+		{
+			RoomID: "3",
 			UserID: "1",
 		},
 	}
 
 	mockRoomUserRepo.On("GetAllRoomsByUserID", mock.Anything, mock.Anything).Return(mockRoomUsers, nil)
-	mockRoomRepo.On("GetByID", mock.Anything, mock.Anything).Return(mockRooms[0], nil).Once()
-	mockRoomRepo.On("GetByID", mock.Anything, mock.Anything).Return(mockRooms[1], nil).Once()
+	// the rooms come back in another order, and the room of the third membership was deleted
+	mockRoomRepo.On("BatchGetByIDs", mock.Anything, []string{"1", "2", "3"}).Return([]*model.Room{mockRooms[1], mockRooms[0]}, nil).Once()
+	mockMessageRepo.On("CountMessagesSince", mock.Anything, "1", map[string]time.Time{"1": {}, "2": lastReadAt, "3": {}}, model.MaxUnreadCount).
+		Return(map[string]int{"1": model.MaxUnreadCount, "2": 3, "3": 0}, nil).Once()
 
-	roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo)
+	roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo, mockMessageRepo, new(usecaseMocks.AuthorizationUsecase), new(modelMocks.Hub), new(modelMocks.RoomEventPublisher))
 
-	rooms, err := roomUserUsecase.GetAllRoomsByUserID(context.Background(), "1")
+	ctx := authctx.WithUserID(context.Background(), "1")
+	rooms, err := roomUserUsecase.GetAllRoomsByUserID(ctx, "1")
 
 	assert.NoError(t, err)
-	assert.NotEmpty(t, rooms)
-	assert.Equal(t, len(mockRooms), len(rooms))
-	for i, room := range rooms {
-		assert.Equal(t, mockRooms[i].RoomID, room.RoomID)
-		assert.Equal(t, mockRooms[i].Name, room.Name)
-		assert.Equal(t, mockRooms[i].RoomType, room.RoomType)
-	}
+	assert.Equal(t, []*model.UserRoom{
+		{Room: *mockRooms[0], UnreadCount: model.MaxUnreadCount},
+		{Room: *mockRooms[1], UnreadCount: 3, LastReadMessageID: "10"},
+	}, rooms)
 	mockRoomUserRepo.AssertExpectations(t)
+	mockRoomRepo.AssertExpectations(t)
+	mockMessageRepo.AssertExpectations(t)
 }
 
-func TestRemoveUserFromRoom(t *testing.T) {
+func TestGetAllRoomsByUserID_OtherUser(t *testing.T) {
 	mockRoomUserRepo := new(mocks.RoomUserRepository)
 	mockUserRepo := new(mocks.UserRepository)
 	mockRoomRepo := new(mocks.RoomRepository)
 
+	roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo, new(mocks.MessageRepository), new(usecaseMocks.AuthorizationUsecase), new(modelMocks.Hub), new(modelMocks.RoomEventPublisher))
+
+	ctx := authctx.WithUserID(context.Background(), "2")
+	rooms, err := roomUserUsecase.GetAllRoomsByUserID(ctx, "1")
+
+	assert.Nil(t, rooms)
+	assert.Equal(t, apperror.NewForbiddenErr("User", "UserID: 1"), err)
+	mockRoomUserRepo.AssertNotCalled(t, "GetAllRoomsByUserID", mock.Anything, mock.Anything)
+}
+
+func TestRemoveUserFromRoom(t *testing.T) {
 	roomId := "1"
-	userId := "1"
 
-	mockRoomUserRepo.On("RemoveUserFromRoom", mock.Anything, roomId, userId).Return(nil)
+	testCases := []struct {
+		name        string
+		callerId    string
+		userId      string
+		callerRole  model.RoomRole
+		targetRole  model.RoomRole
+		expectedErr error
+	}{
+		{
+			name:        "Member Leaves",
+			callerId:    "1",
+			userId:      "1",
+			callerRole:  model.Member,
+			expectedErr: nil,
+		},
+		{
+			name:        "Owner Leaves",
+			callerId:    "1",
+			userId:      "1",
+			callerRole:  model.Owner,
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1, the owner must transfer the ownership before leaving"),
+		},
+		{
+			name:        "Admin Kicks Member",
+			callerId:    "1",
+			userId:      "2",
+			callerRole:  model.Admin,
+			targetRole:  model.Member,
+			expectedErr: nil,
+		},
+		{
+			name:        "Owner Kicks Admin",
+			callerId:    "1",
+			userId:      "2",
+			callerRole:  model.Owner,
+			targetRole:  model.Admin,
+			expectedErr: nil,
+		},
+		{
+			name:        "Admin Kicks Admin",
+			callerId:    "1",
+			userId:      "2",
+			callerRole:  model.Admin,
+			targetRole:  model.Admin,
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+		},
+		{
+			name:        "Member Kicks Member",
+			callerId:    "1",
+			userId:      "2",
+			callerRole:  model.Member,
+			targetRole:  model.Member,
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1, Role: member"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			mockUserRepo := new(mocks.UserRepository)
+			mockRoomRepo := new(mocks.RoomRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
 
-	roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo)
+			mockAuthorizationUsecase.On("AuthorizeRoomMember", mock.Anything, roomId, tc.callerId).Return(&model.RoomUser{RoomID: roomId, UserID: tc.callerId, Role: tc.callerRole}, nil)
+			mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, roomId, tc.userId).Return(&model.RoomUser{RoomID: roomId, UserID: tc.userId, Role: tc.targetRole}, nil)
+			mockRoomUserRepo.On("RemoveUserFromRoom", mock.Anything, roomId, tc.userId).Return(nil)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+			mockPublisher.On("PublishToRoom", roomId, &model.MemberRemovedDetails{RoomID: roomId, UserID: tc.userId}).Return()
 
-	err := roomUserUsecase.RemoveUserFromRoom(context.Background(), roomId, userId)
+			roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo, new(mocks.MessageRepository), mockAuthorizationUsecase, new(modelMocks.Hub), mockPublisher)
 
-	assert.NoError(t, err)
-	mockRoomUserRepo.AssertExpectations(t)
+			ctx := authctx.WithUserID(context.Background(), tc.callerId)
+			err := roomUserUsecase.RemoveUserFromRoom(ctx, roomId, tc.userId)
+
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+				mockRoomUserRepo.AssertNotCalled(t, "RemoveUserFromRoom", mock.Anything, mock.Anything, mock.Anything)
+				mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				mockRoomUserRepo.AssertCalled(t, "RemoveUserFromRoom", mock.Anything, roomId, tc.userId)
+				mockPublisher.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestAddUsersToRoom_SelfJoin(t *testing.T) {
+	mockRoom := &model.Room{
+		RoomID:   "1",
+		Name:     "room-1",
+		RoomType: model.Public,
+	}
+
+	testCases := []struct {
+		name        string
+		roomType    model.RoomType
+		expectedErr error
+	}{
+		{
+			name:        "Public Room",
+			roomType:    model.Public,
+			expectedErr: nil,
+		},
+		{
+			name:        "Private Room",
+			roomType:    model.Private,
+			expectedErr: apperror.NewNotFoundErr("Room", "RoomID: 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			mockUserRepo := new(mocks.UserRepository)
+			mockRoomRepo := new(mocks.RoomRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+
+			room := *mockRoom
+			room.RoomType = tc.roomType
+
+			mockUserRepo.On("GetByID", mock.Anything, "2").Return(&model.User{UserID: "2"}, nil)
+			mockRoomRepo.On("GetByID", mock.Anything, room.RoomID).Return(&room, nil)
+			mockAuthorizationUsecase.On("AuthorizeRoomRole", mock.Anything, room.RoomID, "2", model.Owner, model.Admin).Return(nil, tc.expectedErr)
+			mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, room.RoomID, "2").Return(nil, apperror.NewNotFoundErr("RoomUser", "RoomID: 1, UserID: 2"))
+			mockRoomUserRepo.On("AddUsersToRoom", mock.Anything, room.RoomID, []string{"2"}).Return(nil)
+
+			roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo, new(mocks.MessageRepository), mockAuthorizationUsecase, new(modelMocks.Hub), new(modelMocks.RoomEventPublisher))
+
+			ctx := authctx.WithUserID(context.Background(), "2")
+			err := roomUserUsecase.AddUsersToRoom(ctx, room.RoomID, []string{"2"})
+
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+				mockRoomUserRepo.AssertNotCalled(t, "AddUsersToRoom", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				mockAuthorizationUsecase.AssertNotCalled(t, "AuthorizeRoomRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mockRoomUserRepo.AssertExpectations(t)
+			}
+		})
+	}
 }
 
 func TestAddUsersToRoom(t *testing.T) {
@@ -147,9 +307,14 @@ func TestAddUsersToRoom(t *testing.T) {
 				mockRoomRepo.On("GetByID", mock.Anything, tc.roomId).Return(mockRoom, nil)
 			}
 
-			roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockAuthorizationUsecase.On("AuthorizeRoomRole", mock.Anything, tc.roomId, "1", model.Owner, model.Admin).Return(&model.RoomUser{RoomID: tc.roomId, UserID: "1", Role: model.Owner}, nil)
+			mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, tc.roomId, mock.Anything).Return(nil, apperror.NewNotFoundErr("RoomUser", "RoomID: "+tc.roomId))
+
+			roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo, new(mocks.MessageRepository), mockAuthorizationUsecase, new(modelMocks.Hub), new(modelMocks.RoomEventPublisher))
 
-			err := roomUserUsecase.AddUsersToRoom(context.Background(), tc.roomId, tc.userIDs)
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := roomUserUsecase.AddUsersToRoom(ctx, tc.roomId, tc.userIDs)
 
 			if tc.expectedErr != nil {
 				assert.Error(t, err)
@@ -161,3 +326,179 @@ func TestAddUsersToRoom(t *testing.T) {
 		})
 	}
 }
+
+func TestAddUsersToRoom_SkipsExistingMembers(t *testing.T) {
+	mockRoomUserRepo := new(mocks.RoomUserRepository)
+	mockUserRepo := new(mocks.UserRepository)
+	mockRoomRepo := new(mocks.RoomRepository)
+	mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+
+	mockUserRepo.On("GetByID", mock.Anything, mock.Anything).Return(&model.User{}, nil)
+	mockRoomRepo.On("GetByID", mock.Anything, "1").Return(&model.Room{RoomID: "1", Name: "room-1", RoomType: model.Private}, nil)
+	mockAuthorizationUsecase.On("AuthorizeRoomRole", mock.Anything, "1", "1", model.Owner, model.Admin).Return(&model.RoomUser{RoomID: "1", UserID: "1", Role: model.Owner}, nil)
+	mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, "1", "2").Return(&model.RoomUser{RoomID: "1", UserID: "2", Role: model.Admin}, nil)
+	mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, "1", "3").Return(nil, apperror.NewNotFoundErr("RoomUser", "RoomID: 1, UserID: 3"))
+	mockRoomUserRepo.On("AddUsersToRoom", mock.Anything, "1", []string{"3"}).Return(nil)
+
+	roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo, new(mocks.MessageRepository), mockAuthorizationUsecase, new(modelMocks.Hub), new(modelMocks.RoomEventPublisher))
+
+	ctx := authctx.WithUserID(context.Background(), "1")
+	err := roomUserUsecase.AddUsersToRoom(ctx, "1", []string{"2", "3"})
+
+	assert.NoError(t, err)
+	mockRoomUserRepo.AssertExpectations(t)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	roomId := "1"
+
+	testCases := []struct {
+		name             string
+		userId           string
+		role             model.RoomRole
+		mockAuthorizeErr error
+		mockTargetErr    error
+		expectedErr      error
+	}{
+		{
+			name:   "Promote To Admin",
+			userId: "2",
+			role:   model.Admin,
+		},
+		{
+			name:   "Demote To Member",
+			userId: "2",
+			role:   model.Member,
+		},
+		{
+			name:        "Assign Owner",
+			userId:      "2",
+			role:        model.Owner,
+			expectedErr: apperror.NewBadRequestErr("RoomUser", "Role: owner cannot be assigned, transfer the ownership instead"),
+		},
+		{
+			name:        "Change Own Role",
+			userId:      "1",
+			role:        model.Member,
+			expectedErr: apperror.NewForbiddenErr("Room", "RoomID: 1, the owner cannot change their own role"),
+		},
+		{
+			name:             "Not Owner",
+			userId:           "2",
+			role:             model.Admin,
+			mockAuthorizeErr: apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+			expectedErr:      apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+		},
+		{
+			name:          "Target Not Member",
+			userId:        "2",
+			role:          model.Admin,
+			mockTargetErr: apperror.NewNotFoundErr("RoomUser", "RoomID: 1, UserID: 2"),
+			expectedErr:   errors.New("failed to fetch the user with ID 2 in the room: RoomUser RoomID: 1, UserID: 2: not found"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			mockUserRepo := new(mocks.UserRepository)
+			mockRoomRepo := new(mocks.RoomRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockHub := new(modelMocks.Hub)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+
+			var caller *model.RoomUser
+			if tc.mockAuthorizeErr == nil {
+				caller = &model.RoomUser{RoomID: roomId, UserID: "1", Role: model.Owner}
+			}
+			var target *model.RoomUser
+			if tc.mockTargetErr == nil {
+				target = &model.RoomUser{RoomID: roomId, UserID: tc.userId, Role: model.Member}
+			}
+
+			mockAuthorizationUsecase.On("AuthorizeRoomRole", mock.Anything, roomId, "1", model.Owner).Return(caller, tc.mockAuthorizeErr)
+			mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, roomId, tc.userId).Return(target, tc.mockTargetErr)
+			mockRoomUserRepo.On("UpdateRole", mock.Anything, roomId, tc.userId, tc.role).Return(nil)
+			mockHub.On("BroadcastEvent", &model.RoomUserDetails{RoomID: roomId, UserID: tc.userId, Role: tc.role}).Return()
+			mockPublisher.On("PublishToRoom", roomId, &model.RoomUserDetails{RoomID: roomId, UserID: tc.userId, Role: tc.role}).Return()
+
+			roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo, new(mocks.MessageRepository), mockAuthorizationUsecase, mockHub, mockPublisher)
+
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := roomUserUsecase.UpdateUserRole(ctx, roomId, tc.userId, tc.role)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				mockRoomUserRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mockHub.AssertNotCalled(t, "BroadcastEvent", mock.Anything)
+				mockPublisher.AssertNotCalled(t, "PublishToRoom", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				mockRoomUserRepo.AssertExpectations(t)
+				mockHub.AssertExpectations(t)
+				mockPublisher.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	roomId := "1"
+
+	testCases := []struct {
+		name             string
+		newOwnerId       string
+		mockAuthorizeErr error
+		expectedErr      error
+	}{
+		{
+			name:       "Success",
+			newOwnerId: "2",
+		},
+		{
+			name:             "Not Owner",
+			newOwnerId:       "2",
+			mockAuthorizeErr: apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+			expectedErr:      apperror.NewForbiddenErr("Room", "RoomID: 1, Role: admin"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRoomUserRepo := new(mocks.RoomUserRepository)
+			mockUserRepo := new(mocks.UserRepository)
+			mockRoomRepo := new(mocks.RoomRepository)
+			mockAuthorizationUsecase := new(usecaseMocks.AuthorizationUsecase)
+			mockHub := new(modelMocks.Hub)
+			mockPublisher := new(modelMocks.RoomEventPublisher)
+
+			var caller *model.RoomUser
+			if tc.mockAuthorizeErr == nil {
+				caller = &model.RoomUser{RoomID: roomId, UserID: "1", Role: model.Owner}
+			}
+
+			mockAuthorizationUsecase.On("AuthorizeRoomRole", mock.Anything, roomId, "1", model.Owner).Return(caller, tc.mockAuthorizeErr)
+			mockRoomUserRepo.On("GetByRoomIDAndUserID", mock.Anything, roomId, tc.newOwnerId).Return(&model.RoomUser{RoomID: roomId, UserID: tc.newOwnerId, Role: model.Admin}, nil)
+			mockRoomUserRepo.On("TransferOwnership", mock.Anything, roomId, "1", tc.newOwnerId).Return(nil)
+			mockHub.On("BroadcastEvent", &model.RoomUserDetails{RoomID: roomId, UserID: "1", Role: model.Admin}).Return()
+			mockHub.On("BroadcastEvent", &model.RoomUserDetails{RoomID: roomId, UserID: tc.newOwnerId, Role: model.Owner}).Return()
+			mockPublisher.On("PublishToRoom", roomId, &model.RoomUserDetails{RoomID: roomId, UserID: "1", Role: model.Admin}).Return()
+			mockPublisher.On("PublishToRoom", roomId, &model.RoomUserDetails{RoomID: roomId, UserID: tc.newOwnerId, Role: model.Owner}).Return()
+
+			roomUserUsecase := NewRoomUserUsecase(mockRoomUserRepo, mockUserRepo, mockRoomRepo, new(mocks.MessageRepository), mockAuthorizationUsecase, mockHub, mockPublisher)
+
+			ctx := authctx.WithUserID(context.Background(), "1")
+			err := roomUserUsecase.TransferOwnership(ctx, roomId, tc.newOwnerId)
+
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+				mockRoomUserRepo.AssertNotCalled(t, "TransferOwnership", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				mockRoomUserRepo.AssertExpectations(t)
+				mockHub.AssertExpectations(t)
+				mockPublisher.AssertExpectations(t)
+			}
+		})
+	}
+}
@@ -9,37 +9,28 @@ import (
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/repository"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/usecase"
-	"github.com/shunsukenagashima/chat-api/pkg/infra/auth"
 )
 
 type UserUsecaseImpl struct {
-	repo         repository.UserRepository
-	firebaseAuth auth.FirebaseAuthenticator
+	repo repository.UserRepository
 }
 
-func NewUserUsecase(repo repository.UserRepository, firebaseAuth auth.FirebaseAuthenticator) usecase.UserUsecase {
+func NewUserUsecase(repo repository.UserRepository) usecase.UserUsecase {
 	return &UserUsecaseImpl{
 		repo,
-		firebaseAuth,
 	}
 }
 
-func (uu *UserUsecaseImpl) CreateUser(ctx context.Context, user *model.User, idToken string) error {
-	// TODO: NAT Gateway is required to use Firebase Auth
-	// token, err := uu.firebaseAuth.GetFirebaseUser(ctx, idToken)
-	// if err != nil {
-	// 	return err
-	// }
-	// if token.UID != user.UserID {
-	// 	return fmt.Errorf("provided user ID does not match the user ID in Firebase token")
-	// }
-
-	_, err := uu.repo.GetByID(ctx, user.UserID)
+func (uu *UserUsecaseImpl) CreateUser(ctx context.Context, user *model.User) error {
+	existing, err := uu.repo.GetByID(ctx, user.UserID)
 	if err != nil {
 		var notFoundErr *apperror.NotFoundErr
 		if !errors.As(err, &notFoundErr) {
 			return err
 		}
+	} else {
+		// the last seen time is recorded by the presence usecase, not sent by the client
+		user.LastSeenAt = existing.LastSeenAt
 	}
 
 	clock := clock.RealClocker{}
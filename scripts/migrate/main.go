@@ -0,0 +1,44 @@
+// Command migrate creates and updates the DynamoDB tables of the API. Only the migrations that have not been
+// applied yet are run, so it is safe to run on every deploy.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/shunsukenagashima/chat-api/pkg/config"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+)
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML file overriding the default settings; environment variables override it in turn")
+	reset := flag.Bool("reset", false, "drop every table before migrating; for local development only")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load the config: %v", err)
+	}
+
+	db, err := schema.NewClient(cfg.DynamoDB.Endpoint, cfg.DynamoDB.Region)
+	if err != nil {
+		log.Fatalf("Failed to create a dynamodb client: %v", err)
+	}
+	tables := schema.NewTableNames(cfg.DynamoDB.TablePrefix)
+
+	if *reset {
+		if err := schema.DropTables(ctx, db, tables); err != nil {
+			log.Fatalf("Failed to drop the tables: %v", err)
+		}
+	}
+
+	if err := schema.Migrate(ctx, db, tables); err != nil {
+		log.Fatalf("Failed to migrate: %v", err)
+	}
+
+	log.Print("Migration completed")
+}
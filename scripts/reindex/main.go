@@ -0,0 +1,76 @@
+// Command reindex rebuilds the search index of messages from the Messages table. It connects to DynamoDB and
+// Elasticsearch as configured for the API.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/shunsukenagashima/chat-api/pkg/config"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/repository"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+)
+
+const batchSize = 500
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML file overriding the default settings; environment variables override it in turn")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load the config: %v", err)
+	}
+
+	db, err := schema.NewClient(cfg.DynamoDB.Endpoint, cfg.DynamoDB.Region)
+	if err != nil {
+		log.Fatalf("Failed to create a dynamodb client: %v", err)
+	}
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: cfg.Elasticsearch.URLs,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create an elasticsearch client: %v", err)
+	}
+	if err := repository.CreateMessageIndex(ctx, es); err != nil {
+		log.Fatalf("Failed to create the message index: %v", err)
+	}
+	searchRepo := repository.NewMessageSearchRepository(es)
+
+	indexed := 0
+	var indexErr error
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(schema.NewTableNames(cfg.DynamoDB.TablePrefix).Messages),
+		Limit:     aws.Int64(batchSize),
+	}
+	err = db.ScanPagesWithContext(ctx, input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var messages []*model.Message
+		if indexErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &messages); indexErr != nil {
+			return false
+		}
+		if indexErr = searchRepo.BulkIndex(ctx, messages); indexErr != nil {
+			return false
+		}
+		indexed += len(messages)
+		log.Printf("Indexed %d messages", indexed)
+		return true
+	})
+	if err != nil {
+		log.Fatalf("Failed to scan messages: %v", err)
+	}
+	if indexErr != nil {
+		log.Fatalf("Failed to index messages: %v", indexErr)
+	}
+
+	log.Printf("Reindexed %d messages", indexed)
+}
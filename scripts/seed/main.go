@@ -0,0 +1,48 @@
+// Command seed fills the tables with sample users, rooms, memberships and messages for local development.
+// The tables must have been created by the migrate command first.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/shunsukenagashima/chat-api/pkg/config"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+)
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML file overriding the default settings; environment variables override it in turn")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load the config: %v", err)
+	}
+
+	svc, err := schema.NewClient(cfg.DynamoDB.Endpoint, cfg.DynamoDB.Region)
+	if err != nil {
+		log.Fatalf("Failed to create a dynamodb client: %v", err)
+	}
+	tables := schema.NewTableNames(cfg.DynamoDB.TablePrefix)
+
+	users, err := seedUsers(svc, tables)
+	if err != nil {
+		log.Fatalf("Failed to seed users: %v", err)
+	}
+
+	roomIDs, err := seedRooms(svc, tables)
+	if err != nil {
+		log.Fatalf("Failed to seed rooms: %v", err)
+	}
+
+	if err := seedRoomUsers(svc, tables, roomIDs, users); err != nil {
+		log.Fatalf("Failed to seed room users: %v", err)
+	}
+
+	if err := seedMessages(svc, tables, users, roomIDs[0]); err != nil {
+		log.Fatalf("Failed to seed messages: %v", err)
+	}
+
+	log.Printf("Seeded %d users and %d rooms", len(users), len(roomIDs))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/shunsukenagashima/chat-api/pkg/clock"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+)
+
+func seedMessages(svc *dynamodb.DynamoDB, tables schema.TableNames, users []*model.User, roomId string) error {
+	tableName := tables.Messages
+
+	clock := clock.FixedClocker{}
+
+	totalUsers := len(users)
+	for j := 0; j < 2; j++ {
+		for i, user := range users {
+			// テストデータの投入
+			_, err := svc.PutItem(&dynamodb.PutItemInput{
+				Item: map[string]*dynamodb.AttributeValue{
+					"messageId": {
+						S: aws.String(uuid.New().String()),
+					},
+					"content": {
+						S: aws.String("sample message" + strconv.Itoa(i+j*totalUsers)),
+					},
+					"createdAt": {
+						S: aws.String(clock.Now().Add(time.Duration(i+j*totalUsers) * time.Minute).Format("2006-01-02T15:04:05Z")),
+					},
+					"roomId": {
+						S: aws.String(roomId),
+					},
+					"userId": {
+						S: aws.String(user.UserID),
+					},
+				},
+				TableName: aws.String(tableName),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+)
+
+func seedRoomUsers(svc *dynamodb.DynamoDB, tables schema.TableNames, roomIDs []string, users []*model.User) error {
+	tableName := tables.RoomUsers
+
+	// テストデータの投入
+	for _, roomId := range roomIDs {
+		_, err := svc.PutItem(&dynamodb.PutItemInput{
+			Item: map[string]*dynamodb.AttributeValue{
+				"roomId": {
+					S: aws.String(roomId),
+				},
+				"userId": {
+					S: aws.String(users[0].UserID),
+				},
+				"role": {
+					S: aws.String(string(model.Owner)),
+				},
+			},
+			TableName: aws.String(tableName),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, user := range users[1:] {
+		_, err := svc.PutItem(&dynamodb.PutItemInput{
+			Item: map[string]*dynamodb.AttributeValue{
+				"roomId": {
+					S: aws.String(roomIDs[0]),
+				},
+				"userId": {
+					S: aws.String(user.UserID),
+				},
+				"role": {
+					S: aws.String(string(model.Member)),
+				},
+			},
+			TableName: aws.String(tableName),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
+)
+
+func seedRooms(svc *dynamodb.DynamoDB, tables schema.TableNames) ([]string, error) {
+	tableName := tables.Rooms
+
+	var roomIDs []string
+	// テストデータの投入
+	for i := 0; i <= 3; i++ {
+		roomId := uuid.New().String()
+		roomIDs = append(roomIDs, roomId)
+		var roomName string
+		var roomType string
+		if i == 0 {
+			roomName = "sample-private-room"
+			roomType = "private"
+		} else {
+			roomName = fmt.Sprintf("sample-public-room-%d", i)
+			roomType = "public"
+		}
+
+		_, err := svc.PutItem(&dynamodb.PutItemInput{
+			Item: map[string]*dynamodb.AttributeValue{
+				"roomId": {
+					S: aws.String(roomId),
+				},
+				"name": {
+					S: aws.String(roomName),
+				},
+				"roomType": {
+					S: aws.String(roomType),
+				},
+			},
+			TableName: aws.String(tableName),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return roomIDs, nil
+}
@@ -1,50 +1,19 @@
-package scripts
+package main
 
 import (
 	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/google/uuid"
 	"github.com/shunsukenagashima/chat-api/pkg/clock"
 	"github.com/shunsukenagashima/chat-api/pkg/domain/model"
+	"github.com/shunsukenagashima/chat-api/pkg/infra/schema"
 )
 
-func SetupUsers() ([]*model.User, error) {
-	tableName := "Users"
-
-	sess, _ := session.NewSession(&aws.Config{
-		Region:   aws.String("us-west-2"),
-		Endpoint: aws.String("http://localhost:8000"),
-	})
-
-	svc := dynamodb.New(sess)
-
-	// usersテーブルの作成
-	_, err := svc.CreateTable(&dynamodb.CreateTableInput{
-		AttributeDefinitions: []*dynamodb.AttributeDefinition{
-			{
-				AttributeName: aws.String("userId"),
-				AttributeType: aws.String("S"),
-			},
-		},
-		KeySchema: []*dynamodb.KeySchemaElement{
-			{
-				AttributeName: aws.String("userId"),
-				KeyType:       aws.String("HASH"),
-			},
-		},
-		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-			ReadCapacityUnits:  aws.Int64(10),
-			WriteCapacityUnits: aws.Int64(10),
-		},
-		TableName: aws.String(tableName),
-	})
-	if err != nil {
-		return nil, err
-	}
+func seedUsers(svc *dynamodb.DynamoDB, tables schema.TableNames) ([]*model.User, error) {
+	tableName := tables.Users
 
 	var users []*model.User
 	clock := clock.FixedClocker{}
@@ -59,7 +28,7 @@ func SetupUsers() ([]*model.User, error) {
 	users = append(users, firebaseUser)
 
 	// テストデータの投入
-	_, err = svc.PutItem(&dynamodb.PutItemInput{
+	_, err := svc.PutItem(&dynamodb.PutItemInput{
 		Item: map[string]*dynamodb.AttributeValue{
 			"userId": {
 				S: aws.String(firebaseUser.UserID),
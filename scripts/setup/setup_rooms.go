@@ -1,103 +0,0 @@
-package scripts
-
-import (
-	"fmt"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/google/uuid"
-)
-
-func SetupRooms() ([]string, error) {
-	tableName := "Rooms"
-
-	sess, _ := session.NewSession(&aws.Config{
-		Region:   aws.String("us-west-2"),
-		Endpoint: aws.String("http://localhost:8000"),
-	})
-
-	svc := dynamodb.New(sess)
-
-	// rooms テーブルの作成
-	_, err := svc.CreateTable(&dynamodb.CreateTableInput{
-		AttributeDefinitions: []*dynamodb.AttributeDefinition{
-			{
-				AttributeName: aws.String("roomId"),
-				AttributeType: aws.String("S"),
-			},
-			{
-				AttributeName: aws.String("name"),
-				AttributeType: aws.String("S"),
-			},
-		},
-		KeySchema: []*dynamodb.KeySchemaElement{
-			{
-				AttributeName: aws.String("roomId"),
-				KeyType:       aws.String("HASH"),
-			},
-		},
-		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
-			{
-				IndexName: aws.String("NameIndex"),
-				KeySchema: []*dynamodb.KeySchemaElement{
-					{
-						AttributeName: aws.String("name"),
-						KeyType:       aws.String("HASH"),
-					},
-				},
-				Projection: &dynamodb.Projection{
-					ProjectionType: aws.String("ALL"),
-				},
-				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-					ReadCapacityUnits:  aws.Int64(10),
-					WriteCapacityUnits: aws.Int64(10),
-				},
-			},
-		},
-		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-			ReadCapacityUnits:  aws.Int64(10),
-			WriteCapacityUnits: aws.Int64(10),
-		},
-		TableName: aws.String(tableName),
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	var roomIDs []string
-	// テストデータの投入
-	for i := 0; i <= 3; i++ {
-		roomId := uuid.New().String()
-		roomIDs = append(roomIDs, roomId)
-		var roomName string
-		var roomType string
-		if i == 0 {
-			roomName = "sample-private-room"
-			roomType = "private"
-		} else {
-			roomName = fmt.Sprintf("sample-public-room-%d", i)
-			roomType = "public"
-		}
-
-		_, err = svc.PutItem(&dynamodb.PutItemInput{
-			Item: map[string]*dynamodb.AttributeValue{
-				"roomId": {
-					S: aws.String(roomId),
-				},
-				"name": {
-					S: aws.String(roomName),
-				},
-				"roomType": {
-					S: aws.String(roomType),
-				},
-			},
-			TableName: aws.String(tableName),
-		})
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	return roomIDs, nil
-}